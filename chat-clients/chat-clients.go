@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"math"
 	"net/http"
 	"os"
 	"os/signal"
@@ -18,6 +19,7 @@ import (
 	"contrib.go.opencensus.io/exporter/stackdriver"
 	"github.com/aasmall/dicemagic/internal/handler"
 	log "github.com/aasmall/dicemagic/internal/logger"
+	pb "github.com/aasmall/dicemagic/internal/proto"
 	"github.com/go-redis/redis"
 	"github.com/gorilla/mux"
 	"go.opencensus.io/plugin/ocgrpc"
@@ -55,10 +57,14 @@ type envConfig struct {
 	redisClusterHosts     string
 	debug                 bool
 	local                 bool
+	slackShowStats        bool
 	traceProbability      float64
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(migrateCommand(os.Args[2:]))
+	}
 	log.Printf("hello.")
 	ctx := context.Background()
 
@@ -85,6 +91,7 @@ func main() {
 		redisClusterHosts:     configReader.getEnvOpt("REDIS_CLUSTER_HOSTS"),
 		debug:                 configReader.getEnvBoolOpt("DEBUG"),
 		local:                 configReader.getEnvBoolOpt("LOCAL"),
+		slackShowStats:        configReader.getEnvBoolOpt("SLACK_SHOW_STATS"),
 		traceProbability:      configReader.getEnvFloat("TRACE_PROBABILITY"),
 	}
 	if configReader.errors {
@@ -239,6 +246,17 @@ func totalsMapString(m map[string]float64) string {
 	}
 	return string(bytes.Join(b, []byte(", ")))
 }
+
+// statsString summarizes how lucky a DiceSet's total was, e.g. "(avg 12.5, top 18%)", or is empty when the
+// DiceSet has no statistics
+func statsString(ds *pb.DiceSet) string {
+	if !ds.HasStatistics {
+		return ""
+	}
+	return fmt.Sprintf("(avg %s, top %s%%)",
+		strconv.FormatFloat(ds.Mean, 'f', 1, 64),
+		strconv.FormatFloat(math.Ceil(ds.TopPercent), 'f', 0, 64))
+}
 func facesSliceString(faces []int64) string {
 	var b [][]byte
 	for _, f := range faces {
@@ -247,6 +265,51 @@ func facesSliceString(faces []int64) string {
 	return string(bytes.Join(b, []byte(", ")))
 }
 
+// totalString renders the Total of a DiceSet, or every value when it is a list, like "15, 14, 13, 12, 10, 8"
+func totalString(ds *pb.DiceSet) string {
+	if len(ds.Values) == 0 {
+		return strconv.FormatInt(ds.Total, 10)
+	}
+	values := make([]string, len(ds.Values))
+	for i, x := range ds.Values {
+		values[i] = strconv.FormatFloat(x, 'f', -1, 64)
+	}
+	return strings.Join(values, ", ")
+}
+
+// resultString renders the result of a DiceSet, with the faces of each dice in parentheses
+func resultString(ds *pb.DiceSet) string {
+	if ds.Result == nil {
+		// servers which predate ResultNode only send ReString
+		var faces []interface{}
+		for _, d := range ds.Dice {
+			faces = append(faces, facesSliceString(d.Faces))
+		}
+		return fmt.Sprintf(ds.ReString, faces...)
+	}
+	var b strings.Builder
+	writeResultNode(&b, ds.Result, ds.Dice)
+	return b.String()
+}
+
+func writeResultNode(b *strings.Builder, n *pb.ResultNode, dice []*pb.Dice) {
+	switch n.Kind {
+	case pb.ResultNode_TEXT:
+		b.WriteString(n.Text)
+	case pb.ResultNode_DICE:
+		b.WriteString(n.Text)
+		b.WriteString("(")
+		if i := int(n.DiceIndex); i >= 0 && i < len(dice) {
+			b.WriteString(facesSliceString(dice[i].Faces))
+		}
+		b.WriteString(")")
+	case pb.ResultNode_OPERATOR:
+		for _, c := range n.Children {
+			writeResultNode(b, c, dice)
+		}
+	}
+}
+
 func (env *env) isLocal() bool {
 	return env.config.local
 }
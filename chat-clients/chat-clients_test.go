@@ -0,0 +1,84 @@
+package main
+
+import (
+	"testing"
+
+	pb "github.com/aasmall/dicemagic/internal/proto"
+)
+
+func TestStatsString(t *testing.T) {
+	tests := []struct {
+		name string
+		ds   *pb.DiceSet
+		want string
+	}{
+		{"rounded", &pb.DiceSet{HasStatistics: true, Mean: 10.5, TopPercent: 17.2}, "(avg 10.5, top 18%)"},
+		{"whole", &pb.DiceSet{HasStatistics: true, Mean: 7, TopPercent: 100}, "(avg 7.0, top 100%)"},
+		{"negative", &pb.DiceSet{HasStatistics: true, Mean: -2.25, TopPercent: 0.1}, "(avg -2.2, top 1%)"},
+		{"zero", &pb.DiceSet{HasStatistics: true}, "(avg 0.0, top 0%)"},
+		{"absent", &pb.DiceSet{Total: 12}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := statsString(tt.ds); got != tt.want {
+				t.Errorf("statsString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResultString(t *testing.T) {
+	dice := []*pb.Dice{{Faces: []int64{3, 5}}, {Faces: []int64{20}}}
+	tests := []struct {
+		name string
+		ds   *pb.DiceSet
+		want string
+	}{
+		{
+			name: "result",
+			ds: &pb.DiceSet{Dice: dice, Result: &pb.ResultNode{Kind: pb.ResultNode_OPERATOR, Text: "+", Children: []*pb.ResultNode{
+				{Kind: pb.ResultNode_TEXT, Text: "Roll "},
+				{Kind: pb.ResultNode_DICE, Text: "2d6", DiceIndex: 0},
+				{Kind: pb.ResultNode_TEXT, Text: " + "},
+				{Kind: pb.ResultNode_DICE, Text: "1d20", DiceIndex: 1},
+			}}},
+			want: "Roll 2d6(3, 5) + 1d20(20)",
+		},
+		{
+			name: "missing dice",
+			ds:   &pb.DiceSet{Result: &pb.ResultNode{Kind: pb.ResultNode_DICE, Text: "1d4", DiceIndex: 2}},
+			want: "1d4()",
+		},
+		{
+			name: "ReString from an older server",
+			ds:   &pb.DiceSet{Dice: dice, ReString: "Roll 2d6(%s) + 1d20(%s)"},
+			want: "Roll 2d6(3, 5) + 1d20(20)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resultString(tt.ds); got != tt.want {
+				t.Errorf("resultString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTotalString(t *testing.T) {
+	tests := []struct {
+		name string
+		ds   *pb.DiceSet
+		want string
+	}{
+		{"number", &pb.DiceSet{Total: 17}, "17"},
+		{"list", &pb.DiceSet{Total: 72, Values: []float64{15, 14, 13, 12, 10, 8}}, "15, 14, 13, 12, 10, 8"},
+		{"fractional", &pb.DiceSet{Total: 3, Values: []float64{1.5, 1.5}}, "1.5, 1.5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := totalString(tt.ds); got != tt.want {
+				t.Errorf("totalString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"cloud.google.com/go/datastore"
+	"github.com/aasmall/dicemagic/dicelang"
+	"github.com/go-redis/redis"
+)
+
+// commandStore is where saved commands are kept for good, like Datastore
+type commandStore interface {
+	allCommands(ctx context.Context) ([]*RedisCommand, error)
+	putCommand(ctx context.Context, cmd *RedisCommand) error
+}
+
+// commandCache caches saved commands, like redis
+type commandCache interface {
+	evict(key string) error
+}
+
+type datastoreCommands struct {
+	client *datastore.Client
+}
+
+func (d datastoreCommands) allCommands(ctx context.Context) ([]*RedisCommand, error) {
+	var cmds []*RedisCommand
+	_, err := d.client.GetAll(ctx, datastore.NewQuery("RedisCommand"), &cmds)
+	return cmds, err
+}
+
+func (d datastoreCommands) putCommand(ctx context.Context, cmd *RedisCommand) error {
+	_, err := d.client.Put(ctx, cmd.Key, cmd)
+	return err
+}
+
+type redisCommands struct {
+	client *redis.Client
+}
+
+func (r redisCommands) evict(key string) error {
+	return r.client.Del(key).Err()
+}
+
+// migrateCommand rewrites every saved command in Datastore to the latest version of dicelang.
+// It returns the process exit code.
+func migrateCommand(args []string) int {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	projectID := flags.String("project", os.Getenv("PROJECT_ID"), "Google Cloud project holding the saved commands")
+	redisAddr := flags.String("redis", "", "Address of a redis server caching saved commands. Migrated commands are evicted from it when set")
+	dryRun := flags.Bool("dry-run", false, "Print the rewritten commands without saving them")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	ctx := context.Background()
+	dsClient, err := datastore.NewClient(ctx, *projectID)
+	if err != nil {
+		fmt.Printf("Could not configure Datastore Client: %v\n", err)
+		return 2
+	}
+	defer dsClient.Close()
+	var cache commandCache
+	if *redisAddr != "" {
+		redisClient := redis.NewClient(&redis.Options{Addr: *redisAddr})
+		defer redisClient.Close()
+		cache = redisCommands{client: redisClient}
+	}
+	return migrateCommands(ctx, os.Stdout, datastoreCommands{client: dsClient}, cache, *dryRun)
+}
+
+// migrateCommands rewrites every command in store to the latest version of dicelang, evicting each from cache when
+// it isn't nil, and writes what it did to out. It returns the process exit code.
+func migrateCommands(ctx context.Context, out io.Writer, store commandStore, cache commandCache, dryRun bool) int {
+	cmds, err := store.allCommands(ctx)
+	if err != nil {
+		fmt.Fprintf(out, "Could not read saved commands: %v\n", err)
+		return 2
+	}
+	var migrated, failed int
+	for _, cmd := range cmds {
+		source, err := dicelang.Migrate(cmd.CommandValue)
+		if err != nil {
+			fmt.Fprintf(out, "Could not migrate %q for %s:%s: %v\n", cmd.CommandKey, cmd.TeamID, cmd.UserID, err)
+			failed++
+			continue
+		}
+		if source == cmd.CommandValue {
+			continue
+		}
+		fmt.Fprintf(out, "%s:%s:%s: %q => %q\n", cmd.TeamID, cmd.UserID, cmd.CommandKey, cmd.CommandValue, source)
+		migrated++
+		if dryRun {
+			continue
+		}
+		cmd.CommandValue = source
+		if err := store.putCommand(ctx, cmd); err != nil {
+			fmt.Fprintf(out, "Could not save %q for %s:%s: %v\n", cmd.CommandKey, cmd.TeamID, cmd.UserID, err)
+			failed++
+			continue
+		}
+		// the cached copy would be written back over the migrated command the next time it is used
+		if cache != nil {
+			key := fmt.Sprintf("command:%s:%s:%s", cmd.TeamID, cmd.UserID, cmd.CommandKey)
+			if err := cache.evict(key); err != nil {
+				fmt.Fprintf(out, "Could not evict %s: %v\n", key, err)
+			}
+		}
+	}
+	fmt.Fprintf(out, "Migrated %d of %d saved commands to dicelang %d. %d failed.\n", migrated, len(cmds), dicelang.LatestVersion, failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// memoryCommands is a commandStore and commandCache in memory, keyed like the redis cache
+type memoryCommands struct {
+	saved   map[string]string
+	evicted []string
+	failPut bool
+}
+
+func (m *memoryCommands) allCommands(ctx context.Context) ([]*RedisCommand, error) {
+	var cmds []*RedisCommand
+	for _, key := range []string{"4d6", "attack", "stats", "broken"} {
+		if value, ok := m.saved[key]; ok {
+			cmds = append(cmds, &RedisCommand{TeamID: "T1", UserID: "U1", CommandKey: key, CommandValue: value})
+		}
+	}
+	return cmds, nil
+}
+
+func (m *memoryCommands) putCommand(ctx context.Context, cmd *RedisCommand) error {
+	if m.failPut {
+		return errors.New("datastore is down")
+	}
+	m.saved[cmd.CommandKey] = cmd.CommandValue
+	return nil
+}
+
+func (m *memoryCommands) evict(key string) error {
+	m.evicted = append(m.evicted, key)
+	return nil
+}
+
+func newMemoryCommands() *memoryCommands {
+	return &memoryCommands{saved: map[string]string{
+		"4d6":    "4d6-L1",
+		"attack": "roll 1d20+5 fire",
+		"stats":  "#dicelang 2\n[4d6dl1; 6]",
+		"broken": "(1d4",
+	}}
+}
+
+func TestMigrateCommands(t *testing.T) {
+	m := newMemoryCommands()
+	var out bytes.Buffer
+	if code := migrateCommands(context.Background(), &out, m, m, false); code != 1 {
+		t.Errorf("migrateCommands() = %d, want 1 for the command that doesn't parse\n%s", code, out.String())
+	}
+	want := map[string]string{
+		"4d6":    "#dicelang 2\n4d6dl1",
+		"attack": "#dicelang 2\nroll 1d20 + 5 Fire",
+		"stats":  "#dicelang 2\n[4d6dl1; 6]",
+		"broken": "(1d4",
+	}
+	if !reflect.DeepEqual(m.saved, want) {
+		t.Errorf("migrateCommands() saved %q, want %q", m.saved, want)
+	}
+	if evicted := []string{"command:T1:U1:4d6", "command:T1:U1:attack"}; !reflect.DeepEqual(m.evicted, evicted) {
+		t.Errorf("migrateCommands() evicted %q, want %q", m.evicted, evicted)
+	}
+	if summary := "Migrated 2 of 4 saved commands to dicelang 2. 1 failed.\n"; !bytes.HasSuffix(out.Bytes(), []byte(summary)) {
+		t.Errorf("migrateCommands() wrote %q, want it to end with %q", out.String(), summary)
+	}
+
+	// migrating again changes nothing
+	out.Reset()
+	m.evicted = nil
+	migrateCommands(context.Background(), &out, m, m, false)
+	if !reflect.DeepEqual(m.saved, want) || len(m.evicted) != 0 {
+		t.Errorf("migrateCommands() again saved %q and evicted %q, want no changes", m.saved, m.evicted)
+	}
+}
+
+func TestMigrateCommands_DryRun(t *testing.T) {
+	m := newMemoryCommands()
+	before := fmt.Sprint(m.saved)
+	var out bytes.Buffer
+	migrateCommands(context.Background(), &out, m, m, true)
+	if after := fmt.Sprint(m.saved); after != before || len(m.evicted) != 0 {
+		t.Errorf("migrateCommands() dry run saved %s and evicted %q, want %s", after, m.evicted, before)
+	}
+	if !bytes.Contains(out.Bytes(), []byte(`"4d6-L1" => "#dicelang 2\n4d6dl1"`)) {
+		t.Errorf("migrateCommands() dry run wrote %q, want each rewritten command", out.String())
+	}
+}
+
+func TestMigrateCommands_PutFails(t *testing.T) {
+	m := newMemoryCommands()
+	m.failPut = true
+	var out bytes.Buffer
+	if code := migrateCommands(context.Background(), &out, m, m, false); code != 1 {
+		t.Errorf("migrateCommands() = %d, want 1", code)
+	}
+	if m.saved["4d6"] != "4d6-L1" || len(m.evicted) != 0 {
+		t.Errorf("migrateCommands() saved %q and evicted %q, want nothing changed when saving fails", m.saved, m.evicted)
+	}
+}
+
+func TestMigrateCommands_NoCache(t *testing.T) {
+	m := newMemoryCommands()
+	var out bytes.Buffer
+	migrateCommands(context.Background(), &out, m, nil, false)
+	if m.saved["4d6"] != "#dicelang 2\n4d6dl1" {
+		t.Errorf("migrateCommands() saved %q, want it migrated without a cache", m.saved["4d6"])
+	}
+}
@@ -4,10 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"strconv"
 	"strings"
 
-	"github.com/aasmall/dicemagic/internal/dicelang/errors"
+	"github.com/aasmall/dicemagic/dicelang/errors"
 	pb "github.com/aasmall/dicemagic/internal/proto"
 )
 
@@ -21,6 +20,11 @@ type RESTRollRequest struct {
 	Cmd         string `json:"cmd"`
 	Chart       bool   `json:"with_chart,omitempty"`
 	Probability bool   `json:"with_probability,omitempty"`
+	Statistics  bool   `json:"with_statistics,omitempty"`
+	Explain     bool   `json:"with_explain,omitempty"`
+	Simplify    bool   `json:"simplify,omitempty"`
+	// Words to suggest when cmd has a typo, like the names of the caller's saved commands
+	KnownWords []string `json:"known_words,omitempty"`
 }
 
 func RESTRollHandler(e interface{}, w http.ResponseWriter, r *http.Request) error {
@@ -34,7 +38,7 @@ func RESTRollHandler(e interface{}, w http.ResponseWriter, r *http.Request) erro
 		return err
 	}
 	resp := &RESTRollResponse{Cmd: req.Cmd}
-	diceServerResponse, err := Roll(env.diceServerClient, req.Cmd, RollOptionWithProbability(req.Probability), RollOptionWithChart(req.Chart))
+	diceServerResponse, err := Roll(env.diceServerClient, req.Cmd, RollOptionWithProbability(req.Probability), RollOptionWithChart(req.Chart), RollOptionWithStatistics(req.Statistics), RollOptionWithExplain(req.Explain), RollOptionWithSimplify(req.Simplify), RollOptionWithKnownWords(req.KnownWords))
 	if err != nil {
 		errString := fmt.Sprintf("Unexpected error: %+v", err)
 		resp.Ok = false
@@ -44,7 +48,7 @@ func RESTRollHandler(e interface{}, w http.ResponseWriter, r *http.Request) erro
 	}
 	if diceServerResponse.Ok {
 		resp.Ok = true
-		resp.Result = StringFromRollResponse(diceServerResponse)
+		resp.Result = StringFromRollResponse(diceServerResponse, req.Statistics)
 	} else {
 		if diceServerResponse.Error.Code == errors.Friendly {
 			resp.Ok = true
@@ -59,17 +63,24 @@ func RESTRollHandler(e interface{}, w http.ResponseWriter, r *http.Request) erro
 	return nil
 }
 
-func StringFromRollResponse(rr *pb.RollResponse) string {
+func StringFromRollResponse(rr *pb.RollResponse, withStats bool) string {
 	var s []string
 	for _, ds := range rr.DiceSets {
-		var faces []interface{}
-		for _, d := range ds.Dice {
-			faces = append(faces, facesSliceString(d.Faces))
+		line := fmt.Sprintf("%s = *%s*", resultString(ds), totalString(ds))
+		if ds.Explanation != nil {
+			line = ds.Explanation.Text
 		}
-		s = append(s, fmt.Sprintf("%s = *%s*", fmt.Sprintf(ds.ReString, faces...), strconv.FormatInt(ds.Total, 10)))
+		if stats := statsString(ds); withStats && stats != "" {
+			line = fmt.Sprintf("%s %s", line, stats)
+		}
+		s = append(s, line)
 	}
 	if len(rr.DiceSets) > 1 {
-		s = append(s, fmt.Sprintf("Total: %s", strconv.FormatInt(rr.DiceSet.Total, 10)))
+		line := fmt.Sprintf("Total: %s", totalString(rr.DiceSet))
+		if stats := statsString(rr.DiceSet); withStats && stats != "" {
+			line = fmt.Sprintf("%s %s", line, stats)
+		}
+		s = append(s, line)
 	}
 	return strings.Join(s, "\n")
 }
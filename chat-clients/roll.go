@@ -13,6 +13,13 @@ type RollOption func(*RollOptions)
 type RollOptions struct {
 	Chart       bool
 	Probability bool
+	Statistics  bool
+	Explain     bool
+	Simplify    bool
+	KnownWords  []string
+	Seed        uint64
+	Replay      []*pb.Draw
+	Fair        *pb.FairSeed
 	Timeout     time.Duration
 	Context     context.Context
 }
@@ -27,6 +34,41 @@ func RollOptionWithProbability(withProb bool) RollOption {
 		o.Probability = withProb
 	}
 }
+func RollOptionWithStatistics(withStats bool) RollOption {
+	return func(o *RollOptions) {
+		o.Statistics = withStats
+	}
+}
+func RollOptionWithExplain(withExplain bool) RollOption {
+	return func(o *RollOptions) {
+		o.Explain = withExplain
+	}
+}
+func RollOptionWithSimplify(withSimplify bool) RollOption {
+	return func(o *RollOptions) {
+		o.Simplify = withSimplify
+	}
+}
+func RollOptionWithKnownWords(words []string) RollOption {
+	return func(o *RollOptions) {
+		o.KnownWords = words
+	}
+}
+func RollOptionWithSeed(seed uint64) RollOption {
+	return func(o *RollOptions) {
+		o.Seed = seed
+	}
+}
+func RollOptionWithReplay(transcript []*pb.Draw) RollOption {
+	return func(o *RollOptions) {
+		o.Replay = transcript
+	}
+}
+func RollOptionWithFairSeed(seed *pb.FairSeed) RollOption {
+	return func(o *RollOptions) {
+		o.Fair = seed
+	}
+}
 func RollOptionWithTimeout(timeout time.Duration) RollOption {
 	return func(o *RollOptions) {
 		o.Timeout = timeout
@@ -43,6 +85,7 @@ func Roll(client *grpc.ClientConn, cmd string, options ...RollOption) (*pb.RollR
 	opts := RollOptions{
 		Chart:       false,
 		Probability: false,
+		Statistics:  false,
 		Timeout:     time.Second,
 		Context:     context.Background(),
 	}
@@ -56,6 +99,13 @@ func Roll(client *grpc.ClientConn, cmd string, options ...RollOption) (*pb.RollR
 		Cmd:           cmd,
 		Probabilities: opts.Probability,
 		Chart:         opts.Chart,
+		Statistics:    opts.Statistics,
+		Explain:       opts.Explain,
+		Simplify:      opts.Simplify,
+		KnownWords:    opts.KnownWords,
+		Seed:          opts.Seed,
+		Replay:        opts.Replay,
+		Fair:          opts.Fair,
 	}
 	return rollerClient.Roll(timeOutCtx, request)
 }
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/aasmall/dicemagic/internal/proto"
+	"github.com/go-redis/redis"
+	"github.com/nlopes/slack"
+)
+
+// FairSession is a provably fair rolling session for a single channel.
+// The commitment (SHA-256 of the server seed) is published when the session starts and the server seed is revealed when it ends,
+// so players can recompute every roll from the seeds and its nonce.
+type FairSession struct {
+	ServerSeed  string
+	Commitment  string
+	ClientSeeds map[string]string
+	Nonce       int64
+}
+
+// ClientSeed combines every player's seed, ordered by user ID
+func (s *FairSession) ClientSeed() string {
+	var users []string
+	for user := range s.ClientSeeds {
+		users = append(users, user)
+	}
+	sort.Strings(users)
+	var seeds []string
+	for _, user := range users {
+		seeds = append(seeds, fmt.Sprintf("%s=%s", user, s.ClientSeeds[user]))
+	}
+	return strings.Join(seeds, ";")
+}
+
+func fairSessionKey(teamID string, channel string) string {
+	return fmt.Sprintf("fair:%s:%s", teamID, channel)
+}
+
+// Every change to a session is a Lua script, so that checking the session and changing it happen at once,
+// even when several players roll or add seeds at the same time.
+var (
+	// startFairScript creates the session KEYS[1] with the server seed ARGV[1] and commitment ARGV[2], for ARGV[3] seconds,
+	// unless there is one already. It returns 0 if there is.
+	startFairScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	return 0
+end
+redis.call("HMSET", KEYS[1], "serverSeed", ARGV[1], "commitment", ARGV[2], "nonce", 0)
+redis.call("EXPIRE", KEYS[1], ARGV[3])
+return 1`)
+	// addFairSeedScript sets the field ARGV[1] of the session KEYS[1] to the seed ARGV[2], unless rolling has started.
+	// It returns -1 without a session and 0 once rolling has started.
+	addFairSeedScript = redis.NewScript(`
+if redis.call("HEXISTS", KEYS[1], "serverSeed") == 0 then
+	return -1
+end
+if tonumber(redis.call("HGET", KEYS[1], "nonce")) > 0 then
+	return 0
+end
+redis.call("HSET", KEYS[1], ARGV[1], ARGV[2])
+return 1`)
+	// nextFairRollScript counts a roll of the session KEYS[1] and returns the session, or nothing without one
+	nextFairRollScript = redis.NewScript(`
+if redis.call("HEXISTS", KEYS[1], "serverSeed") == 0 then
+	return {}
+end
+redis.call("HINCRBY", KEYS[1], "nonce", 1)
+return redis.call("HGETALL", KEYS[1])`)
+	// revealFairScript deletes the session KEYS[1] and returns it, or nothing without one
+	revealFairScript = redis.NewScript(`
+local session = redis.call("HGETALL", KEYS[1])
+redis.call("DEL", KEYS[1])
+return session`)
+)
+
+var errNoFairSession = fmt.Errorf("There is no fair session in this channel. Start one with `!fair start`")
+
+func (c *SlackChatClient) StartFairSession(teamID string, channel string) (*FairSession, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	serverSeed := hex.EncodeToString(b)
+	sum := sha256.Sum256([]byte(serverSeed))
+	session := &FairSession{ServerSeed: serverSeed, Commitment: hex.EncodeToString(sum[:]), ClientSeeds: map[string]string{}}
+	started, err := startFairScript.Run(c.redisClient, []string{fairSessionKey(teamID, channel)},
+		session.ServerSeed, session.Commitment, int64(threeMonths/time.Second)).Int64()
+	if err != nil {
+		return nil, err
+	}
+	if started == 0 {
+		return nil, fmt.Errorf("A fair session is already running in this channel. End it with `!fair reveal`")
+	}
+	return session, nil
+}
+
+func (c *SlackChatClient) GetFairSession(teamID string, channel string) (*FairSession, error) {
+	hash, err := c.redisClient.HGetAll(fairSessionKey(teamID, channel)).Result()
+	if err != nil {
+		return nil, err
+	}
+	return fairSessionFromHash(hash)
+}
+
+// fairSessionFromHash reads a session stored as a hash
+func fairSessionFromHash(hash map[string]string) (*FairSession, error) {
+	if hash["serverSeed"] == "" {
+		return nil, errNoFairSession
+	}
+	session := &FairSession{ServerSeed: hash["serverSeed"], Commitment: hash["commitment"], ClientSeeds: map[string]string{}}
+	session.Nonce, _ = strconv.ParseInt(hash["nonce"], 10, 64)
+	for k, v := range hash {
+		if strings.HasPrefix(k, "client:") {
+			session.ClientSeeds[strings.TrimPrefix(k, "client:")] = v
+		}
+	}
+	return session, nil
+}
+
+// fairSessionFromScript reads a session returned by a script as a flat list of fields and values, like HGETALL
+func fairSessionFromScript(cmd *redis.Cmd) (*FairSession, error) {
+	reply, err := cmd.Result()
+	if err != nil {
+		return nil, err
+	}
+	fields, ok := reply.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected reply from redis: %v", reply)
+	}
+	hash := make(map[string]string)
+	for i := 0; i+1 < len(fields); i += 2 {
+		hash[fmt.Sprint(fields[i])] = fmt.Sprint(fields[i+1])
+	}
+	return fairSessionFromHash(hash)
+}
+
+// AddFairClientSeed sets a player's seed. Seeds can't change once rolling has started.
+func (c *SlackChatClient) AddFairClientSeed(teamID string, channel string, userID string, seed string) error {
+	added, err := addFairSeedScript.Run(c.redisClient, []string{fairSessionKey(teamID, channel)}, "client:"+userID, seed).Int64()
+	if err != nil {
+		return err
+	}
+	switch added {
+	case -1:
+		return errNoFairSession
+	case 0:
+		return fmt.Errorf("Seeds can't change once rolling has started")
+	}
+	return nil
+}
+
+// NextFairRoll returns the seeds for the next roll in the session
+func (c *SlackChatClient) NextFairRoll(teamID string, channel string) (*pb.FairSeed, error) {
+	session, err := fairSessionFromScript(nextFairRollScript.Run(c.redisClient, []string{fairSessionKey(teamID, channel)}))
+	if err != nil {
+		return nil, err
+	}
+	return &pb.FairSeed{ServerSeed: session.ServerSeed, ClientSeed: session.ClientSeed(), Nonce: session.Nonce}, nil
+}
+
+// RevealFairSession ends the session and returns it, including the server seed
+func (c *SlackChatClient) RevealFairSession(teamID string, channel string) (*FairSession, error) {
+	return fairSessionFromScript(revealFairScript.Run(c.redisClient, []string{fairSessionKey(teamID, channel)}))
+}
+
+// FairCommand handles "!fair start", "!fair seed <seed>", "!fair roll <cmd>" and "!fair reveal"
+func (c *SlackChatClient) FairCommand(conn *SlackConnection, ev *slack.MessageEvent, action string, arg string) {
+	post := func(text string) {
+		conn.client.PostMessage(ev.Channel, slack.MsgOptionText(text, false))
+	}
+	switch strings.ToLower(action) {
+	case "start":
+		session, err := c.StartFairSession(ev.Team, ev.Channel)
+		if err != nil {
+			post(err.Error())
+			return
+		}
+		post(fmt.Sprintf("Fair session started. Commitment: `%s`\nAdd your own seed with `!fair seed <anything>` before the first roll.", session.Commitment))
+	case "seed":
+		if arg == "" {
+			post("Usage: `!fair seed <anything>`")
+			return
+		}
+		if err := c.AddFairClientSeed(ev.Team, ev.Channel, ev.User, arg); err != nil {
+			post(err.Error())
+			return
+		}
+		post(fmt.Sprintf("Seed from <@%s> added.", ev.User))
+	case "roll":
+		seed, err := c.NextFairRoll(ev.Team, ev.Channel)
+		if err != nil {
+			post(err.Error())
+			return
+		}
+		post(fmt.Sprintf("Fair roll #%d", seed.Nonce))
+		c.Reply(conn, arg, ev.Channel, RollOptionWithFairSeed(seed), c.knownWords(ev.User, ev.Team))
+	case "reveal":
+		session, err := c.RevealFairSession(ev.Team, ev.Channel)
+		if err != nil {
+			post(err.Error())
+			return
+		}
+		post(fmt.Sprintf("Fair session ended after %d rolls.\nServer seed: `%s`\nCommitment: `%s`\nClient seed: `%s`\nRoll #n can be checked with: `cli -server-seed <server seed> -client-seed <client seed> -nonce n -cmd <roll>`",
+			session.Nonce, session.ServerSeed, session.Commitment, session.ClientSeed()))
+	default:
+		post("Usage: `!fair start`, `!fair seed <anything>`, `!fair roll <roll>` or `!fair reveal`")
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"sync"
+	"testing"
+)
+
+// fairTestClient connects to the Redis at REDIS_TEST_ADDR, which the fair session scripts need to run
+func fairTestClient(t *testing.T) *SlackChatClient {
+	c := redisTestClient(t)
+	// a session left behind by an earlier run would already be started
+	c.redisClient.Del(fairSessionKey("team", t.Name()))
+	return c
+}
+
+func TestFairSession_ConcurrentRolls(t *testing.T) {
+	c := fairTestClient(t)
+	defer c.redisClient.Del(fairSessionKey("team", t.Name()))
+	if _, err := c.StartFairSession("team", t.Name()); err != nil {
+		t.Fatalf("StartFairSession() error = %v", err)
+	}
+	if _, err := c.StartFairSession("team", t.Name()); err == nil {
+		t.Errorf("StartFairSession() should not start a second session")
+	}
+	if err := c.AddFairClientSeed("team", t.Name(), "U1", "lucky"); err != nil {
+		t.Fatalf("AddFairClientSeed() error = %v", err)
+	}
+	const rolls = 50
+	nonces := make(chan int64, rolls)
+	var wg sync.WaitGroup
+	for i := 0; i < rolls; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			seed, err := c.NextFairRoll("team", t.Name())
+			if err != nil {
+				t.Errorf("NextFairRoll() error = %v", err)
+				return
+			}
+			if seed.ClientSeed != "U1=lucky" {
+				t.Errorf("NextFairRoll() client seed = %q, want %q", seed.ClientSeed, "U1=lucky")
+			}
+			nonces <- seed.Nonce
+		}()
+	}
+	wg.Wait()
+	close(nonces)
+	seen := make(map[int64]bool)
+	for nonce := range nonces {
+		if seen[nonce] || nonce < 1 || nonce > rolls {
+			t.Errorf("NextFairRoll() nonce %d is repeated or out of range", nonce)
+		}
+		seen[nonce] = true
+	}
+	if err := c.AddFairClientSeed("team", t.Name(), "U2", "late"); err == nil {
+		t.Errorf("AddFairClientSeed() should fail once rolling has started")
+	}
+	session, err := c.RevealFairSession("team", t.Name())
+	if err != nil {
+		t.Fatalf("RevealFairSession() error = %v", err)
+	}
+	if session.Nonce != rolls {
+		t.Errorf("RevealFairSession() nonce = %d, want %d", session.Nonce, rolls)
+	}
+	if _, err := c.NextFairRoll("team", t.Name()); err != errNoFairSession {
+		t.Errorf("NextFairRoll() after reveal error = %v, want %v", err, errNoFairSession)
+	}
+}
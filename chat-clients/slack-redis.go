@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/binary"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/serialx/hashring"
@@ -49,12 +50,59 @@ func (c *SlackChatClient) GetLastCommand(userID string, teamID string) (string,
 	}
 	return cmd, err
 }
-func (c SlackChatClient) SaveCommand(userID string, teamID string, commandMap map[string]string) error {
+func (c *SlackChatClient) SaveCommand(userID string, teamID string, commandMap map[string]string) error {
 	key := fmt.Sprintf("command:%s:%s:%s", teamID, userID, commandMap["name"])
 	go c.SlackDatastoreClient.UpsetRedisCommand(context.Background(),
 		&RedisCommand{TeamID: teamID, UserID: userID, CommandKey: commandMap["name"], CommandValue: commandMap["cmd"], Expire: time.Now().Add(threeMonths)},
 		c.config.slackAppID)
-	return c.redisClient.Set(key, commandMap["cmd"], threeMonths).Err()
+	if err := c.redisClient.Set(key, commandMap["cmd"], threeMonths).Err(); err != nil {
+		return err
+	}
+	return c.addCommandName(userID, teamID, commandMap["name"], time.Now())
+}
+
+// commandNamesKey is the hash of every command a user has saved, by name, to when it expires
+func commandNamesKey(userID string, teamID string) string {
+	return fmt.Sprintf("command-names:%s:%s", teamID, userID)
+}
+
+// addCommandName records that a user saved or used a command at tick. Commands saved before the hash
+// existed are added the next time they are used.
+func (c *SlackChatClient) addCommandName(userID string, teamID string, name string, tick time.Time) error {
+	key := commandNamesKey(userID, teamID)
+	if err := c.redisClient.HSet(key, name, tick.Add(threeMonths).Format(timeFormat)).Err(); err != nil {
+		return err
+	}
+	return c.redisClient.Expire(key, threeMonths).Err()
+}
+
+// GetCommandNames returns the names of every command a user has saved, forgetting those that have expired
+func (c *SlackChatClient) GetCommandNames(userID string, teamID string) ([]string, error) {
+	key := commandNamesKey(userID, teamID)
+	hashMap, err := c.redisClient.HGetAll(key).Result()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for name, v := range hashMap {
+		expires, err := time.Parse(timeFormat, v)
+		if err != nil || time.Now().After(expires) {
+			c.redisClient.HDel(key, name)
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// knownWords suggests the names of a user's saved commands for typos in their rolls
+func (c *SlackChatClient) knownWords(userID string, teamID string) RollOption {
+	names, err := c.GetCommandNames(userID, teamID)
+	if err != nil {
+		c.log.Debugf("could not get command names: %s", err)
+	}
+	return RollOptionWithKnownWords(names)
 }
 func (c *SlackChatClient) GetCommand(userID string, teamID string, commandMap map[string]string) (string, error) {
 	key := fmt.Sprintf("command:%s:%s:%s", teamID, userID, commandMap["name"])
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// redisTestClient connects to the Redis at REDIS_TEST_ADDR, skipping the test when it isn't set
+func redisTestClient(t *testing.T) *SlackChatClient {
+	addr := os.Getenv("REDIS_TEST_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_TEST_ADDR is not set")
+	}
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	if err := client.Ping().Err(); err != nil {
+		t.Fatalf("could not connect to redis at %s: %v", addr, err)
+	}
+	return &SlackChatClient{redisClient: client}
+}
+
+func TestGetCommandNames(t *testing.T) {
+	c := redisTestClient(t)
+	key := commandNamesKey(t.Name(), "team")
+	c.redisClient.Del(key)
+	defer c.redisClient.Del(key)
+	now := time.Now()
+	for name, saved := range map[string]time.Time{"attack": now, "heal": now.Add(-time.Hour), "old": now.Add(-threeMonths - time.Hour)} {
+		if err := c.addCommandName(t.Name(), "team", name, saved); err != nil {
+			t.Fatalf("addCommandName() error = %v", err)
+		}
+	}
+	// the last command is not a saved command
+	c.redisClient.Set("command:team:"+t.Name()+":!!", "1d20", time.Minute)
+	defer c.redisClient.Del("command:team:" + t.Name() + ":!!")
+
+	names, err := c.GetCommandNames(t.Name(), "team")
+	if err != nil {
+		t.Fatalf("GetCommandNames() error = %v", err)
+	}
+	if want := []string{"attack", "heal"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("GetCommandNames() = %q, want %q", names, want)
+	}
+	if c.redisClient.HExists(key, "old").Val() {
+		t.Errorf("GetCommandNames() kept the expired command")
+	}
+}
@@ -8,7 +8,8 @@ import (
 
 	"github.com/serialx/hashring"
 
-	"github.com/aasmall/dicemagic/internal/dicelang/errors"
+	"github.com/aasmall/dicemagic/dicelang"
+	"github.com/aasmall/dicemagic/dicelang/errors"
 	pb "github.com/aasmall/dicemagic/internal/proto"
 	"github.com/nlopes/slack"
 	"golang.org/x/net/context"
@@ -97,6 +98,7 @@ func (c *SlackChatClient) listen(ctx context.Context, connectionInfo *SlackConne
 	var err error
 	saveCommand := regexp.MustCompile(`(?i)^!(?P<name>\w+)\b\s*=[\t|\f|\v| ]*(?P<cmd>.*)$`)
 	execCommand := regexp.MustCompile(`(?i)^!(?P<name>\w+)\b\s*$`)
+	fairCommand := regexp.MustCompile(`(?i)^!fair\s+(?P<action>\w+)\b\s*(?P<arg>.*)$`)
 	go connectionInfo.conn.ManageConnection()
 	for msg := range connectionInfo.conn.IncomingEvents {
 		switch ev := msg.Data.(type) {
@@ -124,11 +126,19 @@ func (c *SlackChatClient) listen(ctx context.Context, connectionInfo *SlackConne
 						if err != nil {
 							continue
 						}
-						c.Reply(connectionInfo, cmd, ev.Channel)
+						c.Reply(connectionInfo, cmd, ev.Channel, c.knownWords(ev.User, ev.Team))
 						break
+					case fairCommand.MatchString(cmd):
+						fairCommandMap := regexToMap(fairCommand, cmd)
+						c.log.Debugf("Fair: %s", fairCommandMap)
+						c.FairCommand(connectionInfo, ev, fairCommandMap["action"], fairCommandMap["arg"])
 					case saveCommand.MatchString(cmd):
 						saveCommandMap := regexToMap(saveCommand, cmd)
 						c.log.Debugf("Save: %s", saveCommandMap)
+						// store the canonical form, so equivalent commands are saved identically
+						if normalized, err := dicelang.FormatSource(saveCommandMap["cmd"]); err == nil && normalized != "" {
+							saveCommandMap["cmd"] = normalized
+						}
 						err = c.SaveCommand(ev.User, ev.Team, saveCommandMap)
 						if err != nil {
 							continue
@@ -139,15 +149,19 @@ func (c *SlackChatClient) listen(ctx context.Context, connectionInfo *SlackConne
 						c.log.Debugf("Exec: %s", execCommandMap)
 						cmd, err = c.GetCommand(ev.User, ev.Team, execCommandMap)
 						if err != nil {
+							names, _ := c.GetCommandNames(ev.User, ev.Team)
+							if name, ok := dicelang.Suggest(execCommandMap["name"], names); ok {
+								connectionInfo.client.PostMessage(ev.Channel, slack.MsgOptionText(fmt.Sprintf(`Unknown command "%s", did you mean "!%s"?`, execCommandMap["name"], name), false))
+							}
 							continue
 						}
-						c.Reply(connectionInfo, cmd, ev.Channel)
+						c.Reply(connectionInfo, cmd, ev.Channel, c.knownWords(ev.User, ev.Team))
 					default:
 						connectionInfo.client.PostMessage(ev.Channel, slack.MsgOptionText("Unrecognized command.", false))
 
 					}
 				} else {
-					c.Reply(connectionInfo, cmd, ev.Channel)
+					c.Reply(connectionInfo, cmd, ev.Channel, c.knownWords(ev.User, ev.Team))
 					c.SetLastCommand(ev.User, ev.Team, cmd)
 				}
 			}
@@ -188,10 +202,11 @@ func (c *SlackChatClient) IsMention(text string, botID string) (bool, string) {
 	return false, strings.TrimSpace(text)
 }
 
-func (c *SlackChatClient) Reply(conn *SlackConnection, cmd string, channel string) {
+func (c *SlackChatClient) Reply(conn *SlackConnection, cmd string, channel string, options ...RollOption) {
 	var rollResponse *pb.RollResponse
 	var err error
-	rollResponse, err = Roll(c.diceClient, cmd)
+	options = append([]RollOption{RollOptionWithStatistics(c.config.slackShowStats)}, options...)
+	rollResponse, err = Roll(c.diceClient, cmd, options...)
 	if err != nil {
 		c.log.Errorf("Unexpected error: %+v", err)
 		conn.client.PostMessage(channel, slack.MsgOptionText(fmt.Sprintf("Oops! an unexpected error occured: %s", err), false))
@@ -199,14 +214,14 @@ func (c *SlackChatClient) Reply(conn *SlackConnection, cmd string, channel strin
 	}
 	if !rollResponse.Ok {
 		if rollResponse.Error.Code == errors.Friendly {
-			conn.client.PostMessage(channel, slack.MsgOptionText(rollResponse.Error.Msg, false))
+			conn.client.PostMessage(channel, slack.MsgOptionText(slackErrorText(rollResponse.Error), false))
 			return
 		} else {
 			conn.client.PostMessage(channel, slack.MsgOptionText(fmt.Sprintf("Oops! an error occured: %s", rollResponse.Error.Msg), false))
 			return
 		}
 	}
-	attachments := SlackAttachmentsFromRollResponse(rollResponse)
+	attachments := SlackAttachmentsFromRollResponse(rollResponse, c.config.slackShowStats)
 	conn.client.PostMessage(channel, slack.MsgOptionAttachments(attachments...))
 }
 func (c *SlackChatClient) ManageSlackConnections(ctx context.Context, freq time.Duration) {
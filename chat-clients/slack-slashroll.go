@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"net/http"
 
-	"github.com/aasmall/dicemagic/internal/dicelang/errors"
+	"github.com/aasmall/dicemagic/dicelang/errors"
 	"github.com/aasmall/dicemagic/internal/handler"
 	"github.com/nlopes/slack"
 )
@@ -26,7 +26,7 @@ func SlackSlashRollHandler(e interface{}, w http.ResponseWriter, r *http.Request
 	if err != nil {
 		fmt.Fprintf(w, "could not parse slash command: %s", err)
 	}
-	rollResponse, err := Roll(c.diceClient, s.Text)
+	rollResponse, err := Roll(c.diceClient, s.Text, RollOptionWithStatistics(c.config.slackShowStats), c.knownWords(s.UserID, s.TeamID))
 	if err != nil {
 		c.log.Errorf("Unexpected error: %+v", err)
 		returnErrorToSlack(fmt.Sprintf("Oops! an unexpected error occured: %s", err), w, r)
@@ -34,7 +34,7 @@ func SlackSlashRollHandler(e interface{}, w http.ResponseWriter, r *http.Request
 	}
 	if !rollResponse.Ok {
 		if rollResponse.Error.Code == errors.Friendly {
-			returnErrorToSlack(rollResponse.Error.Msg, w, r)
+			returnErrorToSlack(slackErrorText(rollResponse.Error), w, r)
 			return nil
 		} else {
 			returnErrorToSlack(fmt.Sprintf("Oops! an error occured: %s", rollResponse.Error.Msg), w, r)
@@ -42,7 +42,7 @@ func SlackSlashRollHandler(e interface{}, w http.ResponseWriter, r *http.Request
 		}
 	}
 	webhookMessage := slack.Msg{}
-	webhookMessage.Attachments = append(webhookMessage.Attachments, SlackAttachmentsFromRollResponse(rollResponse)...)
+	webhookMessage.Attachments = append(webhookMessage.Attachments, SlackAttachmentsFromRollResponse(rollResponse, c.config.slackShowStats)...)
 	c.log.Errorf("Webook Message Sending: %+v", webhookMessage)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(webhookMessage)
@@ -91,7 +91,15 @@ func returnErrorToSlack(text string, w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(SlackRollJSONResponse{Text: text})
 }
 
-func SlackAttachmentsFromRollResponse(rr *pb.RollResponse) []slack.Attachment {
+// slackErrorText returns the message of a RollError, with the command marked up in a code block when it did not parse
+func slackErrorText(e *pb.RollError) string {
+	if e.Caret == "" {
+		return e.Msg
+	}
+	return fmt.Sprintf("%s\n```%s```", e.Msg, e.Caret)
+}
+
+func SlackAttachmentsFromRollResponse(rr *pb.RollResponse, withStats bool) []slack.Attachment {
 	var sets []slack.Attachment
 	retSlackAttachment := slack.Attachment{
 		Fallback: totalsMapString(rr.DiceSet.TotalsByColor),
@@ -99,24 +107,37 @@ func SlackAttachmentsFromRollResponse(rr *pb.RollResponse) []slack.Attachment {
 	}
 	var fields []slack.AttachmentField
 	for _, ds := range rr.DiceSets {
-		var faces []interface{}
-		for _, d := range ds.Dice {
-			faces = append(faces, facesSliceString(d.Faces))
-		}
 		field := slack.AttachmentField{
-			Value: fmt.Sprintf(ds.ReString, faces...),
+			Value: resultString(ds),
 			Short: false,
 		}
-		field.Value = fmt.Sprintf("%s = *%s*", field.Value, strconv.FormatInt(ds.Total, 10))
+		field.Value = fmt.Sprintf("%s = *%s*", field.Value, totalString(ds))
+		if stats := statsString(ds); withStats && stats != "" {
+			field.Value = fmt.Sprintf("%s %s", field.Value, stats)
+		}
 		fields = append(fields, field)
 	}
 	if len(rr.DiceSets) > 1 {
+		title := fmt.Sprintf("Total: %s", totalString(rr.DiceSet))
+		if stats := statsString(rr.DiceSet); withStats && stats != "" {
+			title = fmt.Sprintf("%s %s", title, stats)
+		}
 		fields = append(fields, slack.AttachmentField{
-			Title: fmt.Sprintf("Total: %s", strconv.FormatInt(rr.DiceSet.Total, 10)),
+			Title: title,
 			Short: false})
 	}
 	retSlackAttachment.Fields = fields
 	sets = append(sets, retSlackAttachment)
+	if len(rr.Warnings) > 0 {
+		var warnings []string
+		for _, w := range rr.Warnings {
+			warnings = append(warnings, w.Message)
+		}
+		sets = append(sets, slack.Attachment{
+			Fallback: strings.Join(warnings, "\n"),
+			Text:     strings.Join(warnings, "\n"),
+		})
+	}
 	return sets
 }
 
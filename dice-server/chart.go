@@ -1,9 +1,11 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
 	"net/http"
 
+	"github.com/aasmall/dicemagic/dicelang"
 	"github.com/wcharczuk/go-chart"
 )
 
@@ -43,3 +45,47 @@ func drawChart(res http.ResponseWriter, req *http.Request) {
 		fmt.Printf("Error rendering chart: %v\n", err)
 	}
 }
+
+// compareChart renders the distributions of two expressions as a PNG line chart, in percent.
+func compareChart(nameA string, a dicelang.Distribution, nameB string, b dicelang.Distribution) ([]byte, error) {
+	graph := chart.Chart{
+		Background: chart.Style{
+			Padding: chart.Box{
+				Top:  20,
+				Left: 20,
+			},
+		},
+		Height: 512,
+		XAxis: chart.XAxis{
+			Name:      "Result",
+			NameStyle: chart.StyleShow(),
+			Style:     chart.StyleShow(),
+		},
+		YAxis: chart.YAxis{
+			Name:      "%",
+			NameStyle: chart.StyleShow(),
+			Style:     chart.StyleShow(),
+		},
+		Series: []chart.Series{
+			distributionSeries(nameA, a),
+			distributionSeries(nameB, b),
+		},
+	}
+	graph.Elements = []chart.Renderable{chart.Legend(&graph)}
+
+	buffer := bytes.NewBuffer([]byte{})
+	err := graph.Render(chart.PNG, buffer)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func distributionSeries(name string, d dicelang.Distribution) chart.ContinuousSeries {
+	series := chart.ContinuousSeries{Name: name}
+	for _, k := range d.Outcomes() {
+		series.XValues = append(series.XValues, k)
+		series.YValues = append(series.YValues, d[k]*100)
+	}
+	return series
+}
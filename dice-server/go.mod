@@ -5,8 +5,7 @@ go 1.12
 require (
 	cloud.google.com/go v0.36.0
 	contrib.go.opencensus.io/exporter/stackdriver v0.9.1
-	github.com/aasmall/dicemagic/internal/dicelang v0.1.0
-	github.com/aasmall/dicemagic/internal/dicelang/errors v0.1.0
+	github.com/aasmall/dicemagic/dicelang v0.1.0
 	github.com/aasmall/dicemagic/internal/logger v0.1.0
 	github.com/aasmall/dicemagic/internal/proto v0.1.0
 	github.com/census-instrumentation/opencensus-proto v0.1.0 // indirect
@@ -18,9 +17,7 @@ require (
 	google.golang.org/grpc v1.19.0
 )
 
-replace github.com/aasmall/dicemagic/internal/dicelang v0.1.0 => ../internal/dicelang
-
-replace github.com/aasmall/dicemagic/internal/dicelang/errors v0.1.0 => ../internal/dicelang/errors
+replace github.com/aasmall/dicemagic/dicelang v0.1.0 => ../dicelang
 
 replace github.com/aasmall/dicemagic/internal/logger v0.1.0 => ../internal/logger
 
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"time"
+
+	pb "github.com/aasmall/dicemagic/internal/proto"
+)
+
+// receiptSigner signs and verifies RollResponses with HMAC-SHA256
+type receiptSigner struct {
+	secret []byte
+}
+
+func newReceiptSigner(secret string) *receiptSigner {
+	if secret == "" {
+		return nil
+	}
+	return &receiptSigner{secret: []byte(secret)}
+}
+
+// sign attaches a receipt covering everything rr shows, and the time it was rolled.
+func (r *receiptSigner) sign(rr *pb.RollResponse, now time.Time) {
+	timestamp := now.UnixNano()
+	rr.Receipt = &pb.Receipt{
+		Timestamp: timestamp,
+		Signature: r.calculateHMAC(receiptPayload(rr, timestamp)),
+	}
+}
+
+// verify reports whether the receipt of rr was signed by this signer, and rr has not changed since.
+func (r *receiptSigner) verify(rr *pb.RollResponse) bool {
+	if rr.Receipt == nil {
+		return false
+	}
+	expected := r.calculateHMAC(receiptPayload(rr, rr.Receipt.Timestamp))
+	return hmac.Equal(expected, rr.Receipt.Signature)
+}
+
+func (r *receiptSigner) calculateHMAC(data []byte) []byte {
+	h := hmac.New(sha256.New, r.secret)
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// receiptPayload serializes everything a receipt covers, which is every field of rr a user may see, and the draws that replay it.
+// Protobuf encoding is not used because it is not deterministic for maps.
+func receiptPayload(rr *pb.RollResponse, timestamp int64) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "cmd:%q\ntimestamp:%d\n", rr.Cmd, timestamp)
+	writeDiceSet(&b, "root", rr.DiceSet)
+	for i, ds := range rr.DiceSets {
+		writeDiceSet(&b, fmt.Sprintf("set %d", i), ds)
+	}
+	for _, d := range rr.Transcript {
+		fmt.Fprintf(&b, "draw:%d:%d\n", d.N, d.Value)
+	}
+	for _, w := range rr.Warnings {
+		fmt.Fprintf(&b, "warning:%d:%q:%d:%d:%d:%d\n", w.Severity, w.Message, w.Start.GetLine(), w.Start.GetCol(), w.End.GetLine(), w.End.GetCol())
+	}
+	return b.Bytes()
+}
+
+func writeDiceSet(b *bytes.Buffer, name string, ds *pb.DiceSet) {
+	if ds == nil {
+		fmt.Fprintf(b, "%s:nil\n", name)
+		return
+	}
+	fmt.Fprintf(b, "%s:total:%d:values:%v\n", name, ds.Total, ds.Values)
+	fmt.Fprintf(b, "%s:restring:%q\n", name, ds.ReString)
+	fmt.Fprintf(b, "%s:stats:%v:%v:%v:%v:%v:%v:%v\n", name, ds.HasStatistics, ds.Mean, ds.StdDev, ds.Percentile5, ds.Percentile50, ds.Percentile95, ds.TopPercent)
+	var colors []string
+	for color := range ds.TotalsByColor {
+		colors = append(colors, color)
+	}
+	sort.Strings(colors)
+	for _, color := range colors {
+		fmt.Fprintf(b, "%s:color:%q:%v\n", name, color, ds.TotalsByColor[color])
+	}
+	writeBounds(b, name+":bounds", ds.Bounds)
+	colors = colors[:0]
+	for color := range ds.BoundsByColor {
+		colors = append(colors, color)
+	}
+	sort.Strings(colors)
+	for _, color := range colors {
+		writeBounds(b, fmt.Sprintf("%s:bounds:%q", name, color), ds.BoundsByColor[color])
+	}
+	for i, d := range ds.Dice {
+		writeDice(b, fmt.Sprintf("%s:dice %d", name, i), d)
+	}
+	writeResult(b, name+":result", ds.Result)
+	writeTrace(b, name+":explanation", ds.Explanation)
+}
+
+func writeDice(b *bytes.Buffer, name string, d *pb.Dice) {
+	fmt.Fprintf(b, "%s:%dd%d:%q:-H%d:-L%d:explode:%d:success:%d:botch:%d:total:%d:min:%d:max:%d:faces:%v:chart:%x\n",
+		name, d.Count, d.Sides, d.Color, d.DropHighest, d.DropLowest, d.ExplodeDepth, d.SuccessTarget, d.BotchTarget, d.Total, d.Min, d.Max, d.Faces, d.Chart)
+	var results []int64
+	for result := range d.Probabilities {
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i] < results[j] })
+	for _, result := range results {
+		fmt.Fprintf(b, "%s:probability:%d:%v\n", name, result, d.Probabilities[result])
+	}
+}
+
+func writeBounds(b *bytes.Buffer, name string, bounds *pb.Bounds) {
+	if bounds != nil {
+		fmt.Fprintf(b, "%s:%v:%v:%v\n", name, bounds.Min, bounds.Max, bounds.Mean)
+	}
+}
+
+func writeResult(b *bytes.Buffer, name string, n *pb.ResultNode) {
+	if n == nil {
+		return
+	}
+	fmt.Fprintf(b, "%s:%d:%q:%d\n", name, n.Kind, n.Text, n.DiceIndex)
+	for i, child := range n.Children {
+		writeResult(b, fmt.Sprintf("%s.%d", name, i), child)
+	}
+}
+
+func writeTrace(b *bytes.Buffer, name string, n *pb.TraceNode) {
+	if n == nil {
+		return
+	}
+	fmt.Fprintf(b, "%s:%q:%q:%v:%q\n", name, n.Sym, n.Value, n.Result, n.Text)
+	for i, d := range n.Dice {
+		writeDice(b, fmt.Sprintf("%s:dice %d", name, i), d)
+	}
+	for i, child := range n.Children {
+		writeTrace(b, fmt.Sprintf("%s.%d", name, i), child)
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	pb "github.com/aasmall/dicemagic/internal/proto"
+	"golang.org/x/net/context"
+)
+
+// signedRoll rolls cmd on a server that signs rolls, with everything a response can show
+func signedRoll(t *testing.T, s *server, cmd string) *pb.RollResponse {
+	out, err := s.Roll(context.Background(), &pb.RollRequest{Cmd: cmd, Seed: 3, Probabilities: true, Statistics: true, Explain: true, Bounds: true})
+	if err != nil || !out.Ok {
+		t.Fatalf("Roll() error = %v, %v", err, out.Error)
+	}
+	if out.Receipt == nil {
+		t.Fatalf("Roll() has no receipt")
+	}
+	return out
+}
+
+func TestReceipt_Verify(t *testing.T) {
+	s := newTestServer()
+	out := signedRoll(t, s, "roll 4d6-L fire, 1d20 + 5 ice")
+	if !s.signer.verify(out) {
+		t.Errorf("verify() = false for an untouched roll")
+	}
+	// the verify command reads rolls as JSON
+	data, err := json.Marshal(out)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var decoded pb.RollResponse
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if !s.signer.verify(&decoded) {
+		t.Errorf("verify() = false for a roll decoded from JSON")
+	}
+	res, err := s.Verify(context.Background(), &pb.VerifyRequest{Roll: out})
+	if err != nil || !res.Ok || !res.Valid {
+		t.Errorf("Verify() = %+v, %v, want a valid roll", res, err)
+	}
+	other := newServer(&env{log: s.env.log, config: &envConfig{receiptSecret: "another secret"}})
+	if other.signer.verify(out) {
+		t.Errorf("verify() = true for a roll signed with another secret")
+	}
+	if s.signer.verify(&pb.RollResponse{Cmd: out.Cmd, DiceSet: out.DiceSet}) {
+		t.Errorf("verify() = true for a roll without a receipt")
+	}
+}
+
+func TestReceipt_Tampered(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(rr *pb.RollResponse)
+	}{
+		{"command", func(rr *pb.RollResponse) { rr.Cmd = "roll 4d6 fire, 1d20 + 5 ice" }},
+		{"timestamp", func(rr *pb.RollResponse) { rr.Receipt.Timestamp += int64(time.Hour) }},
+		{"total", func(rr *pb.RollResponse) { rr.DiceSet.Total++ }},
+		{"color total", func(rr *pb.RollResponse) { rr.DiceSet.TotalsByColor["Fire"]++ }},
+		{"face", func(rr *pb.RollResponse) { rr.DiceSet.Dice[0].Faces[0]++ }},
+		{"drop", func(rr *pb.RollResponse) { rr.DiceSet.Dice[0].DropLowest = 0 }},
+		{"explosions", func(rr *pb.RollResponse) { rr.DiceSet.Dice[0].ExplodeDepth = 1 }},
+		{"success target", func(rr *pb.RollResponse) { rr.DiceSet.Dice[0].SuccessTarget = 5 }},
+		{"botch target", func(rr *pb.RollResponse) { rr.DiceSet.Dice[0].BotchTarget = 1 }},
+		{"probability", func(rr *pb.RollResponse) { rr.DiceSet.Dice[1].Probabilities[20] = 50 }},
+		{"values", func(rr *pb.RollResponse) { rr.DiceSet.Values = []float64{18} }},
+		{"restring", func(rr *pb.RollResponse) { rr.DiceSet.ReString = "roll 20" }},
+		{"statistics", func(rr *pb.RollResponse) { rr.DiceSet.TopPercent = 1 }},
+		{"statistics presence", func(rr *pb.RollResponse) { rr.DiceSet.HasStatistics = !rr.DiceSet.HasStatistics }},
+		{"bounds", func(rr *pb.RollResponse) { rr.DiceSet.Bounds.Max = 100 }},
+		{"result", func(rr *pb.RollResponse) { rr.DiceSet.Result.Text = "Roll 20" }},
+		{"explanation", func(rr *pb.RollResponse) { rr.DiceSet.Explanation.Text = "20" }},
+		{"statement", func(rr *pb.RollResponse) { rr.DiceSets[1].Total = 25 }},
+		{"transcript", func(rr *pb.RollResponse) { rr.Transcript[0].Value = 5 }},
+		{"warnings", func(rr *pb.RollResponse) { rr.Warnings = append(rr.Warnings, &pb.Diagnostic{Message: "fake"}) }},
+	}
+	s := newTestServer()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := signedRoll(t, s, "roll 4d6-L fire, 1d20 + 5 ice")
+			tt.tamper(out)
+			if s.signer.verify(out) {
+				t.Errorf("verify() = true after tampering with the %s", tt.name)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"runtime/debug"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// recoverPanics is a grpc interceptor which turns a panic in a handler into an Internal error,
+// so a single bad command can't take down the server.
+func (e *env) recoverPanics(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			e.log.Criticalf("panic in %s handling %+v: %v\n%s", info.FullMethod, req, r, debug.Stack())
+			resp, err = nil, status.Errorf(codes.Internal, "An unexpected Error has occured. Please try again later")
+		}
+	}()
+	return handler(ctx, req)
+}
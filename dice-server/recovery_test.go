@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+
+	log "github.com/aasmall/dicemagic/internal/logger"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRecoverPanics(t *testing.T) {
+	e := &env{log: log.NewLocal()}
+	info := &grpc.UnaryServerInfo{FullMethod: "/proto.Roller/Roll"}
+	resp, err := e.recoverPanics(context.Background(), "1d6", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("bad command")
+	})
+	if resp != nil || status.Code(err) != codes.Internal {
+		t.Errorf("recoverPanics() = %v, %v, want an Internal error", resp, err)
+	}
+	resp, err = e.recoverPanics(context.Background(), "1d6", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "rolled " + req.(string), nil
+	})
+	if resp != "rolled 1d6" || err != nil {
+		t.Errorf("recoverPanics() = %v, %v, want the handler's response", resp, err)
+	}
+}
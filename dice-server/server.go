@@ -3,16 +3,19 @@
 package main
 
 import (
+	"fmt"
 	"net"
+	"os"
 	"sort"
+	"time"
 
 	"cloud.google.com/go/logging"
 	"contrib.go.opencensus.io/exporter/stackdriver"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 
-	"github.com/aasmall/dicemagic/internal/dicelang"
-	"github.com/aasmall/dicemagic/internal/dicelang/errors"
+	"github.com/aasmall/dicemagic/dicelang"
+	"github.com/aasmall/dicemagic/dicelang/errors"
 	log "github.com/aasmall/dicemagic/internal/logger"
 	pb "github.com/aasmall/dicemagic/internal/proto"
 	"golang.org/x/net/context"
@@ -34,17 +37,22 @@ type envConfig struct {
 	local            bool
 	podName          string
 	traceProbability float64
+	receiptSecret    string
 }
 type server struct {
-	env *env
+	env    *env
+	signer *receiptSigner
 }
 
 func newServer(e *env) *server {
-	s := &server{env: e}
+	s := &server{env: e, signer: newReceiptSigner(e.config.receiptSecret)}
 	return s
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "verify" {
+		os.Exit(verifyCommand(os.Args[2:]))
+	}
 	configReader := new(envReader)
 
 	config := &envConfig{
@@ -55,6 +63,7 @@ func main() {
 		local:            configReader.getEnvBoolOpt("LOCAL"),
 		traceProbability: configReader.getEnvFloat("TRACE_PROBABILITY"),
 		podName:          configReader.getEnv("POD_NAME"),
+		receiptSecret:    configReader.getEnvOpt("RECEIPT_SIGNING_SECRET"),
 	}
 	if configReader.errors {
 		log.Fatalf("could not gather environment variables. Failed variables: %v", configReader.missingKeys)
@@ -86,7 +95,7 @@ func main() {
 		env.log.Criticalf("failed to listen: %v", err)
 		return
 	}
-	s := grpc.NewServer(grpc.StatsHandler(&ocgrpc.ServerHandler{}))
+	s := grpc.NewServer(grpc.StatsHandler(&ocgrpc.ServerHandler{}), grpc.UnaryInterceptor(env.recoverPanics))
 	pb.RegisterRollerServer(s, newServer(env))
 
 	// Register reflection service on gRPC server.
@@ -99,109 +108,359 @@ func main() {
 }
 
 func (s *server) handleExposedErrors(e error, response *pb.RollResponse) error {
-	log := s.env.log
-	response.Error = &pb.RollError{}
 	response.Ok = false
+	var err error
+	response.Error, err = s.exposedError(e)
+	return err
+}
+
+// exposedError converts e into a RollError safe to return to clients.
+// Errors that are not DicelangErrors are returned so they can be surfaced over grpc.
+func (s *server) exposedError(e error) (*pb.RollError, error) {
+	log := s.env.log
+	rollError := &pb.RollError{}
 	switch e := e.(type) {
+	case *dicelang.ParseError:
+		log.Debugf("ParseError: %+v", e)
+		rollError.Code = errors.Friendly
+		rollError.Msg = fmt.Sprintf("Your command could not be parsed: %s", e.Error())
+		rollError.Caret = e.Caret()
+		for _, d := range e.Diagnostics {
+			rollError.Diagnostics = append(rollError.Diagnostics, diagnosticToPb(d))
+		}
 	case *errors.DicelangError:
 		log.Debugf("DiceLangError: %+v", e)
-		response.Error.Code = e.Code
-		switch response.Error.Code {
+		rollError.Code = e.Code
+		switch rollError.Code {
 		case errors.InvalidAST:
-			response.Error.Msg = "The AST that resulted from your command was invalid."
+			rollError.Msg = "The AST that resulted from your command was invalid."
 		case errors.InvalidCommand:
-			response.Error.Msg = "Your command could not be parsed."
+			rollError.Msg = "Your command could not be parsed."
 		case errors.Friendly:
-			response.Error.Msg = e.Error()
-		case errors.Unexpected:
+			rollError.Msg = e.Error()
 		default:
-			panic("Unexpected error can't surface.")
+			rollError.Code = errors.Unexpected
+			rollError.Msg = "An unexpected Error has occured. Please try again later"
+			log.Criticalf("A DicelangError with an unexpected code occured: %+v", e)
 		}
 	default:
-		response.Error.Code = errors.Unexpected
-		response.Error.Msg = "An unexpected Error has occured. Please try again later"
+		rollError.Code = errors.Unexpected
+		rollError.Msg = "An unexpected Error has occured. Please try again later"
 		s.env.log.Criticalf("An unhandled error occured: %+v", e)
-		return e
+		return rollError, e
 	}
-	return nil
+	return rollError, nil
 }
 
-func (s *server) astToPbDiceSets(p bool, c bool, ro bool, tree *dicelang.AST) (*pb.DiceSet, []*pb.DiceSet, error) {
-	log := s.env.log
-	var fTotal float64
-	if tree == nil {
-		return nil, nil, errors.NewDicelangError("No dice sets resulted from that command", errors.InvalidCommand, nil)
+func diagnosticToPb(d dicelang.Diagnostic) *pb.Diagnostic {
+	severity := pb.Diagnostic_ERROR
+	if d.Severity == dicelang.SeverityWarning {
+		severity = pb.Diagnostic_WARNING
 	}
-	total, ds, err := tree.GetDiceSet()
-	if err != nil {
-		return nil, nil, err
+	return &pb.Diagnostic{
+		Severity: severity,
+		Message:  d.Message,
+		Start:    &pb.Position{Line: int32(d.Start.Line), Col: int32(d.Start.Col)},
+		End:      &pb.Position{Line: int32(d.End.Line), Col: int32(d.End.Col)},
 	}
-	restring, err := tree.String()
-	if err != nil {
-		return nil, nil, err
+}
+
+// maxRolledStatements is the most statements, counting every repetition of a REP statement, rolled for one command
+const maxRolledStatements = 10000
+
+// astToPbDiceSets rolls every statement of tree, and every repetition of a statement like "1d20 rep 3", once and on its
+// own DiceSet. They are rolled at the same time when src allows it. The DiceSet of the whole command is put together
+// from them, so no dice are rolled twice. It also returns every draw, in an order that replays the command.
+func (s *server) astToPbDiceSets(p bool, c bool, ro bool, st bool, ex bool, bo bool, tree *dicelang.AST, src dicelang.RandomSource, budget *dicelang.Budget) (*pb.DiceSet, []*pb.DiceSet, []dicelang.Draw, error) {
+	if tree == nil {
+		return nil, nil, nil, errors.NewDicelangError("No dice sets resulted from that command", errors.InvalidCommand, nil)
 	}
-	pbDiceSet := &pb.DiceSet{
-		Dice:          diceToPbDice(p, c, ds.Dice...),
-		TotalsByColor: ds.TotalsByColor,
-		Total:         int64(total),
-		ReString:      restring,
+	an := newAnalyzer(budget)
+	// describe adds the statistics and bounds requested to the DiceSet rolled from t
+	describe := func(ds *pb.DiceSet, t *dicelang.AST) {
+		if st {
+			s.addStatistics(ds, t, an)
+		}
+		if bo {
+			s.addBounds(ds, t, an)
+		}
+	}
+	// rollAll rolls every tree, adding the draws to transcript
+	var transcript []dicelang.Draw
+	rollAll := func(trees []*dicelang.AST) ([]*rolledTree, error) {
+		rolled := make([]*rolledTree, len(trees))
+		drawn, err := dicelang.EvalEach(len(trees), src, func(i int, src dicelang.RandomSource) (err error) {
+			rolled[i], err = rollTree(trees[i], dicelang.WithRandomSource(src), dicelang.WithBudget(budget))
+			return err
+		})
+		transcript = append(transcript, drawn...)
+		return rolled, err
 	}
 	if ro {
-		return pbDiceSet, []*pb.DiceSet{}, nil
+		rolled, err := rollAll([]*dicelang.AST{tree})
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		pbDiceSet, err := rolled[0].toPb(p, c, ex, tree, budget)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		describe(pbDiceSet, tree)
+		return pbDiceSet, []*pb.DiceSet{}, transcript, nil
 	}
 
-	var outDiceSets []*pb.DiceSet
-	for _, child := range tree.Children {
-		log.Debugf("child: %+v", child)
-		if child.Value == "REP" {
-			var sortabldDiceSets []*pb.DiceSet
-			reps, _, _ := child.Children[1].GetDiceSet()
-			for index := 0; index < int(reps); index++ {
-				total, ds, err := child.Children[0].GetDiceSet()
-				fTotal += total
-				if err != nil {
-					return nil, nil, err
-				}
-				restring, err := child.Children[0].String()
-				if err != nil {
-					return nil, nil, err
-				}
-				sortabldDiceSets = append(sortabldDiceSets,
-					&pb.DiceSet{
-						Dice:          diceToPbDice(p, c, ds.Dice...),
-						TotalsByColor: ds.TotalsByColor,
-						Total:         int64(total),
-						ReString:      restring,
-					})
+	// statements are rolled in the order they are written, as dicelang evaluates them, so a seed or transcript rolls the
+	// same dice here as anywhere else. The number of repetitions of a REP is rolled before its repetitions, to know how
+	// many there are. Every statement up to the next REP is rolled together.
+	// tree.Children[i] is rolled as stmts[first[i]:first[i+1]]
+	var stmts []*dicelang.AST
+	var rolled, counts []*rolledTree
+	first := make([]int, len(tree.Children)+1)
+	rollPending := func() error {
+		pending, err := rollAll(stmts[len(rolled):])
+		rolled = append(rolled, pending...)
+		return err
+	}
+	for i, child := range tree.Children {
+		first[i] = len(stmts)
+		if !isRep(child) {
+			stmts = append(stmts, child)
+			continue
+		}
+		if err := rollPending(); err != nil {
+			return nil, nil, nil, err
+		}
+		count, err := rollAll([]*dicelang.AST{child.Children[1]})
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		counts = append(counts, count[0])
+		for n := int(count[0].total); n > 0; n-- {
+			if len(stmts) == maxRolledStatements {
+				return nil, nil, nil, errors.NewDicelangError(fmt.Sprintf("That's too many rolls! I can only show %d at a time", maxRolledStatements), errors.Friendly, nil)
 			}
-			sort.Slice(sortabldDiceSets, func(i, j int) bool {
-				return sortabldDiceSets[i].Total < sortabldDiceSets[j].Total
-			})
-			outDiceSets = append(outDiceSets, sortabldDiceSets...)
-		} else {
-			total, ds, err := child.GetDiceSet()
-			fTotal += total
+			stmts = append(stmts, child.Children[0])
+		}
+	}
+	first[len(tree.Children)] = len(stmts)
+	if err := rollPending(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	root := &rolledTree{trace: dicelang.Trace{Sym: tree.Sym, Value: tree.Value}}
+	var outDiceSets []*pb.DiceSet
+	for i, child := range tree.Children {
+		var stmtDiceSets []*pb.DiceSet
+		for j := first[i]; j < first[i+1]; j++ {
+			pbChildDiceSet, err := rolled[j].toPb(p, c, ex, stmts[j], budget)
 			if err != nil {
-				return nil, nil, err
+				return nil, nil, nil, err
 			}
-			restring, err := child.String()
-			if err != nil {
-				return nil, nil, err
+			describe(pbChildDiceSet, stmts[j])
+			stmtDiceSets = append(stmtDiceSets, pbChildDiceSet)
+		}
+		if !isRep(child) {
+			root.add(rolled[first[i]])
+			if len(tree.Children) == 1 {
+				// a command of one list, like "[4d6dl1; 6]", is that list
+				root.values = rolled[first[i]].values
 			}
-			outDiceSets = append(outDiceSets,
-				&pb.DiceSet{
-					Dice:          diceToPbDice(p, c, ds.Dice...),
-					TotalsByColor: ds.TotalsByColor,
-					Total:         int64(total),
-					ReString:      restring,
-				})
+			outDiceSets = append(outDiceSets, stmtDiceSets...)
+			continue
+		}
+		// dicelang traces a REP as its number of repetitions followed by each repetition
+		count := counts[0]
+		counts = counts[1:]
+		rep := &rolledTree{dice: count.dice, trace: dicelang.Trace{Sym: child.Sym, Value: child.Value, Children: []*dicelang.Trace{&count.trace}}}
+		for _, r := range rolled[first[i]:first[i+1]] {
+			rep.add(r)
 		}
+		root.add(rep)
+		sort.Slice(stmtDiceSets, func(i, j int) bool {
+			return stmtDiceSets[i].Total < stmtDiceSets[j].Total
+		})
+		outDiceSets = append(outDiceSets, stmtDiceSets...)
+	}
+	pbDiceSet, err := root.toPb(p, c, ex, tree, budget)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	describe(pbDiceSet, tree)
+	return pbDiceSet, outDiceSets, transcript, nil
+}
+
+// isRep reports whether a statement is a REP, whose repetitions are rolled and shown one by one
+func isRep(stmt *dicelang.AST) bool {
+	return stmt.Value == "REP" && len(stmt.Children) > 1
+}
+
+// analyzer works out the distribution and bounds of each AST once, however many repetitions of it are rolled
+type analyzer struct {
+	budget *dicelang.Budget
+	trees  map[*dicelang.AST]analyzed
+}
+
+// analyzed is the distribution and bounds of an AST, or why they could not be worked out
+type analyzed struct {
+	dist        dicelang.Distribution
+	distErr     error
+	analysis    dicelang.Analysis
+	analysisErr error
+	// hasDist and hasAnalysis record which of the two have been worked out
+	hasDist, hasAnalysis bool
+}
+
+func newAnalyzer(budget *dicelang.Budget) *analyzer {
+	return &analyzer{budget: budget, trees: make(map[*dicelang.AST]analyzed)}
+}
+
+func (an *analyzer) distribution(tree *dicelang.AST) (dicelang.Distribution, error) {
+	a := an.trees[tree]
+	if !a.hasDist {
+		a.dist, a.distErr = tree.DistributionWithBudget(an.budget)
+		a.hasDist = true
+		an.trees[tree] = a
+	}
+	return a.dist, a.distErr
+}
+
+func (an *analyzer) analyze(tree *dicelang.AST) (dicelang.Analysis, error) {
+	a := an.trees[tree]
+	if !a.hasAnalysis {
+		a.analysis, a.analysisErr = dicelang.AnalyzeWithBudget(tree, an.budget)
+		a.hasAnalysis = true
+		an.trees[tree] = a
+	}
+	return a.analysis, a.analysisErr
+}
+
+// addBounds populates the bounds of a DiceSet from the AST that rolled it.
+// Rolls too complex to analyze within budget are left without bounds.
+func (s *server) addBounds(ds *pb.DiceSet, tree *dicelang.AST, an *analyzer) {
+	analysis, err := an.analyze(tree)
+	if err != nil {
+		s.env.log.Debugf("could not calculate bounds: %v", err)
+		return
+	}
+	ds.Bounds = boundsToPb(analysis.Bounds)
+	ds.BoundsByColor = make(map[string]*pb.Bounds)
+	for color, b := range analysis.ByColor {
+		ds.BoundsByColor[color] = boundsToPb(b)
+	}
+}
+
+// addStatistics populates the summary statistics of a DiceSet from the distribution of the AST that rolled it.
+// Rolls too complex to calculate within budget are left without statistics.
+func (s *server) addStatistics(ds *pb.DiceSet, tree *dicelang.AST, an *analyzer) {
+	dist, err := an.distribution(tree)
+	if err != nil {
+		s.env.log.Debugf("could not calculate statistics: %v", err)
+		return
+	}
+	stats := dist.Stats()
+	ds.Mean = stats.Mean
+	ds.StdDev = stats.StdDev
+	ds.Percentile5 = stats.Percentile5
+	ds.Percentile50 = stats.Percentile50
+	ds.Percentile95 = stats.Percentile95
+	ds.TopPercent = dist.AtLeast(float64(ds.Total)) * 100
+	ds.HasStatistics = true
+}
+
+// rolledTree is a tree rolled with a trace, or several rolled trees put together
+type rolledTree struct {
+	total float64
+	// values holds every value of a tree that is a list, like "[4d6dl1; 6]"
+	values        []float64
+	dice          []dicelang.Dice
+	totalsByColor map[string]float64
+	trace         dicelang.Trace
+}
+
+// rollTree evaluates a single tree, tracing it to render the result
+func rollTree(tree *dicelang.AST, opts ...dicelang.EvalOption) (*rolledTree, error) {
+	r := &rolledTree{}
+	opts = append(opts[:len(opts):len(opts)], dicelang.WithTrace(&r.trace))
+	values, ds, err := tree.GetList(opts...)
+	if err != nil {
+		return nil, err
+	}
+	for _, x := range values {
+		r.total += x
+	}
+	if tree.IsList() {
+		r.values = values
+	}
+	r.dice, r.totalsByColor = ds.Dice, ds.TotalsByColor
+	return r, nil
+}
+
+// add puts a rolled child tree together with r
+func (r *rolledTree) add(child *rolledTree) {
+	r.total += child.total
+	r.dice = append(r.dice, child.dice...)
+	r.totalsByColor = dicelang.MergeDiceTotalMaps(r.totalsByColor, child.totalsByColor)
+	r.trace.Children = append(r.trace.Children, &child.trace)
+	r.trace.Result = r.total
+}
+
+// toPb renders the roll of tree. If ex is set, the DiceSet explains how it was evaluated.
+func (r *rolledTree) toPb(p bool, c bool, ex bool, tree *dicelang.AST, budget *dicelang.Budget) (*pb.DiceSet, error) {
+	restring, err := tree.String()
+	if err != nil {
+		return nil, err
+	}
+	pbDiceSet := &pb.DiceSet{
+		Dice:          diceToPbDice(p, c, budget, r.dice...),
+		TotalsByColor: r.totalsByColor,
+		Total:         int64(r.total),
+		ReString:      restring,
+		Result:        resultToPb(r.trace.ResultTree()),
+		Values:        r.values,
 	}
-	pbDiceSet.Total = int64(fTotal)
-	return pbDiceSet, outDiceSets, nil
+	if ex {
+		pbDiceSet.Explanation = traceToPb(&r.trace)
+	}
+	return pbDiceSet, nil
+}
+
+func boundsToPb(b dicelang.Bounds) *pb.Bounds {
+	return &pb.Bounds{Min: b.Min, Max: b.Max, Mean: b.Mean}
+}
+
+func resultToPb(n *dicelang.ResultNode) *pb.ResultNode {
+	node := &pb.ResultNode{Text: n.Text}
+	switch n.Kind {
+	case dicelang.DiceResult:
+		node.Kind = pb.ResultNode_DICE
+		node.DiceIndex = int32(n.Dice)
+	case dicelang.OperatorResult:
+		node.Kind = pb.ResultNode_OPERATOR
+	default:
+		node.Kind = pb.ResultNode_TEXT
+	}
+	for _, child := range n.Children {
+		node.Children = append(node.Children, resultToPb(child))
+	}
+	return node
+}
+
+func traceToPb(tr *dicelang.Trace) *pb.TraceNode {
+	node := &pb.TraceNode{
+		Sym:    tr.Sym,
+		Value:  tr.Value,
+		Result: tr.Result,
+		Dice:   diceToPbDice(false, false, nil, tr.Dice...),
+		Text:   tr.String(),
+	}
+	for _, child := range tr.Children {
+		node.Children = append(node.Children, traceToPb(child))
+	}
+	return node
 }
 
-func diceToPbDice(p bool, c bool, dice ...dicelang.Dice) []*pb.Dice {
+// Dice too complex to calculate within budget are left without probabilities.
+func diceToPbDice(p bool, c bool, budget *dicelang.Budget, dice ...dicelang.Dice) []*pb.Dice {
 	var outDice []*pb.Dice
 	for _, d := range dice {
 		var dice pb.Dice
@@ -214,8 +473,11 @@ func diceToPbDice(p bool, c bool, dice ...dicelang.Dice) []*pb.Dice {
 		dice.Min = d.Min
 		dice.Sides = d.Sides
 		dice.Total = d.Total
+		dice.ExplodeDepth = d.ExplodeDepth
+		dice.SuccessTarget = d.SuccessTarget
+		dice.BotchTarget = d.BotchTarget
 		if p {
-			dice.Probabilities = dicelang.DiceProbability(dice.Count, dice.Sides, dice.DropHighest, dice.DropLowest)
+			dice.Probabilities, _ = d.ProbabilitiesWithBudget(budget)
 		}
 		if c {
 			dice.Chart = []byte{}
@@ -226,6 +488,43 @@ func diceToPbDice(p bool, c bool, dice ...dicelang.Dice) []*pb.Dice {
 	return outDice
 }
 
+// evaluationLimits returns the default limits, lowered by any limits in the request.
+func evaluationLimits(in *pb.EvaluationLimits) dicelang.Limits {
+	limits := dicelang.DefaultLimits
+	if in == nil {
+		return limits
+	}
+	if in.MaxDraws > 0 && in.MaxDraws < limits.MaxDraws {
+		limits.MaxDraws = in.MaxDraws
+	}
+	if in.MaxNodes > 0 && in.MaxNodes < limits.MaxNodes {
+		limits.MaxNodes = in.MaxNodes
+	}
+	if in.MaxDepth > 0 && int(in.MaxDepth) < limits.MaxDepth {
+		limits.MaxDepth = int(in.MaxDepth)
+	}
+	if timeout := time.Duration(in.TimeoutMs) * time.Millisecond; timeout > 0 && timeout < limits.Timeout {
+		limits.Timeout = timeout
+	}
+	return limits
+}
+
+func transcriptToPb(transcript []dicelang.Draw) []*pb.Draw {
+	var out []*pb.Draw
+	for _, d := range transcript {
+		out = append(out, &pb.Draw{N: d.N, Value: d.Value})
+	}
+	return out
+}
+
+func pbToTranscript(draws []*pb.Draw) []dicelang.Draw {
+	var out []dicelang.Draw
+	for _, d := range draws {
+		out = append(out, dicelang.Draw{N: d.N, Value: d.Value})
+	}
+	return out
+}
+
 func (s *server) Roll(ctx context.Context, in *pb.RollRequest) (*pb.RollResponse, error) {
 	log := s.env.log
 	ctx, span := trace.StartSpan(ctx, "Roll")
@@ -237,22 +536,168 @@ func (s *server) Roll(ctx context.Context, in *pb.RollRequest) (*pb.RollResponse
 	if in.Cmd == "" {
 		return &out, s.handleExposedErrors(errors.NewDicelangError("zero length command is invalid", errors.InvalidCommand, nil), &out)
 	}
-	p = dicelang.NewParser(in.Cmd)
+	p = dicelang.NewParser(in.Cmd, dicelang.WithKnownWords(in.KnownWords...))
 	log.Debugf("Rolling cmd on server: %s", in.Cmd)
 	tree, err := p.Statements()
+	if err != nil {
+		parseSpan.End()
+		return &out, s.handleExposedErrors(err, &out)
+	}
+	for _, d := range p.Diagnostics() {
+		out.Warnings = append(out.Warnings, diagnosticToPb(d))
+	}
+	if in.Simplify {
+		tree = dicelang.Simplify(tree)
+	}
 	parseSpan.End()
 
 	ctx, dsSpan := trace.StartSpan(ctx, "AST to Diceset")
 	defer dsSpan.End()
-	diceSet, diceSets, err := s.astToPbDiceSets(in.Probabilities, in.Chart, in.RootOnly, tree)
+	// share one source between every evaluation so a seed or transcript reproduces the whole response
+	var src dicelang.RandomSource = dicelang.NewCryptoSource()
+	var replay *dicelang.ReplaySource
+	if len(in.Replay) > 0 {
+		replay = dicelang.NewReplaySource(pbToTranscript(in.Replay))
+		src = replay
+	} else if in.Fair != nil {
+		src = dicelang.NewFairSource(in.Fair.ServerSeed, in.Fair.ClientSeed, in.Fair.Nonce)
+	} else if in.Seed != 0 {
+		src = dicelang.NewPCGSource(in.Seed)
+	}
+	budget := dicelang.NewBudget(ctx, evaluationLimits(in.Limits))
+	diceSet, diceSets, transcript, err := s.astToPbDiceSets(in.Probabilities, in.Chart, in.RootOnly, in.Statistics, in.Explain, in.Bounds, tree, src, budget)
 	if err != nil {
 		return &out, s.handleExposedErrors(err, &out)
 	}
+	if replay != nil {
+		if err := replay.Done(); err != nil {
+			return &out, s.handleExposedErrors(err, &out)
+		}
+	}
+	out.Transcript = transcriptToPb(transcript)
 	out.DiceSet = diceSet
 	for _, ds := range diceSets {
 		out.DiceSets = append(out.DiceSets, ds)
 	}
 	out.Cmd = in.Cmd
+	if s.signer != nil {
+		s.signer.sign(&out, time.Now())
+	}
 	log.Debugf("roll response from server: %+v", out)
 	return &out, nil
 }
+
+func (s *server) Verify(ctx context.Context, in *pb.VerifyRequest) (*pb.VerifyResponse, error) {
+	ctx, span := trace.StartSpan(ctx, "Verify")
+	defer span.End()
+	out := pb.VerifyResponse{Ok: true}
+	if s.signer == nil {
+		out.Ok = false
+		var err error
+		out.Error, err = s.exposedError(errors.NewDicelangError("This server does not sign rolls", errors.Friendly, nil))
+		return &out, err
+	}
+	if in.Roll != nil {
+		out.Valid = s.signer.verify(in.Roll)
+	}
+	return &out, nil
+}
+
+func (s *server) Compare(ctx context.Context, in *pb.CompareRequest) (*pb.CompareResponse, error) {
+	log := s.env.log
+	ctx, span := trace.StartSpan(ctx, "Compare")
+	defer span.End()
+	out := pb.CompareResponse{Ok: true, Cmd: in.Cmd}
+
+	cmdA, cmdB, err := dicelang.SplitComparison(in.Cmd)
+	if err != nil {
+		return &out, s.handleExposedCompareErrors(err, &out)
+	}
+	log.Debugf("Comparing on server: %s vs %s", cmdA, cmdB)
+	// both distributions share one budget, like every statement of a roll
+	budget := dicelang.NewBudget(ctx, dicelang.DefaultLimits)
+	distA, err := distributionFromCmd(cmdA, budget)
+	if err != nil {
+		return &out, s.handleExposedCompareErrors(err, &out)
+	}
+	distB, err := distributionFromCmd(cmdB, budget)
+	if err != nil {
+		return &out, s.handleExposedCompareErrors(err, &out)
+	}
+
+	comparison := distA.Compare(distB)
+	out.A = &pb.Expression{Cmd: cmdA, Mean: distA.Mean(), StdDev: distA.StdDev()}
+	out.B = &pb.Expression{Cmd: cmdB, Mean: distB.Mean(), StdDev: distB.StdDev()}
+	out.Greater = comparison.Greater * 100
+	out.Equal = comparison.Equal * 100
+	out.Less = comparison.Less * 100
+	for _, result := range dicelang.MergeOutcomes(distA, distB) {
+		out.Table = append(out.Table, &pb.CompareRow{Result: result, A: distA[result] * 100, B: distB[result] * 100})
+	}
+	if in.Chart {
+		out.Chart, err = compareChart(cmdA, distA, cmdB, distB)
+		if err != nil {
+			log.Errorf("could not render comparison chart: %v", err)
+		}
+	}
+	return &out, nil
+}
+
+func (s *server) handleExposedCompareErrors(e error, response *pb.CompareResponse) error {
+	response.Ok = false
+	var err error
+	response.Error, err = s.exposedError(e)
+	return err
+}
+
+func distributionFromCmd(cmd string, budget *dicelang.Budget) (dicelang.Distribution, error) {
+	tree, err := dicelang.NewParser(cmd).Statements()
+	if err != nil {
+		return nil, err
+	}
+	return tree.DistributionWithBudget(budget)
+}
+
+func (s *server) DamagePerRound(ctx context.Context, in *pb.DamageRequest) (*pb.DamageResponse, error) {
+	log := s.env.log
+	ctx, span := trace.StartSpan(ctx, "DamagePerRound")
+	defer span.End()
+	out := pb.DamageResponse{Ok: true}
+
+	if in.Damage == "" {
+		return &out, s.handleExposedDamageErrors(errors.NewDicelangError("zero length damage is invalid", errors.InvalidCommand, nil), &out)
+	}
+	log.Debugf("Calculating damage per round on server: %+v", in)
+	damage, err := dicelang.NewParser(in.Damage).Statements()
+	if err != nil {
+		return &out, s.handleExposedDamageErrors(err, &out)
+	}
+	attack := dicelang.NewAttack(in.AttackBonus, in.AC, damage)
+	if in.CritRange != 0 {
+		attack.CritRange = in.CritRange
+	}
+	if in.Attacks != 0 {
+		attack.Attacks = in.Attacks
+	}
+	dist, err := attack.DistributionWithBudget(dicelang.NewBudget(ctx, dicelang.DefaultLimits))
+	if err != nil {
+		return &out, s.handleExposedDamageErrors(err, &out)
+	}
+	hit, crit, miss := attack.Chances()
+	out.Mean = dist.Mean()
+	out.StdDev = dist.StdDev()
+	out.HitChance = hit * 100
+	out.CritChance = crit * 100
+	out.MissChance = miss * 100
+	for _, result := range dist.Outcomes() {
+		out.Distribution = append(out.Distribution, &pb.Outcome{Result: result, Probability: dist[result] * 100})
+	}
+	return &out, nil
+}
+
+func (s *server) handleExposedDamageErrors(e error, response *pb.DamageResponse) error {
+	response.Ok = false
+	var err error
+	response.Error, err = s.exposedError(e)
+	return err
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/aasmall/dicemagic/dicelang"
+	"github.com/aasmall/dicemagic/dicelang/errors"
+	log "github.com/aasmall/dicemagic/internal/logger"
+	pb "github.com/aasmall/dicemagic/internal/proto"
+	"golang.org/x/net/context"
+)
+
+func newTestServer() *server {
+	return newServer(&env{log: &log.Logger{}, config: &envConfig{receiptSecret: "test secret"}})
+}
+
+func TestRoll_DrawsInASTOrder(t *testing.T) {
+	for _, cmd := range []string{
+		"1d6, 1d4 rep 1d3",
+		"1d20 rep 2, 1d8, 2d6 rep 1d4, 1d12",
+	} {
+		t.Run(cmd, func(t *testing.T) {
+			tree, err := dicelang.NewParser(cmd).Statements()
+			if err != nil {
+				t.Fatalf("Statements() error = %v", err)
+			}
+			src := dicelang.NewRecordingSource(dicelang.NewPCGSource(7))
+			total, _, err := tree.GetDiceSet(dicelang.WithRandomSource(src))
+			if err != nil {
+				t.Fatalf("AST.GetDiceSet() error = %v", err)
+			}
+			out, err := newTestServer().Roll(context.Background(), &pb.RollRequest{Cmd: cmd, Seed: 7})
+			if err != nil || !out.Ok {
+				t.Fatalf("Roll() error = %v, %v", err, out.Error)
+			}
+			if out.DiceSet.Total != int64(total) {
+				t.Errorf("Roll() total = %d, want %v", out.DiceSet.Total, total)
+			}
+			want := src.Transcript()
+			if len(out.Transcript) != len(want) {
+				t.Fatalf("Roll() drew %d numbers, want %d", len(out.Transcript), len(want))
+			}
+			for i, d := range out.Transcript {
+				if d.N != want[i].N || d.Value != want[i].Value {
+					t.Errorf("Roll() draw %d = %v, want %+v", i, d, want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRoll_Statistics(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want bool
+	}{
+		{"1d20", true},
+		{"1d20 rep 3", true},
+		// too complex to calculate
+		{"1000d1000 * 1000d1000", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cmd, func(t *testing.T) {
+			out, err := newTestServer().Roll(context.Background(), &pb.RollRequest{Cmd: tt.cmd, Seed: 7, Statistics: true})
+			if err != nil || !out.Ok {
+				t.Fatalf("Roll() error = %v, %v", err, out.Error)
+			}
+			if out.DiceSet.HasStatistics != tt.want {
+				t.Errorf("Roll() HasStatistics = %v, want %v", out.DiceSet.HasStatistics, tt.want)
+			}
+			if !tt.want && out.DiceSet.Mean != 0 {
+				t.Errorf("Roll() Mean = %v without statistics, want 0", out.DiceSet.Mean)
+			}
+		})
+	}
+	out, err := newTestServer().Roll(context.Background(), &pb.RollRequest{Cmd: "1d20", Seed: 7})
+	if err != nil || out.DiceSet.HasStatistics {
+		t.Errorf("Roll() HasStatistics = %v, %v, want false when statistics aren't requested", out.DiceSet.HasStatistics, err)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	out, err := newTestServer().Compare(context.Background(), &pb.CompareRequest{Cmd: "compare 2d6 vs 1d12"})
+	if err != nil || !out.Ok {
+		t.Fatalf("Compare() error = %v, %v", err, out.Error)
+	}
+	if out.A.Cmd != "2d6" || out.B.Cmd != "1d12" || math.Abs(out.A.Mean-7) > 1e-9 || math.Abs(out.B.Mean-6.5) > 1e-9 {
+		t.Errorf("Compare() expressions = %+v, %+v, want 2d6 with a mean of 7 and 1d12 with 6.5", out.A, out.B)
+	}
+	if math.Abs(out.Greater-50) > 1e-9 || math.Abs(out.Equal-100.0/12) > 1e-9 || math.Abs(out.Less-500.0/12) > 1e-9 {
+		t.Errorf("Compare() = %v > %v = %v <, want 50%%, 8.33%% and 41.67%%", out.Greater, out.Equal, out.Less)
+	}
+	if len(out.Table) != 12 || out.Table[0].Result != 1 || out.Table[11].Result != 12 {
+		t.Fatalf("Compare() table = %v, want a row for each result from 1 to 12", out.Table)
+	}
+	if row := out.Table[0]; row.A != 0 || math.Abs(row.B-100.0/12) > 1e-9 {
+		t.Errorf("Compare() row 1 = %+v, want 0%% of 2d6 and 8.33%% of 1d12", row)
+	}
+}
+
+func TestCompare_Errors(t *testing.T) {
+	for _, cmd := range []string{"2d6", "2d6 vs", "2d6 vs (1d4", "1000d1000 vs 1000d1000"} {
+		out, err := newTestServer().Compare(context.Background(), &pb.CompareRequest{Cmd: cmd})
+		if err != nil {
+			t.Fatalf("Compare(%q) error = %v", cmd, err)
+		}
+		if out.Ok || out.Error.Code != errors.Friendly {
+			t.Errorf("Compare(%q) = %+v, want a Friendly error", cmd, out)
+		}
+	}
+}
+
+func TestDamagePerRound(t *testing.T) {
+	out, err := newTestServer().DamagePerRound(context.Background(), &pb.DamageRequest{AttackBonus: 5, AC: 15, Damage: "2", Attacks: 2})
+	if err != nil || !out.Ok {
+		t.Fatalf("DamagePerRound() error = %v, %v", err, out.Error)
+	}
+	//naturals 10 to 19 hit, 20 crits and the rest miss
+	if math.Abs(out.HitChance-50) > 1e-9 || math.Abs(out.CritChance-5) > 1e-9 || math.Abs(out.MissChance-45) > 1e-9 {
+		t.Errorf("DamagePerRound() chances = %v, %v, %v, want 50%%, 5%% and 45%%", out.HitChance, out.CritChance, out.MissChance)
+	}
+	if math.Abs(out.Mean-2.2) > 1e-9 {
+		t.Errorf("DamagePerRound() mean = %v, want 2.2", out.Mean)
+	}
+	want := []pb.Outcome{{Result: 0, Probability: 20.25}, {Result: 2, Probability: 49.5}, {Result: 4, Probability: 30.25}}
+	if len(out.Distribution) != len(want) {
+		t.Fatalf("DamagePerRound() distribution = %v, want %v", out.Distribution, want)
+	}
+	for i, o := range out.Distribution {
+		if o.Result != want[i].Result || math.Abs(o.Probability-want[i].Probability) > 1e-9 {
+			t.Errorf("DamagePerRound() outcome %d = %+v, want %+v", i, o, want[i])
+		}
+	}
+}
+
+func TestDamagePerRound_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   *pb.DamageRequest
+		code int32
+	}{
+		{"no damage", &pb.DamageRequest{AC: 15}, errors.InvalidCommand},
+		{"unparsed damage", &pb.DamageRequest{AC: 15, Damage: "(1d8"}, errors.Friendly},
+		{"crit range", &pb.DamageRequest{AC: 15, Damage: "1d8", CritRange: 21}, errors.Friendly},
+		{"too complex", &pb.DamageRequest{AC: 15, Damage: "1000d1000"}, errors.Friendly},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := newTestServer().DamagePerRound(context.Background(), tt.in)
+			if err != nil {
+				t.Fatalf("DamagePerRound() error = %v", err)
+			}
+			if out.Ok || out.Error.Code != tt.code {
+				t.Errorf("DamagePerRound() = %+v, want an error with code %d", out, tt.code)
+			}
+		})
+	}
+}
+
+func TestEvaluationLimits(t *testing.T) {
+	tests := []struct {
+		name string
+		in   *pb.EvaluationLimits
+		want dicelang.Limits
+	}{
+		{"none", nil, dicelang.DefaultLimits},
+		{"zero", &pb.EvaluationLimits{}, dicelang.DefaultLimits},
+		{"lowered", &pb.EvaluationLimits{MaxDraws: 10, MaxNodes: 20, MaxDepth: 5, TimeoutMs: 100},
+			dicelang.Limits{MaxDraws: 10, MaxNodes: 20, MaxDepth: 5, Timeout: 100 * time.Millisecond}},
+		{"raised", &pb.EvaluationLimits{MaxDraws: 1 << 40, MaxNodes: 1 << 40, MaxDepth: 1 << 20, TimeoutMs: 1 << 40}, dicelang.DefaultLimits},
+		{"negative", &pb.EvaluationLimits{MaxDraws: -1, MaxNodes: -1, MaxDepth: -1, TimeoutMs: -1}, dicelang.DefaultLimits},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := evaluationLimits(tt.in); got != tt.want {
+				t.Errorf("evaluationLimits() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	pb "github.com/aasmall/dicemagic/internal/proto"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// verifyCommand asks a running dice-server whether a JSON encoded RollResponse has a valid receipt.
+// It returns the process exit code.
+func verifyCommand(args []string) int {
+	flags := flag.NewFlagSet("verify", flag.ContinueOnError)
+	addr := flags.String("addr", "localhost:50051", "Address of the dice-server that signed the roll")
+	path := flags.String("receipt", "", "Path to a JSON encoded RollResponse. Reads stdin when empty")
+	timeout := flags.Duration("timeout", 5*time.Second, "How long to wait for dice-server")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	var data []byte
+	var err error
+	if *path == "" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(*path)
+	}
+	if err != nil {
+		fmt.Printf("Could not read receipt: %v\n", err)
+		return 2
+	}
+	var roll pb.RollResponse
+	if err := json.Unmarshal(data, &roll); err != nil {
+		fmt.Printf("Could not decode receipt: %v\n", err)
+		return 2
+	}
+
+	conn, err := grpc.Dial(*addr, grpc.WithInsecure())
+	if err != nil {
+		fmt.Printf("Could not connect to dice-server: %v\n", err)
+		return 2
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	res, err := pb.NewRollerClient(conn).Verify(ctx, &pb.VerifyRequest{Roll: &roll})
+	if err != nil {
+		fmt.Printf("Could not verify receipt: %v\n", err)
+		return 2
+	}
+	if !res.Ok {
+		fmt.Printf("Could not verify receipt: %s\n", res.Error.Msg)
+		return 2
+	}
+	if !res.Valid {
+		fmt.Printf("INVALID: %q was not rolled by this server, or was changed after it was rolled\n", roll.Cmd)
+		return 1
+	}
+	fmt.Printf("Valid: %q rolled %d at %s\n", roll.Cmd, roll.DiceSet.GetTotal(), time.Unix(0, roll.Receipt.Timestamp).UTC().Format(time.RFC3339))
+	return 0
+}
@@ -0,0 +1,380 @@
+package dicelang
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/aasmall/dicemagic/dicelang/errors"
+)
+
+//Bounds are the smallest, largest and mean result of an expression
+type Bounds struct {
+	Min  float64
+	Max  float64
+	Mean float64
+}
+
+//Analysis bounds every result of an AST
+type Analysis struct {
+	Bounds
+	//ByColor bounds each total in DiceSet.TotalsByColor
+	ByColor map[string]Bounds
+}
+
+func pointBounds(x float64) Bounds {
+	return Bounds{Min: x, Max: x, Mean: x}
+}
+
+func (b Bounds) constant() bool {
+	return b.Min == b.Max
+}
+
+//Analyze returns the bounds of an AST without rolling any dice. Unlike Distribution, it scales to any number of dice.
+//Mean falls back to the distribution for conditions, division by dice, exponents of dice and drops from a variable
+//number of dice, so it fails when those are too complex. An expression with more than one color is counted as its last color.
+//Lists are not supported, and return an "Unsupported symbol" error.
+func Analyze(t *AST) (Analysis, error) {
+	return AnalyzeWithBudget(t, nil)
+}
+
+//AnalyzeWithBudget is Analyze, which gives up with a Friendly error once b is out of time.
+//Only the fallbacks to the distribution spend b.
+func AnalyzeWithBudget(t *AST, budget *Budget) (Analysis, error) {
+	a := Analysis{ByColor: make(map[string]Bounds)}
+	b, err := t.analyze(true, a.ByColor, budget.orUnlimited())
+	if err != nil {
+		return Analysis{}, err
+	}
+	a.Bounds = b
+	return a, nil
+}
+
+//analyze returns the bounds of t. Like AST.eval, only nodes at the top of a statement count towards the total of their color.
+func (t *AST) analyze(top bool, colors map[string]Bounds, budget *Budget) (Bounds, error) {
+	switch strings.ToUpper(t.Sym) {
+	case "(NUMBER)":
+		x, _ := strconv.ParseFloat(t.Value, 64)
+		return pointBounds(x), nil
+	case "-H", "-L", "(IDENT)":
+		return pointBounds(0), nil
+	case "D":
+		b, err := t.analyzeDice(budget)
+		if err != nil {
+			return Bounds{}, err
+		}
+		if top {
+			addBounds(colors, colorOf(t), b)
+		}
+		return b, nil
+	case "+", "-", "*", "/", "^":
+		b, err := t.analyzeArithmetic(budget)
+		if err != nil {
+			return Bounds{}, err
+		}
+		if top {
+			addBounds(colors, colorOf(t), b)
+		}
+		return b, nil
+	case "%":
+		if len(t.Children) < 1 {
+			return Bounds{}, errors.NewDicelangError("Invalid percentage", errors.InvalidAST, nil)
+		}
+		b, err := t.Children[0].analyze(false, colors, budget)
+		if err != nil {
+			return Bounds{}, err
+		}
+		return Bounds{Min: b.Min / 100, Max: b.Max / 100, Mean: b.Mean / 100}, nil
+	case "DC", "NEEDED", "DPR":
+		d, err := t.distribution(budget)
+		if err != nil {
+			return Bounds{}, err
+		}
+		return pointBounds(d.Mean()), nil
+	case "{", "ROLL", "(ROOTNODE)":
+		sum := pointBounds(0)
+		for _, c := range t.Children {
+			b, err := c.analyze(top, colors, budget)
+			if err != nil {
+				return Bounds{}, err
+			}
+			sum = Bounds{Min: sum.Min + b.Min, Max: sum.Max + b.Max, Mean: sum.Mean + b.Mean}
+		}
+		return sum, nil
+	case "REP":
+		if len(t.Children) < 2 {
+			return Bounds{}, errors.NewDicelangError("Invalid repetition", errors.InvalidAST, nil)
+		}
+		reps, err := t.Children[1].analyze(top, colors, budget)
+		if err != nil {
+			return Bounds{}, err
+		}
+		//like AST.eval, repetitions are truncated, and fewer than one never evaluates
+		reps = Bounds{Min: math.Max(0, math.Trunc(reps.Min)), Max: math.Max(0, math.Trunc(reps.Max)), Mean: math.Max(0, reps.Mean)}
+		repColors := make(map[string]Bounds)
+		b, err := t.Children[0].analyze(top, repColors, budget)
+		if err != nil {
+			return Bounds{}, err
+		}
+		for color, cb := range repColors {
+			addBounds(colors, color, scaleBounds(cb, reps))
+		}
+		return scaleBounds(b, reps), nil
+	case "IF":
+		return t.analyzeIf(top, colors, budget)
+	default:
+		return Bounds{}, errors.Newf("Unsupported symbol: %s", t.Sym)
+	}
+}
+
+func (t *AST) analyzeDice(budget *Budget) (Bounds, error) {
+	var operands []Bounds
+	H, L := pointBounds(0), pointBounds(0)
+	for _, c := range t.Children {
+		var b Bounds
+		var err error
+		switch c.Sym {
+		case "(IDENT)":
+			continue
+		case "-H", "-L":
+			if len(c.Children) < 1 {
+				return Bounds{}, errors.NewDicelangError("Invalid drop", errors.InvalidAST, nil)
+			}
+			if c.Sym == "-H" {
+				H, err = c.Children[0].analyze(false, nil, budget)
+			} else {
+				L, err = c.Children[0].analyze(false, nil, budget)
+			}
+		default:
+			b, err = c.analyze(false, nil, budget)
+			operands = append(operands, b)
+		}
+		if err != nil {
+			return Bounds{}, err
+		}
+	}
+	if len(operands) < 2 {
+		return Bounds{}, errors.NewDicelangError("Invalid dice", errors.InvalidAST, nil)
+	}
+	count, sides := operands[0], operands[1]
+	dropped := droppedBounds(H, L)
+	b := Bounds{
+		Min: math.Max(0, math.Trunc(count.Min)-dropped.Max),
+		Max: math.Max(0, math.Trunc(count.Max)-dropped.Min) * math.Trunc(sides.Max),
+	}
+	switch {
+	case H.Max == 0 && L.Max == 0:
+		//every die is kept, so the mean is linear in the count and the sides
+		b.Mean = count.Mean * (sides.Mean + 1) / 2
+	case count.constant() && sides.constant() && H.constant() && L.constant():
+		if err := budget.spendWork(diceCost(int64(count.Min), int64(sides.Min), int64(H.Min), int64(L.Min))); err != nil {
+			return Bounds{}, err
+		}
+		d, err := DiceDistribution(int64(count.Min), int64(sides.Min), int64(H.Min), int64(L.Min))
+		if err != nil {
+			return Bounds{}, err
+		}
+		b.Mean = d.Mean()
+	default:
+		d, err := t.diceDistribution(budget)
+		if err != nil {
+			return Bounds{}, err
+		}
+		b.Mean = d.Mean()
+	}
+	return b, nil
+}
+
+//droppedBounds bounds how many dice are dropped by -H and -L, only one of which applies, like droppedDice
+func droppedBounds(H Bounds, L Bounds) Bounds {
+	switch {
+	case math.Trunc(H.Min) > 0:
+		return Bounds{Min: math.Trunc(H.Min), Max: math.Trunc(H.Max)}
+	case math.Trunc(H.Max) <= 0:
+		return Bounds{Min: math.Max(0, math.Trunc(L.Min)), Max: math.Max(0, math.Trunc(L.Max))}
+	}
+	return Bounds{Min: 0, Max: math.Max(math.Trunc(H.Max), math.Max(0, math.Trunc(L.Max)))}
+}
+
+func (t *AST) analyzeArithmetic(budget *Budget) (Bounds, error) {
+	var operands []Bounds
+	for _, c := range t.Children {
+		if c.Sym == "(IDENT)" {
+			continue
+		}
+		b, err := c.analyze(false, nil, budget)
+		if err != nil {
+			return Bounds{}, err
+		}
+		operands = append(operands, b)
+	}
+	if len(operands) == 0 {
+		return Bounds{}, errors.NewDicelangError("Invalid arithmetic", errors.InvalidAST, nil)
+	}
+	if t.Sym == "-" && len(operands) == 1 {
+		x := operands[0]
+		return Bounds{Min: -x.Max, Max: -x.Min, Mean: -x.Mean}, nil
+	}
+	out := operands[0]
+	for _, y := range operands[1:] {
+		x := out
+		switch t.Sym {
+		case "+":
+			out = Bounds{Min: x.Min + y.Min, Max: x.Max + y.Max, Mean: x.Mean + y.Mean}
+		case "-":
+			out = Bounds{Min: x.Min - y.Max, Max: x.Max - y.Min, Mean: x.Mean - y.Mean}
+		case "*":
+			//operands are rolled independently, so the mean of a product is the product of the means
+			out = extremes([]float64{x.Min, x.Max}, []float64{y.Min, y.Max}, func(x, y float64) float64 { return x * y })
+			out.Mean = x.Mean * y.Mean
+		case "/":
+			if y.Min <= 0 && y.Max >= 0 && !y.constant() {
+				out = Bounds{Min: math.Inf(-1), Max: math.Inf(1)}
+			} else {
+				out = extremes([]float64{x.Min, x.Max}, []float64{y.Min, y.Max}, func(x, y float64) float64 { return x / y })
+			}
+		case "^":
+			bases := []float64{x.Min, x.Max}
+			if x.Min < 0 && x.Max > 0 {
+				bases = append(bases, 0)
+			}
+			powers := []float64{y.Min, y.Max}
+			if x.Min < 0 {
+				//a negative base alternates sign, so the extremes may be one short of the largest or smallest power
+				powers = append(powers, math.Min(y.Min+1, y.Max), math.Max(y.Max-1, y.Min))
+			}
+			out = extremes(bases, powers, math.Pow)
+		}
+	}
+	if t.Sym == "/" || t.Sym == "^" {
+		mean, err := t.arithmeticMean(operands, budget)
+		if err != nil {
+			return Bounds{}, err
+		}
+		out.Mean = mean
+	}
+	return out, nil
+}
+
+//arithmeticMean returns the mean of a division or exponent, which is only linear when dividing by a constant
+func (t *AST) arithmeticMean(operands []Bounds, budget *Budget) (float64, error) {
+	mean := operands[0].Mean
+	constant := true
+	for _, y := range operands[1:] {
+		if !y.constant() || (t.Sym == "^" && !operands[0].constant()) {
+			constant = false
+			break
+		}
+		if t.Sym == "/" {
+			mean /= y.Min
+		} else {
+			mean = math.Pow(mean, y.Min)
+		}
+	}
+	if constant {
+		return mean, nil
+	}
+	d, err := t.distribution(budget)
+	if err != nil {
+		return 0, err
+	}
+	return d.Mean(), nil
+}
+
+func (t *AST) analyzeIf(top bool, colors map[string]Bounds, budget *Budget) (Bounds, error) {
+	if len(t.Children) < 2 || len(t.Children[0].Children) < 2 {
+		return Bounds{}, errors.NewDicelangError("Invalid if", errors.InvalidAST, nil)
+	}
+	//the condition is evaluated where the if is, so it may count towards a color too
+	for _, c := range t.Children[0].Children[:2] {
+		if _, err := c.analyze(top, colors, budget); err != nil {
+			return Bounds{}, err
+		}
+	}
+	p, err := t.Children[0].probabilityTrue(budget)
+	if err != nil {
+		return Bounds{}, err
+	}
+	yesColors, noColors := make(map[string]Bounds), make(map[string]Bounds)
+	yes, err := t.Children[1].analyze(top, yesColors, budget)
+	if err != nil {
+		return Bounds{}, err
+	}
+	no := pointBounds(0)
+	if len(t.Children) > 2 {
+		no, err = t.Children[2].analyze(top, noColors, budget)
+		if err != nil {
+			return Bounds{}, err
+		}
+	}
+	for color := range yesColors {
+		if _, ok := noColors[color]; !ok {
+			noColors[color] = pointBounds(0)
+		}
+	}
+	for color, n := range noColors {
+		y, ok := yesColors[color]
+		if !ok {
+			y = pointBounds(0)
+		}
+		addBounds(colors, color, eitherBounds(y, n, p))
+	}
+	return eitherBounds(yes, no, p), nil
+}
+
+//eitherBounds bounds a result that is yes with probability p, and no otherwise
+func eitherBounds(yes Bounds, no Bounds, p float64) Bounds {
+	switch p {
+	case 1:
+		return yes
+	case 0:
+		return no
+	}
+	return Bounds{Min: math.Min(yes.Min, no.Min), Max: math.Max(yes.Max, no.Max), Mean: p*yes.Mean + (1-p)*no.Mean}
+}
+
+//scaleBounds bounds the sum of a number of independent results, each within b
+func scaleBounds(b Bounds, n Bounds) Bounds {
+	return Bounds{
+		Min:  math.Min(n.Min*b.Min, n.Max*b.Min),
+		Max:  math.Max(n.Min*b.Max, n.Max*b.Max),
+		Mean: n.Mean * b.Mean,
+	}
+}
+
+//extremes returns the smallest and largest op(x, y) for every x in xs and y in ys
+func extremes(xs []float64, ys []float64, op func(x, y float64) float64) Bounds {
+	b := Bounds{Min: math.Inf(1), Max: math.Inf(-1)}
+	for _, x := range xs {
+		for _, y := range ys {
+			z := op(x, y)
+			if math.IsNaN(z) {
+				continue
+			}
+			b.Min = math.Min(b.Min, z)
+			b.Max = math.Max(b.Max, z)
+		}
+	}
+	return b
+}
+
+func addBounds(colors map[string]Bounds, color string, b Bounds) {
+	if colors == nil {
+		return
+	}
+	sum := colors[color]
+	colors[color] = Bounds{Min: sum.Min + b.Min, Max: sum.Max + b.Max, Mean: sum.Mean + b.Mean}
+}
+
+//colorOf returns the last color in t, which is the color AST.eval gives it
+func colorOf(t *AST) string {
+	color := ""
+	for _, c := range t.Children {
+		if c.Sym == "(IDENT)" {
+			color = c.Value
+		} else if inner := colorOf(c); inner != "" {
+			color = inner
+		}
+	}
+	return color
+}
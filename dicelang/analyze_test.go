@@ -0,0 +1,119 @@
+package dicelang
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name    string
+		cmd     string
+		want    Bounds
+		byColor map[string]Bounds
+	}{
+		{"dice", "1d20", Bounds{1, 20, 10.5}, map[string]Bounds{"": {1, 20, 10.5}}},
+		{"arithmetic", "(2d6+3)*2", Bounds{10, 30, 20}, map[string]Bounds{"": {10, 30, 20}}},
+		{"subtraction", "1d6 - 1d4", Bounds{-3, 5, 1}, map[string]Bounds{"": {-3, 5, 1}}},
+		{"drops", "4d6-L1", Bounds{3, 18, 12.244598765432098}, map[string]Bounds{"": {3, 18, 12.244598765432098}}},
+		{"too many dice to enumerate", "1000d1000 * 1000d1000", Bounds{1e6, 1e12, 500500 * 500500}, map[string]Bounds{"": {1e6, 1e12, 500500 * 500500}}},
+		{"rep", "1d20+3 rep 3", Bounds{12, 69, 40.5}, map[string]Bounds{"": {12, 69, 40.5}}},
+		{"colors", "roll 1d4 fire and 1d8+2 ice, 3", Bounds{7, 17, 12}, map[string]Bounds{"Fire": {1, 4, 2.5}, "Ice": {3, 10, 6.5}}},
+		{"division", "10/1d2", Bounds{5, 10, 7.5}, map[string]Bounds{"": {5, 10, 7.5}}},
+		{"negative exponent base", "(-(1d4))^(1d3)", Bounds{-64, 16, -20.0 / 3}, map[string]Bounds{"": {-64, 16, -20.0 / 3}}},
+		{"percent", "50% * 1d10", Bounds{0.5, 5, 2.75}, map[string]Bounds{"": {0.5, 5, 2.75}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Analyze(NewParser(tt.cmd).testStatements())
+			if err != nil {
+				t.Fatalf("Analyze() error = %v", err)
+			}
+			if !equalBounds(got.Bounds, tt.want) {
+				t.Errorf("Analyze() = %+v, want %+v", got.Bounds, tt.want)
+			}
+			if len(got.ByColor) != len(tt.byColor) {
+				t.Errorf("Analyze().ByColor = %+v, want %+v", got.ByColor, tt.byColor)
+			}
+			for color, want := range tt.byColor {
+				if !equalBounds(got.ByColor[color], want) {
+					t.Errorf("Analyze().ByColor[%q] = %+v, want %+v", color, got.ByColor[color], want)
+				}
+			}
+		})
+	}
+}
+
+func TestAnalyze_MatchesDistribution(t *testing.T) {
+	for _, cmd := range []string{"3d6-H1 + 1d4", "1d6 rep 1d4", "(1d4 + 1) * 1d6 - 2", "1d20 + 5 if 1d20 > 10 else 1d4", "2d4 ^ 2"} {
+		root := NewParser(cmd).testStatements()
+		a, err := Analyze(root)
+		if err != nil {
+			t.Fatalf("Analyze(%q) error = %v", cmd, err)
+		}
+		d, err := root.Distribution()
+		if err != nil {
+			t.Fatalf("Distribution(%q) error = %v", cmd, err)
+		}
+		outcomes := d.Outcomes()
+		want := Bounds{Min: outcomes[0], Max: outcomes[len(outcomes)-1], Mean: d.Mean()}
+		if !equalBounds(a.Bounds, want) {
+			t.Errorf("Analyze(%q) = %+v, want %+v", cmd, a.Bounds, want)
+		}
+	}
+}
+
+func TestAnalyze_Malformed(t *testing.T) {
+	one := &AST{Sym: "(NUMBER)", Value: "1"}
+	for _, root := range []*AST{
+		{Sym: "%", Value: "%"},
+		{Sym: "REP", Value: "REP", Children: []*AST{one}},
+		{Sym: "IF", Value: "IF"},
+		{Sym: "D", Value: "d", Children: []*AST{one, one, {Sym: "-L", Value: "-L"}}},
+	} {
+		if _, err := Analyze(root); err == nil {
+			t.Errorf("Analyze(%s) error = nil, want an error", astString(root))
+		}
+	}
+}
+
+func TestAnalyze_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		root := randomRoot(r)
+		//drops from a variable number of dice fall back to distributions, which are too slow to check hundreds of
+		if hasDrops(root) {
+			continue
+		}
+		a, err := Analyze(root)
+		if err != nil {
+			continue
+		}
+		for j := 0; j < 5; j++ {
+			total, _, err := root.GetDiceSet(WithSeed(uint64(i*5 + j + 1)))
+			if err != nil {
+				break
+			}
+			if total < a.Min-1e-9*math.Abs(a.Min) || total > a.Max+1e-9*math.Abs(a.Max) {
+				t.Fatalf("GetDiceSet() = %v, outside %+v for AST %s", total, a.Bounds, astString(root))
+			}
+		}
+	}
+}
+
+func equalBounds(a Bounds, b Bounds) bool {
+	return floatEquals(a.Min, b.Min) && floatEquals(a.Max, b.Max) && floatEquals(a.Mean, b.Mean)
+}
+
+func hasDrops(t *AST) bool {
+	if t.Sym == "-L" || t.Sym == "-H" {
+		return true
+	}
+	for _, c := range t.Children {
+		if hasDrops(c) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package dicelang
+
+import (
+	"fmt"
+
+	"github.com/aasmall/dicemagic/dicelang/errors"
+)
+
+//maxAttacks caps the number of attacks in a single round
+const maxAttacks = 50
+
+//Attack describes a round of identical attacks against a single target.
+//Every attack rolls 1d20+Bonus against AC. A natural 1 always misses and a natural roll of CritRange or higher always hits
+//and doubles the dice of Damage.
+type Attack struct {
+	Bonus     int64
+	AC        int64
+	CritRange int64
+	Damage    *AST
+	Attacks   int64
+}
+
+//NewAttack returns an Attack that crits on a natural 20 and attacks once
+func NewAttack(bonus int64, ac int64, damage *AST) *Attack {
+	return &Attack{Bonus: bonus, AC: ac, CritRange: 20, Damage: damage, Attacks: 1}
+}
+
+func (a *Attack) validate() error {
+	if a.Damage == nil {
+		return errors.NewDicelangError("An attack needs a damage roll", errors.Friendly, nil)
+	}
+	if a.CritRange < 2 || a.CritRange > 20 {
+		return errors.NewDicelangError(fmt.Sprintf("Crit range must be between 2 and 20, found %d", a.CritRange), errors.Friendly, nil)
+	}
+	if a.Attacks < 1 || a.Attacks > maxAttacks {
+		return errors.NewDicelangError(fmt.Sprintf("Number of attacks must be between 1 and %d, found %d", maxAttacks, a.Attacks), errors.Friendly, nil)
+	}
+	return nil
+}
+
+//Chances returns the probability (0-1) that a single attack hits without a crit, crits, or misses
+func (a *Attack) Chances() (hit float64, crit float64, miss float64) {
+	for natural := int64(1); natural <= 20; natural++ {
+		switch {
+		case natural == 1:
+			miss++
+		case natural >= a.CritRange:
+			crit++
+		case natural+a.Bonus >= a.AC:
+			hit++
+		default:
+			miss++
+		}
+	}
+	return hit / 20, crit / 20, miss / 20
+}
+
+//Distribution returns the distribution of the total damage dealt by all attacks in the round
+func (a *Attack) Distribution() (Distribution, error) {
+	return a.DistributionWithBudget(nil)
+}
+
+//DistributionWithBudget is Distribution, which gives up with a Friendly error once b is out of time
+func (a *Attack) DistributionWithBudget(b *Budget) (Distribution, error) {
+	return a.distribution(b.orUnlimited())
+}
+
+func (a *Attack) distribution(budget *Budget) (Distribution, error) {
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+	damage, err := a.Damage.distribution(budget)
+	if err != nil {
+		return nil, err
+	}
+	critDamage, err := a.Damage.critical().distribution(budget)
+	if err != nil {
+		return nil, err
+	}
+	hit, crit, miss := a.Chances()
+	single, err := mixture(budget, Distribution{0: miss, 1: hit, 2: crit}, func(outcome float64) (Distribution, error) {
+		switch outcome {
+		case 1:
+			return damage, nil
+		case 2:
+			return critDamage, nil
+		}
+		return pointDistribution(0), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return repeat(budget, single, int(a.Attacks))
+}
+
+//critical returns a copy of the AST with the number of every die doubled
+func (t *AST) critical() *AST {
+	c := *t
+	c.Children = make([]*AST, len(t.Children))
+	for i, child := range t.Children {
+		c.Children[i] = child.critical()
+	}
+	if c.Sym == "D" && len(c.Children) > 0 {
+		two := &AST{Sym: "(NUMBER)", Value: "2"}
+		c.Children[0] = &AST{Sym: "*", Value: "*", Children: []*AST{c.Children[0], two}}
+	}
+	return &c
+}
+
+//attack builds the Attack described by DPR(bonus, ac, damage[, crit range[, attacks]])
+func (t *AST) attack(ds *DiceSet) (*Attack, error) {
+	var args []float64
+	for i, c := range t.Children {
+		if i == 2 || c.Sym == "(IDENT)" {
+			continue
+		}
+		x, _, err := c.eval(ds)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, x)
+	}
+	if len(args) < 2 || len(t.Children) < 3 {
+		return nil, errors.NewDicelangError("Invalid dpr", errors.InvalidAST, nil)
+	}
+	a := NewAttack(int64(args[0]), int64(args[1]), t.Children[2])
+	if len(args) > 2 {
+		a.CritRange = int64(args[2])
+	}
+	if len(args) > 3 {
+		a.Attacks = int64(args[3])
+	}
+	return a, nil
+}
@@ -0,0 +1,71 @@
+package dicelang
+
+import (
+	"testing"
+)
+
+func TestAttack_Distribution(t *testing.T) {
+	tests := []struct {
+		name     string
+		attack   *Attack
+		wantMean float64
+		wantErr  bool
+	}{
+		{
+			name:     "single attack",
+			attack:   NewAttack(5, 15, NewParser("1d8+3").testStatements()),
+			wantMean: 0.5*7.5 + 0.05*12},
+		{
+			name:     "extended crit range",
+			attack:   &Attack{Bonus: 5, AC: 15, CritRange: 19, Damage: NewParser("1d8+3").testStatements(), Attacks: 1},
+			wantMean: 0.45*7.5 + 0.1*12},
+		{
+			name:     "two attacks",
+			attack:   &Attack{Bonus: 5, AC: 15, CritRange: 20, Damage: NewParser("1d8+3").testStatements(), Attacks: 2},
+			wantMean: 2 * (0.5*7.5 + 0.05*12)},
+		{
+			name:     "only crits hit",
+			attack:   NewAttack(0, 30, NewParser("2d6").testStatements()),
+			wantMean: 0.05 * 14},
+		{
+			name:     "natural one misses",
+			attack:   NewAttack(30, 10, NewParser("4").testStatements()),
+			wantMean: 0.9*4 + 0.05*4},
+		{
+			name:    "bad crit range",
+			attack:  &Attack{Bonus: 5, AC: 15, CritRange: 1, Damage: NewParser("1d8").testStatements(), Attacks: 1},
+			wantErr: true},
+		{
+			name:    "no attacks",
+			attack:  &Attack{Bonus: 5, AC: 15, CritRange: 20, Damage: NewParser("1d8").testStatements(), Attacks: 0},
+			wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := tt.attack.Distribution()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Attack.Distribution() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := d.Mean(); !floatEquals(got, tt.wantMean) {
+				t.Errorf("Attack.Distribution().Mean() = %v, want %v", got, tt.wantMean)
+			}
+		})
+	}
+}
+
+func TestAST_DPR(t *testing.T) {
+	root := NewParser("dpr(5, 15, 1d8+3, 20, 2)").testStatements()
+	got, _, err := root.GetDiceSet()
+	if err != nil {
+		t.Fatalf("AST.GetDiceSet() error = %v", err)
+	}
+	if want := 2 * (0.5*7.5 + 0.05*12); !floatEquals(got, want) {
+		t.Errorf("AST.GetDiceSet() = %v, want %v", got, want)
+	}
+	if s, _ := root.String(); s != "dpr(5, 15, (1d8 + 3), 20, 2)" {
+		t.Errorf("AST.String() = %v", s)
+	}
+}
@@ -0,0 +1,115 @@
+package dicelang
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/aasmall/dicemagic/dicelang/errors"
+)
+
+//Limits bounds the work done evaluating an AST. Zero values are unlimited.
+type Limits struct {
+	//MaxDraws is the most dice that may be rolled
+	MaxDraws int64
+	//MaxNodes is the most AST nodes that may be evaluated. REP counts its children once per repetition.
+	MaxNodes int64
+	//MaxDepth is the deepest the evaluator may recurse
+	MaxDepth int
+	//Timeout is the longest evaluation may take
+	Timeout time.Duration
+}
+
+//DefaultLimits are generous for any roll a person would make, and keep a single roll from hogging a server
+var DefaultLimits = Limits{
+	MaxDraws: 100000,
+	MaxNodes: 100000,
+	MaxDepth: 100,
+	Timeout:  2 * time.Second,
+}
+
+//Budget tracks the work done against Limits. A Budget can be shared by several evaluations,
+//such as every statement in a single request, including evaluations running at the same time.
+type Budget struct {
+	limits   Limits
+	ctx      context.Context
+	deadline time.Time
+	//draws, nodes and work are only accessed atomically
+	draws int64
+	nodes int64
+	work  int64
+}
+
+//NewBudget returns a Budget which also stops evaluation when ctx is done
+func NewBudget(ctx context.Context, limits Limits) *Budget {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	b := &Budget{limits: limits, ctx: ctx}
+	if limits.Timeout > 0 {
+		b.deadline = time.Now().Add(limits.Timeout)
+	}
+	return b
+}
+
+//WithBudget stops evaluation with a Friendly error once b is spent
+func WithBudget(b *Budget) EvalOption {
+	return func(ds *DiceSet) {
+		ds.budget = b
+	}
+}
+
+//enter is called before evaluating each node, depth nodes deep in its own evaluation
+func (b *Budget) enter(depth int) error {
+	nodes := atomic.AddInt64(&b.nodes, 1)
+	if b.limits.MaxNodes > 0 && nodes > b.limits.MaxNodes {
+		return errors.NewDicelangError("That roll is too complicated for me to work out", errors.Friendly, nil)
+	}
+	if b.limits.MaxDepth > 0 && depth > b.limits.MaxDepth {
+		return errors.NewDicelangError("That roll is nested too deeply for me to work out", errors.Friendly, nil)
+	}
+	return b.checkTime()
+}
+
+//checkTime returns a Friendly error once the context is done or the timeout has passed
+func (b *Budget) checkTime() error {
+	if b.ctx.Err() != nil || (!b.deadline.IsZero() && time.Now().After(b.deadline)) {
+		return errors.NewDicelangError("That roll is taking too long, so I gave up", errors.Friendly, nil)
+	}
+	return nil
+}
+
+//spendDraws is called before rolling n dice. A negative number of dice rolls none, and must not give draws back.
+func (b *Budget) spendDraws(n int64) error {
+	if n < 0 {
+		n = 0
+	}
+	draws := atomic.AddInt64(&b.draws, n)
+	if b.limits.MaxDraws > 0 && draws > b.limits.MaxDraws {
+		return errors.NewDicelangError(fmt.Sprintf("That's too many dice! I can only roll %d at a time", b.limits.MaxDraws), errors.Friendly, nil)
+	}
+	return nil
+}
+
+//spendWork is called before building part of a distribution, which costs roughly cost operations.
+//Every distribution built against a Budget shares maxDistributionCost.
+func (b *Budget) spendWork(cost float64) error {
+	if cost > maxDistributionCost {
+		return errTooComplex()
+	}
+	work := atomic.AddInt64(&b.work, int64(math.Max(cost, 0)))
+	if work > maxDistributionCost {
+		return errTooComplex()
+	}
+	return b.checkTime()
+}
+
+//orUnlimited returns b, or a Budget without limits when b is nil. Its distributions are still bounded by maxDistributionCost.
+func (b *Budget) orUnlimited() *Budget {
+	if b == nil {
+		return NewBudget(context.Background(), Limits{})
+	}
+	return b
+}
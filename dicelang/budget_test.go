@@ -0,0 +1,119 @@
+package dicelang
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aasmall/dicemagic/dicelang/errors"
+)
+
+func TestBudget(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		name    string
+		cmd     string
+		ctx     context.Context
+		limits  Limits
+		wantErr bool
+	}{
+		{
+			name:   "within default limits",
+			cmd:    "roll 4d6-L rep 6",
+			ctx:    context.Background(),
+			limits: DefaultLimits},
+		{
+			name:    "too many draws",
+			cmd:     "roll 1000d1000 rep 1000",
+			ctx:     context.Background(),
+			limits:  DefaultLimits,
+			wantErr: true},
+		{
+			name:    "too many nodes",
+			cmd:     "roll 1d1 rep 1000",
+			ctx:     context.Background(),
+			limits:  Limits{MaxNodes: 100},
+			wantErr: true},
+		{
+			name:    "too deep",
+			cmd:     "((((((1d4))))))",
+			ctx:     context.Background(),
+			limits:  Limits{MaxDepth: 2},
+			wantErr: true},
+		{
+			name:    "cancelled context",
+			cmd:     "roll 1d20",
+			ctx:     cancelled,
+			limits:  Limits{},
+			wantErr: true},
+		{
+			name:    "timeout",
+			cmd:     "roll 1d20",
+			ctx:     context.Background(),
+			limits:  Limits{Timeout: time.Nanosecond},
+			wantErr: true},
+		{
+			name:    "negative dice don't refund draws",
+			cmd:     "roll -1000000d1 + 1000d6 rep 150",
+			ctx:     context.Background(),
+			limits:  DefaultLimits,
+			wantErr: true},
+		{
+			name:   "unlimited",
+			cmd:    "roll 1000d1000 rep 2",
+			ctx:    context.Background(),
+			limits: Limits{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			budget := NewBudget(tt.ctx, tt.limits)
+			time.Sleep(time.Millisecond)
+			_, _, err := NewParser(tt.cmd).testStatements().GetDiceSet(WithBudget(budget))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AST.GetDiceSet() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				return
+			}
+			if e, ok := err.(*errors.DicelangError); !ok || e.Code != errors.Friendly {
+				t.Errorf("AST.GetDiceSet() error = %#v, want a Friendly DicelangError", err)
+			}
+		})
+	}
+}
+
+func TestBudget_Shared(t *testing.T) {
+	budget := NewBudget(context.Background(), Limits{MaxDraws: 15})
+	root := NewParser("roll 10d6").testStatements()
+	if _, _, err := root.GetDiceSet(WithBudget(budget)); err != nil {
+		t.Fatalf("AST.GetDiceSet() error = %v", err)
+	}
+	if _, _, err := root.GetDiceSet(WithBudget(budget)); err == nil {
+		t.Errorf("AST.GetDiceSet() should run out of draws on the second roll")
+	}
+}
+
+func TestBudget_Colors(t *testing.T) {
+	//the color is the last node evaluated, so the budget runs out on it
+	for _, cmd := range []string{"5 fire", "dc(1d20, 50%) fire", "needed(1d20, 50%) fire"} {
+		t.Run(cmd, func(t *testing.T) {
+			root := NewParser(cmd).testStatements()
+			unlimited := NewBudget(context.Background(), Limits{})
+			if _, _, err := root.GetDiceSet(WithBudget(unlimited)); err != nil {
+				t.Fatalf("AST.GetDiceSet() error = %v", err)
+			}
+			limits := Limits{MaxNodes: unlimited.nodes - 1}
+			if _, _, err := root.GetDiceSet(WithBudget(NewBudget(context.Background(), limits))); err == nil {
+				t.Errorf("AST.GetDiceSet() error = nil, want the budget spent on the color")
+			}
+			program, err := Compile(root)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			if _, _, err := program.Run(WithBudget(NewBudget(context.Background(), limits))); err == nil {
+				t.Errorf("Program.Run() error = nil, want the budget spent on the color")
+			}
+		})
+	}
+}
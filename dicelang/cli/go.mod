@@ -0,0 +1,7 @@
+module github.com/aasmall/dicemagic/dicelang/cli
+
+go 1.12
+
+require github.com/aasmall/dicemagic/dicelang v0.1.0
+
+replace github.com/aasmall/dicemagic/dicelang v0.1.0 => ../
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/aasmall/dicemagic/dicelang"
+)
+
+func main() {
+	var path, cmd, replay, serverSeed, clientSeed string
+	var verbose, prob, stats, transcript, explain, simplify bool
+	var dc, needed float64
+	var seed uint64
+	var nonce int64
+	flag.StringVar(&path, "path", "", "Path to a file with one roll command per line.")
+	flag.StringVar(&cmd, "cmd", "roll 1d20 rep 5", "Roll command")
+	flag.BoolVar(&verbose, "v", false, "Display ast for each statement")
+	flag.BoolVar(&prob, "p", false, "Display probability map for each statement")
+	flag.BoolVar(&stats, "s", false, "Display summary statistics for each command")
+	flag.BoolVar(&explain, "explain", false, "Explain how each command arrived at its total")
+	flag.BoolVar(&simplify, "simplify", false, "Fold constants and merge like dice before rolling")
+	flag.Float64Var(&dc, "dc", 0, "Display the highest target each command meets or beats with this probability (0-1)")
+	flag.Float64Var(&needed, "needed", 0, "Display the lowest target each command stays at or under with this probability (0-1)")
+	flag.Uint64Var(&seed, "seed", 0, "Seed for reproducible rolls. 0 rolls with crypto/rand")
+	flag.BoolVar(&transcript, "t", false, "Display the transcript of random numbers drawn")
+	flag.StringVar(&replay, "replay", "", "Replay a transcript displayed by -t instead of rolling")
+	flag.StringVar(&serverSeed, "server-seed", "", "Recompute a provably fair roll from its revealed server seed")
+	flag.StringVar(&clientSeed, "client-seed", "", "Client seed of a provably fair roll")
+	flag.Int64Var(&nonce, "nonce", 1, "Nonce of a provably fair roll")
+	flag.Parse()
+	var src dicelang.RandomSource = dicelang.NewCryptoSource()
+	var replaySource *dicelang.ReplaySource
+	if replay != "" {
+		draws, err := dicelang.ParseTranscript(replay)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		replaySource = dicelang.NewReplaySource(draws)
+		src = replaySource
+	} else if serverSeed != "" {
+		fmt.Printf("Commitment: %s\n", dicelang.FairCommitment(serverSeed))
+		src = dicelang.NewFairSource(serverSeed, clientSeed, nonce)
+	} else if seed != 0 {
+		src = dicelang.NewPCGSource(seed)
+	}
+	var recorder *dicelang.RecordingSource
+	if transcript {
+		recorder = dicelang.NewRecordingSource(src)
+		src = recorder
+	}
+	opts := []dicelang.EvalOption{dicelang.WithRandomSource(src)}
+	if simplify {
+		opts = append(opts, dicelang.WithSimplify())
+	}
+	if path == "" {
+		fmt.Println(cmd)
+		printCommand(cmd, verbose, prob, stats, explain, dc, needed, opts...)
+	} else {
+		c := make(chan string)
+		go readRollsFromFile(c, path)
+		for cmd := range c {
+			fmt.Println(cmd)
+			printCommand(cmd, verbose, prob, stats, explain, dc, needed, opts...)
+		}
+	}
+	if recorder != nil {
+		fmt.Printf("Transcript: %s\n", dicelang.FormatTranscript(recorder.Transcript()))
+	}
+	if replaySource != nil {
+		if err := replaySource.Done(); err != nil {
+			fmt.Println(err.Error())
+		}
+	}
+}
+
+func printCommand(cmd string, verbose bool, prob bool, stats bool, explain bool, dc float64, needed float64, opts ...dicelang.EvalOption) {
+	if strings.HasPrefix(strings.ToLower(strings.TrimSpace(cmd)), "compare") {
+		printComparison(cmd)
+		return
+	}
+	printDiceInfo(cmd, verbose, prob, stats, explain, dc, needed, opts...)
+}
+
+func printComparison(cmd string) {
+	cmdA, cmdB, err := dicelang.SplitComparison(cmd)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	var dists []dicelang.Distribution
+	for _, c := range []string{cmdA, cmdB} {
+		root, err := dicelang.NewParser(c).Statements()
+		if err != nil {
+			fmt.Printf("Could not parse %s: %v\n", c, err)
+			return
+		}
+		dist, err := root.Distribution()
+		if err != nil {
+			fmt.Printf("Could not calculate probabilities for %s: %v\n", c, err)
+			return
+		}
+		dists = append(dists, dist)
+	}
+	a, b := dists[0], dists[1]
+	comparison := a.Compare(b)
+	fmt.Printf("Mean of %s: %2.2F\n", cmdA, a.Mean())
+	fmt.Printf("Mean of %s: %2.2F\n", cmdB, b.Mean())
+	fmt.Printf("P(%s > %s): %2.2F%%\n", cmdA, cmdB, comparison.Greater*100)
+	fmt.Printf("P(%s = %s): %2.2F%%\n", cmdA, cmdB, comparison.Equal*100)
+	fmt.Printf("P(%s < %s): %2.2F%%\n", cmdA, cmdB, comparison.Less*100)
+	fmt.Printf("%6s  %10s  %10s\n", "Result", cmdA, cmdB)
+	for _, k := range dicelang.MergeOutcomes(a, b) {
+		fmt.Printf("%6v  %9.5F%%  %9.5F%%\n", k, a[k]*100, b[k]*100)
+	}
+	fmt.Println("----------")
+}
+
+func sortProbMap(m map[int64]float64) []int64 {
+	var keys []int64
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+func printDiceInfo(cmd string, verbose bool, prob bool, stats bool, explain bool, dc float64, needed float64, opts ...dicelang.EvalOption) {
+	var p *dicelang.Parser
+	p = dicelang.NewParser(cmd)
+	root, err := p.Statements()
+	if err != nil {
+		if parseErr, ok := err.(*dicelang.ParseError); ok {
+			fmt.Print(parseErr.Caret())
+		} else {
+			fmt.Println(err.Error())
+		}
+		return
+	}
+	//fmt.Printf("Statement %d\n", i+1)
+	var tr dicelang.Trace
+	if explain {
+		opts = append(opts, dicelang.WithTrace(&tr))
+	}
+	values, diceSet, err := root.GetList(opts...)
+	if err != nil {
+		fmt.Printf("Could not parse input: %v\n", err)
+		return
+	}
+	var total float64
+	for _, x := range values {
+		total += x
+	}
+	if explain {
+		fmt.Printf("Explanation: %s\n", tr.String())
+	}
+	if verbose {
+		fmt.Print("AST:\n----------")
+		dicelang.PrintAST(root, 0)
+		fmt.Print("\n----------")
+
+	}
+	if prob {
+		for _, v := range diceSet.Dice {
+			probMap := dicelang.DiceProbability(v.Count, v.Sides, v.DropHighest, v.DropLowest)
+			keys := sortProbMap(probMap)
+			fmt.Printf("\nProbability Map for %+v:\n", v)
+			for _, k := range keys {
+				fmt.Printf("%2d:  %2.5F%%\n", k, probMap[k])
+			}
+			fmt.Print("----------\n")
+		}
+	}
+	if stats {
+		if analysis, err := dicelang.Analyze(root); err == nil {
+			fmt.Printf("Bounds: %+v\n", analysis.Bounds)
+			fmt.Printf("Bounds by Color: %+v\n", analysis.ByColor)
+		}
+		dist, err := root.Distribution()
+		if err != nil {
+			fmt.Printf("Could not calculate statistics: %v\n", err)
+		} else {
+			fmt.Printf("Statistics: %+v\n", dist.Stats())
+			fmt.Printf("Top: %2.2F%%\n", dist.AtLeast(total)*100)
+		}
+	}
+	if dc > 0 || needed > 0 {
+		dist, err := root.Distribution()
+		if err != nil {
+			fmt.Printf("Could not calculate probabilities: %v\n", err)
+		} else {
+			if dc > 0 {
+				fmt.Printf("DC at %2.2F%%: %v\n", dc*100, dist.DifficultyClass(dc))
+			}
+			if needed > 0 {
+				fmt.Printf("Needed at %2.2F%%: %v\n", needed*100, dist.Percentile(needed))
+			}
+		}
+	}
+	fmt.Printf("Total: %+v\n", total)
+	if root.IsList() {
+		fmt.Printf("Values: %v\n", values)
+	}
+	fmt.Printf("Color Map: %+v\n", diceSet.TotalsByColor)
+	//pre := dicelang.ReStringAST(stmt)
+	pre, _ := root.String()
+	fmt.Println(pre)
+	fmt.Println("----------")
+	fmt.Println(fmt.Printf("%+v", diceSet))
+	fmt.Println("----------")
+
+}
+
+func readRollsFromFile(c chan string, path string) {
+	defer close(c)
+	file, err := os.Open(path)
+	if err != nil {
+		fmt.Printf("Could not open file: %v\n", err)
+		return
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		c <- scanner.Text()
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Printf("Could not scan file: %v\n", err)
+		return
+	}
+}
@@ -0,0 +1,60 @@
+package dicelang
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/aasmall/dicemagic/dicelang/errors"
+)
+
+var compareSplitter = regexp.MustCompile(`(?i)\s+vs\.?\s+`)
+
+//Comparison holds the chance (0-1) that one expression beats, ties or loses to another
+type Comparison struct {
+	Greater float64
+	Equal   float64
+	Less    float64
+}
+
+//Compare returns the chance a result of d is greater than, equal to, or less than an independent result of other
+func (d Distribution) Compare(other Distribution) Comparison {
+	var c Comparison
+	for x, px := range d {
+		for y, py := range other {
+			switch {
+			case x > y:
+				c.Greater += px * py
+			case x == y:
+				c.Equal += px * py
+			default:
+				c.Less += px * py
+			}
+		}
+	}
+	return c
+}
+
+//MergeOutcomes returns every result of any of dists in ascending order, as a table comparing them lists them
+func MergeOutcomes(dists ...Distribution) []float64 {
+	merged := make(Distribution)
+	for _, d := range dists {
+		for k := range d {
+			merged[k] = 1
+		}
+	}
+	return merged.Outcomes()
+}
+
+//SplitComparison splits a command like "compare 2d6 vs 1d12" into its two expressions.
+//The leading "compare" is optional.
+func SplitComparison(cmd string) (string, string, error) {
+	cmd = strings.TrimSpace(cmd)
+	if len(cmd) >= len("compare") && strings.EqualFold(cmd[:len("compare")], "compare") {
+		cmd = cmd[len("compare"):]
+	}
+	parts := compareSplitter.Split(strings.TrimSpace(cmd), -1)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+		return "", "", errors.NewDicelangError("compare needs exactly two rolls, like: compare 2d6 vs 1d12", errors.Friendly, nil)
+	}
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]), nil
+}
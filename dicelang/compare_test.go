@@ -0,0 +1,90 @@
+package dicelang
+
+import (
+	"testing"
+)
+
+func TestDistribution_Compare(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want Comparison
+	}{
+		{
+			name: "greatsword vs greataxe",
+			a:    "2d6",
+			b:    "1d12",
+			want: Comparison{Greater: 0.5, Equal: 1.0 / 12, Less: 5.0 / 12}},
+		{
+			name: "identical",
+			a:    "1d4",
+			b:    "1d4",
+			want: Comparison{Greater: 0.375, Equal: 0.25, Less: 0.375}},
+		{
+			name: "constant",
+			a:    "1d6",
+			b:    "4",
+			want: Comparison{Greater: 2.0 / 6, Equal: 1.0 / 6, Less: 3.0 / 6}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := NewParser(tt.a).testStatements().Distribution()
+			if err != nil {
+				t.Fatalf("AST.Distribution() error = %v", err)
+			}
+			b, err := NewParser(tt.b).testStatements().Distribution()
+			if err != nil {
+				t.Fatalf("AST.Distribution() error = %v", err)
+			}
+			got := a.Compare(b)
+			if !floatEquals(got.Greater, tt.want.Greater) || !floatEquals(got.Equal, tt.want.Equal) || !floatEquals(got.Less, tt.want.Less) {
+				t.Errorf("Distribution.Compare() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitComparison(t *testing.T) {
+	tests := []struct {
+		cmd     string
+		wantA   string
+		wantB   string
+		wantErr bool
+	}{
+		{cmd: "compare 2d6 vs 1d12", wantA: "2d6", wantB: "1d12"},
+		{cmd: "COMPARE 2d6+3 VS. 1d12 + 3", wantA: "2d6+3", wantB: "1d12 + 3"},
+		{cmd: "4d6-L vs 3d6", wantA: "4d6-L", wantB: "3d6"},
+		{cmd: "compare 2d6", wantErr: true},
+		{cmd: "compare 1d4 vs 1d6 vs 1d8", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cmd, func(t *testing.T) {
+			a, b, err := SplitComparison(tt.cmd)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SplitComparison() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if a != tt.wantA || b != tt.wantB {
+				t.Errorf("SplitComparison() = %q, %q, want %q, %q", a, b, tt.wantA, tt.wantB)
+			}
+		})
+	}
+}
+
+func TestMergeOutcomes(t *testing.T) {
+	a := Distribution{3: 0.5, 1: 0.5}
+	b := Distribution{2: 0.25, 3: 0.75}
+	want := []float64{1, 2, 3}
+	got := MergeOutcomes(a, b)
+	if len(got) != len(want) {
+		t.Fatalf("MergeOutcomes() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("MergeOutcomes() = %v, want %v", got, want)
+		}
+	}
+	if got := MergeOutcomes(); len(got) != 0 {
+		t.Errorf("MergeOutcomes() of nothing = %v, want none", got)
+	}
+}
@@ -0,0 +1,248 @@
+package dicelang
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aasmall/dicemagic/dicelang/errors"
+)
+
+//Program is an AST compiled to instructions for a small stack machine.
+//Running a Program gives the same results from the same random draws as evaluating the AST,
+//without walking the tree or looking up the evaluator of every node. That saves a little on every run,
+//which adds up only when the same Program is run many times.
+//A Program is never changed by running it, so it may be run by many goroutines at once.
+type Program struct {
+	root  *AST
+	code  []instruction
+	nodes []*AST
+	fails []func() error
+}
+
+type opcode uint8
+
+const (
+	//opEnter and opLeave spend the budget of a node, like AST.eval
+	opEnter opcode = iota
+	opLeave
+	//opPush pushes x
+	opPush
+	//opColor pushes the color nodes[arg] onto the DiceSet
+	opColor
+	//opEval pushes the result of evaluating nodes[arg] as a tree. Nodes without an instruction are evaluated this way.
+	opEval
+	//opColorEval evaluates nodes[arg] as a tree for its colors, ignoring its result
+	opColorEval
+	//opSum pops arg values and pushes their sum
+	opSum
+	//opDrop pops the number of dice the next roll drops. sym is "-H" or "-L".
+	opDrop
+	//opDice pops arg values and pushes the roll of the dice made of the first two
+	opDice
+	//opBeginArithmetic marks the dice rolled before an arithmetic expression
+	opBeginArithmetic
+	//opArithmetic pops arg values and pushes the result of the arithmetic operator sym
+	opArithmetic
+	//opPercent divides the top value by 100
+	opPercent
+	//opRepeat pops a number of repetitions and pushes a total of 0
+	opRepeat
+	//opNext jumps to arg once every repetition is done, removing the total's repetition count
+	opNext
+	//opAdd pops a value and adds it to the value below it
+	opAdd
+	//opJump jumps to arg
+	opJump
+	//opCompare pops two values and pushes 1 when the comparison sym holds, or 0
+	opCompare
+	//opJumpFalse pops a value and jumps to arg when it is 0
+	opJumpFalse
+	//opFail stops with the error made by fails[arg]
+	opFail
+)
+
+var opcodeNames = [...]string{"enter", "leave", "push", "color", "eval", "coloreval", "sum", "drop", "dice",
+	"beginarithmetic", "arithmetic", "percent", "repeat", "next", "add", "jump", "compare", "jumpfalse", "fail"}
+
+func (op opcode) String() string {
+	return opcodeNames[op]
+}
+
+type instruction struct {
+	op  opcode
+	sym string
+	arg int
+	x   float64
+}
+
+//Compile compiles t to a Program
+func Compile(t *AST) (*Program, error) {
+	if t == nil {
+		return nil, errors.NewDicelangError("Cannot compile an empty AST", errors.InvalidAST, nil)
+	}
+	p := &Program{root: t}
+	p.compile(t)
+	return p, nil
+}
+
+func (p *Program) emit(op opcode, arg int) int {
+	p.code = append(p.code, instruction{op: op, arg: arg})
+	return len(p.code) - 1
+}
+
+func (p *Program) node(t *AST) int {
+	p.nodes = append(p.nodes, t)
+	return len(p.nodes) - 1
+}
+
+func (p *Program) fail(f func() error) {
+	p.fails = append(p.fails, f)
+	p.emit(opFail, len(p.fails)-1)
+}
+
+func invalid(text string) func() error {
+	return func() error {
+		return errors.NewDicelangError(text, errors.InvalidAST, nil)
+	}
+}
+
+//compile emits the instructions that push the value of t
+func (p *Program) compile(t *AST) {
+	sym := strings.ToUpper(t.Sym)
+	switch sym {
+	case "(NUMBER)", "(IDENT)", "-L", "-H", "D", "+", "-", "*", "/", "^", "%", "REP", "IF", "ROLL", "{", "(ROOTNODE)":
+	default:
+		p.emit(opEval, p.node(t))
+		return
+	}
+	p.emit(opEnter, 0)
+	switch sym {
+	case "(NUMBER)":
+		x, _ := strconv.ParseFloat(t.Value, 64)
+		p.push(x)
+		if len(t.Children) > 0 {
+			if c := t.Children[0]; c.Sym == "(IDENT)" {
+				p.emit(opEnter, 0)
+				p.emit(opColor, p.node(c))
+				p.emit(opLeave, 0)
+			} else {
+				p.emit(opColorEval, p.node(c))
+			}
+		}
+	case "(IDENT)":
+		p.emit(opColor, p.node(t))
+		p.push(0)
+	case "-L", "-H":
+		for _, c := range t.Children {
+			p.compile(c)
+		}
+		p.emit(opSum, len(t.Children))
+		p.code = append(p.code, instruction{op: opDrop, sym: t.Sym})
+		p.push(0)
+	case "D":
+		for _, c := range t.Children {
+			p.compile(c)
+		}
+		if len(t.Children) < 2 {
+			p.fail(invalid("Invalid dice"))
+			break
+		}
+		p.emit(opDice, len(t.Children))
+	case "+", "-", "*", "/", "^":
+		p.emit(opBeginArithmetic, 0)
+		var n int
+		for _, c := range t.Children {
+			if c.Sym == "(IDENT)" {
+				p.emit(opEnter, 0)
+				p.emit(opColor, p.node(c))
+				p.emit(opLeave, 0)
+				continue
+			}
+			p.compile(c)
+			n++
+		}
+		p.code = append(p.code, instruction{op: opArithmetic, arg: n, sym: t.Sym})
+	case "%":
+		if len(t.Children) < 1 {
+			p.fail(invalid("Invalid percentage"))
+			break
+		}
+		p.compile(t.Children[0])
+		p.emit(opPercent, 0)
+	case "REP":
+		if len(t.Children) < 2 {
+			p.fail(invalid("Invalid repetition"))
+			break
+		}
+		p.compile(t.Children[1])
+		p.emit(opRepeat, 0)
+		loop := p.emit(opNext, 0)
+		p.compile(t.Children[0])
+		p.emit(opAdd, 0)
+		p.emit(opJump, loop)
+		p.code[loop].arg = len(p.code)
+	case "IF":
+		p.compileIf(t)
+	default:
+		//ROLL, blocks and the root add up their statements
+		for _, c := range t.Children {
+			p.compile(c)
+		}
+		p.emit(opSum, len(t.Children))
+	}
+	p.emit(opLeave, 0)
+}
+
+func (p *Program) push(x float64) {
+	p.code = append(p.code, instruction{op: opPush, x: x})
+}
+
+func (p *Program) compileIf(t *AST) {
+	if len(t.Children) < 2 {
+		p.fail(invalid("Invalid if"))
+		return
+	}
+	cond := t.Children[0]
+	if len(cond.Children) < 2 {
+		p.fail(func() error {
+			return errors.NewDicelangError("if needs a comparison, like 1d20 > 10", errors.Friendly, nil)
+		})
+		return
+	}
+	p.compile(cond.Children[0])
+	p.compile(cond.Children[1])
+	p.code = append(p.code, instruction{op: opCompare, sym: cond.Sym})
+	otherwise := p.emit(opJumpFalse, 0)
+	p.compile(t.Children[1])
+	end := p.emit(opJump, 0)
+	p.code[otherwise].arg = len(p.code)
+	if len(t.Children) < 3 {
+		p.push(0)
+	} else {
+		p.compile(t.Children[2])
+	}
+	p.code[end].arg = len(p.code)
+}
+
+//String lists the instructions of the Program, one per line
+func (p *Program) String() string {
+	var b strings.Builder
+	for i, in := range p.code {
+		fmt.Fprintf(&b, "%4d %s", i, in.op)
+		switch in.op {
+		case opPush:
+			fmt.Fprintf(&b, " %g", in.x)
+		case opColor, opEval, opColorEval:
+			fmt.Fprintf(&b, " %s", p.nodes[in.arg].Sym)
+		case opDrop, opArithmetic, opCompare:
+			fmt.Fprintf(&b, " %s", in.sym)
+		}
+		switch in.op {
+		case opSum, opDice, opArithmetic, opNext, opJump, opJumpFalse:
+			fmt.Fprintf(&b, " %d", in.arg)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,125 @@
+package dicelang
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestProgram_Run(t *testing.T) {
+	sources := []string{
+		"roll 1d20+5",
+		"roll 1d20+5 fire, 2d6 cold",
+		"4d6-L rep 6",
+		"(3d6-H1 + 2) ice",
+		"1d4 + 2 if 1d20 > 10 else 1d8",
+		"if 1d20 >= 15 { 2d6 fire }",
+		"(2d6+1)% + 50%",
+		"-1d4 - -2",
+		"2 ^ 3 * 4 / 5",
+		"dc(1d20 + 5, 12)",
+		"1 mod 2",
+		"1d4 fire + 1d4 ice",
+		"1 if 1d2 else 2",
+		"[4d6-L; 6] fire",
+		"sort([1d20, 1d20 + 5])[1] + max([1d4; 2])",
+	}
+	for _, source := range sources {
+		root, err := NewParser(source).Statements()
+		if err != nil {
+			t.Fatalf("Statements(%q) error = %v", source, err)
+		}
+		compareRun(t, source, root)
+	}
+}
+
+func TestProgram_RunRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		root := randomRoot(r)
+		compareRun(t, astString(root), root)
+	}
+}
+
+//compareRun checks a Program returns what evaluating root returns, from the same draws and within the same limits
+func compareRun(t *testing.T, name string, root *AST) {
+	t.Helper()
+	limits := Limits{MaxDraws: 1000, MaxNodes: 1000, MaxDepth: 50}
+	p, err := Compile(root)
+	if err != nil {
+		t.Fatalf("Compile(%s) error = %v", name, err)
+	}
+	for seed := uint64(0); seed < 3; seed++ {
+		want, wantDs, wantErr := root.GetDiceSet(WithSeed(seed), WithBudget(NewBudget(context.Background(), limits)))
+		got, gotDs, err := p.Run(WithSeed(seed), WithBudget(NewBudget(context.Background(), limits)))
+		if (err == nil) != (wantErr == nil) || (err != nil && err.Error() != wantErr.Error()) {
+			t.Fatalf("Run(%s) error = %v, want %v\n%s", name, err, wantErr, p)
+		}
+		if err != nil {
+			continue
+		}
+		if !sameFloat(got, want) {
+			t.Fatalf("Run(%s) = %v, want %v\n%s", name, got, want, p)
+		}
+		if !reflect.DeepEqual(gotDs.Dice, wantDs.Dice) || !equalTotals(gotDs.TotalsByColor, wantDs.TotalsByColor) {
+			t.Fatalf("Run(%s) rolled %+v %v, want %+v %v\n%s", name, gotDs.Dice, gotDs.TotalsByColor, wantDs.Dice, wantDs.TotalsByColor, p)
+		}
+	}
+}
+
+func equalTotals(a map[string]float64, b map[string]float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, x := range a {
+		y, ok := b[k]
+		if !ok || !sameFloat(x, y) {
+			return false
+		}
+	}
+	return true
+}
+
+//sameFloat reports whether x and y are equal, both NaN, or differ only by rounding, as the mean of a distribution may
+func sameFloat(x float64, y float64) bool {
+	return x == y || (math.IsNaN(x) && math.IsNaN(y)) || closeTo(x, y)
+}
+
+var benchResult float64
+
+func benchmarkEval(source string, compiled bool, b *testing.B) {
+	root, err := NewParser(source).Statements()
+	if err != nil {
+		b.Fatal(err)
+	}
+	p, err := Compile(root)
+	if err != nil {
+		b.Fatal(err)
+	}
+	var x float64
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if compiled {
+			x, _, err = p.Run(WithSeed(uint64(n)))
+		} else {
+			x, _, err = root.GetDiceSet(WithSeed(uint64(n)))
+		}
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+	benchResult = x
+}
+
+func BenchmarkEval_AST(b *testing.B)     { benchmarkEval("roll 1d20+5 fire, 2d6 cold", false, b) }
+func BenchmarkEval_Program(b *testing.B) { benchmarkEval("roll 1d20+5 fire, 2d6 cold", true, b) }
+func BenchmarkEvalRep_AST(b *testing.B) {
+	benchmarkEval("(1d20 + 5 if 1d20 > 10 else 0) rep 100", false, b)
+}
+func BenchmarkEvalRep_Program(b *testing.B) {
+	benchmarkEval("(1d20 + 5 if 1d20 > 10 else 0) rep 100", true, b)
+}
+func BenchmarkMonteCarlo_AST(b *testing.B)     { benchmarkEval("(4d6-L1 + 2) rep 100000", false, b) }
+func BenchmarkMonteCarlo_Program(b *testing.B) { benchmarkEval("(4d6-L1 + 2) rep 100000", true, b) }
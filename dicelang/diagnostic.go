@@ -0,0 +1,188 @@
+package dicelang
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aasmall/dicemagic/dicelang/errors"
+)
+
+//Severity says whether a Diagnostic stops source from parsing
+type Severity int
+
+const (
+	//SeverityError stops source from parsing
+	SeverityError Severity = iota
+	//SeverityWarning is worth telling the author about, but the source still parses
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	if s == SeverityWarning {
+		return "warning"
+	}
+	return "error"
+}
+
+//Position is a line and column in source, both counting from 1. Columns count runes, not bytes.
+type Position struct {
+	Line int
+	Col  int
+}
+
+//Diagnostic is a problem found in source, spanning Start up to, but not including, End
+type Diagnostic struct {
+	Severity Severity
+	Message  string
+	Start    Position
+	End      Position
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%d:%d: %s", d.Start.Line, d.Start.Col, d.Message)
+}
+
+//ParseError is returned by Parser.Statements when source does not parse. It holds every problem found, in order.
+type ParseError struct {
+	Source      string
+	Diagnostics []Diagnostic
+}
+
+//Error returns the first diagnostic
+func (e *ParseError) Error() string {
+	if len(e.Diagnostics) == 0 {
+		return "could not parse"
+	}
+	s := e.Diagnostics[0].String()
+	if len(e.Diagnostics) > 1 {
+		s += fmt.Sprintf(" (and %d more)", len(e.Diagnostics)-1)
+	}
+	return s
+}
+
+//Caret renders each diagnostic under the line it was found on, like
+//	1d20 + + 5
+//	       ^ unexpected '+'
+func (e *ParseError) Caret() string {
+	lines := strings.Split(e.Source, "\n")
+	var b strings.Builder
+	for _, d := range e.Diagnostics {
+		if d.Start.Line < 1 || d.Start.Line > len(lines) {
+			fmt.Fprintf(&b, "%s\n", d)
+			continue
+		}
+		line := strings.TrimRight(lines[d.Start.Line-1], "\r")
+		if len(lines) > 1 {
+			fmt.Fprintf(&b, "line %d:\n", d.Start.Line)
+		}
+		width := 1
+		if d.End.Line == d.Start.Line && d.End.Col > d.Start.Col {
+			width = d.End.Col - d.Start.Col
+		} else if d.End.Line > d.Start.Line {
+			//spans over more than one line are underlined to the end of the first
+			width = utf8.RuneCountInString(line) - d.Start.Col + 1
+		}
+		if width < 1 {
+			width = 1
+		}
+		fmt.Fprintf(&b, "%s\n%s^%s %s\n", line, caretIndent(line, d.Start.Col), strings.Repeat("~", width-1), d.Message)
+	}
+	return b.String()
+}
+
+//caretIndent returns the whitespace before col, keeping tabs so the caret lines up
+func caretIndent(line string, col int) string {
+	var b strings.Builder
+	i := 1
+	for _, r := range line {
+		if i >= col {
+			break
+		}
+		if r == '\t' {
+			b.WriteRune('\t')
+		} else {
+			b.WriteRune(' ')
+		}
+		i++
+	}
+	for ; i < col; i++ {
+		b.WriteRune(' ')
+	}
+	return b.String()
+}
+
+//errorAt returns a Diagnostic from the start of t to where the lexer is now
+func (parse *Parser) errorAt(t *AST, format string, a ...interface{}) *Diagnostic {
+	return &Diagnostic{
+		Severity: SeverityError,
+		Message:  fmt.Sprintf(format, a...),
+		Start:    Position{Line: t.line, Col: t.col},
+		End:      Position{Line: parse.lexer.line, Col: parse.lexer.col},
+	}
+}
+
+//Error returns the message, so a Diagnostic can be returned while parsing
+func (d *Diagnostic) Error() string {
+	return d.Message
+}
+
+//diagnose records err, which stopped a statement from parsing
+func (parse *Parser) diagnose(err error) {
+	switch e := err.(type) {
+	case *Diagnostic:
+		parse.diagnostics = append(parse.diagnostics, *e)
+	case *errors.LexError:
+		parse.diagnostics = append(parse.diagnostics, Diagnostic{
+			Severity: SeverityError,
+			Message:  e.Err,
+			Start:    Position{Line: e.Line, Col: e.Col},
+			End:      Position{Line: e.Line, Col: e.Col + 1},
+		})
+	default:
+		parse.diagnostics = append(parse.diagnostics, Diagnostic{
+			Severity: SeverityError,
+			Message:  err.Error(),
+			Start:    Position{Line: parse.lexer.line, Col: parse.lexer.col},
+			End:      Position{Line: parse.lexer.line, Col: parse.lexer.col + 1},
+		})
+	}
+}
+
+//synchronize skips to the start of the next statement, so parsing can continue after an error
+func (parse *Parser) synchronize() {
+	for {
+		tok, err := parse.lexer.peek()
+		if err != nil {
+			parse.lexer.skipRune()
+			continue
+		}
+		if tok.Sym == "(EOF)" || tok.Sym == "}" {
+			return
+		}
+		parse.lexer.next()
+		if endsStatement(tok) {
+			return
+		}
+	}
+}
+
+//endsStatement reports whether t separates statements
+func endsStatement(t *AST) bool {
+	switch t.Sym {
+	case "(EOF)", "}", "(NEWLINE)", ",", "AND":
+		return true
+	}
+	return false
+}
+
+//describe names a token in a diagnostic
+func describe(t *AST) string {
+	switch t.Sym {
+	case "(EOF)":
+		return "end of input"
+	case "(NEWLINE)":
+		return "end of line"
+	}
+	return fmt.Sprintf("'%s'", t.Value)
+}
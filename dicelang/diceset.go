@@ -16,10 +16,20 @@ func MergeDiceTotalMaps(mapsToMerge ...map[string]float64) map[string]float64 {
 }
 
 //GetDiceSet returns the sum of an AST, a DiceSet, and an error
-func (t *AST) GetDiceSet() (float64, DiceSet, error) {
-	v, ret, err := t.eval(&DiceSet{})
+func (t *AST) GetDiceSet(opts ...EvalOption) (float64, DiceSet, error) {
+	ds := &DiceSet{}
+	for _, o := range opts {
+		o(ds)
+	}
+	if ds.simplify {
+		t = Simplify(t)
+	}
+	v, ret, err := t.eval(ds)
 	if err != nil {
-		return 0, DiceSet{}, err
+		if ret == nil {
+			return 0, DiceSet{}, err
+		}
+		return 0, *ret, err
 	}
 	return v, *ret, err
 }
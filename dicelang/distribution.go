@@ -0,0 +1,507 @@
+package dicelang
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aasmall/dicemagic/dicelang/errors"
+)
+
+// maxDistributionCost caps the work spent building a single distribution.
+// It is a rough count of the floating point operations required.
+const maxDistributionCost = 50000000
+
+//Distribution maps every possible result of an expression to its probability (0-1)
+type Distribution map[float64]float64
+
+//Stats summarizes a Distribution
+type Stats struct {
+	Mean         float64
+	StdDev       float64
+	Percentile5  float64
+	Percentile50 float64
+	Percentile95 float64
+}
+
+func errTooComplex() error {
+	return errors.NewDicelangError("That roll is too complex to calculate probabilities for", errors.Friendly, nil)
+}
+
+func pointDistribution(v float64) Distribution {
+	return Distribution{v: 1}
+}
+
+//DiceDistribution returns the distribution of the total of a single throw of dice.
+//It is the same as DiceProbability, but in the range 0-1 and bounded by maxDistributionCost.
+func DiceDistribution(numberOfDice, sides, H, L int64) (Distribution, error) {
+	if numberOfDice < 0 || sides < 1 {
+		return nil, errors.NewDicelangError("/me ponders the meaning of a zero sided die", errors.Friendly, nil)
+	}
+	H, L = droppedDice(H, L)
+	if H+L >= numberOfDice {
+		return pointDistribution(0), nil
+	}
+	if diceCost(numberOfDice, sides, H, L) > maxDistributionCost {
+		return nil, errTooComplex()
+	}
+	if H > 0 || L > 0 {
+		d := make(Distribution)
+		for k, v := range DiceProbability(numberOfDice, sides, H, L) {
+			d[float64(k)] = v / 100
+		}
+		return d, nil
+	}
+	ways := []float64{1}
+	p := 1 / float64(sides)
+	for i := int64(0); i < numberOfDice; i++ {
+		next := make([]float64, len(ways)+int(sides))
+		for total, w := range ways {
+			if w == 0 {
+				continue
+			}
+			for face := 1; face <= int(sides); face++ {
+				next[total+face] += w * p
+			}
+		}
+		ways = next
+	}
+	d := make(Distribution)
+	for total, w := range ways {
+		if w > 0 {
+			d[float64(total)] = w
+		}
+	}
+	return d, nil
+}
+
+//diceCost estimates the work DiceDistribution does
+func diceCost(numberOfDice, sides, H, L int64) float64 {
+	H, L = droppedDice(H, L)
+	n, s := float64(numberOfDice), float64(sides)
+	if H+L >= numberOfDice {
+		return 1
+	}
+	if H > 0 || L > 0 {
+		// outcomes() is roughly cubic in the dice and quadratic in the sides
+		return n * n * n * s * s
+	}
+	// without drops a direct convolution is much cheaper than outcomes()
+	return n * n * s * s
+}
+
+//Outcomes returns all possible results in ascending order
+func (d Distribution) Outcomes() []float64 {
+	keys := make([]float64, 0, len(d))
+	for k := range d {
+		keys = append(keys, k)
+	}
+	sort.Float64s(keys)
+	return keys
+}
+
+//Mean returns the expected value
+func (d Distribution) Mean() float64 {
+	var mean float64
+	for k, v := range d {
+		mean += k * v
+	}
+	return mean
+}
+
+//Variance returns the variance
+func (d Distribution) Variance() float64 {
+	mean := d.Mean()
+	var variance float64
+	for k, v := range d {
+		variance += (k - mean) * (k - mean) * v
+	}
+	return variance
+}
+
+//StdDev returns the standard deviation
+func (d Distribution) StdDev() float64 {
+	return math.Sqrt(d.Variance())
+}
+
+//Percentile returns the smallest result which is greater than or equal to p (0-1) of all results
+func (d Distribution) Percentile(p float64) float64 {
+	var cumulative float64
+	outcomes := d.Outcomes()
+	for _, k := range outcomes {
+		cumulative += d[k]
+		if cumulative >= p-1e-9 {
+			return k
+		}
+	}
+	if len(outcomes) == 0 {
+		return 0
+	}
+	return outcomes[len(outcomes)-1]
+}
+
+//DifficultyClass returns the highest target that a result meets or beats with probability p (0-1)
+func (d Distribution) DifficultyClass(p float64) float64 {
+	var cumulative float64
+	outcomes := d.Outcomes()
+	for i := len(outcomes) - 1; i >= 0; i-- {
+		cumulative += d[outcomes[i]]
+		if cumulative >= p-1e-9 {
+			return outcomes[i]
+		}
+	}
+	if len(outcomes) == 0 {
+		return 0
+	}
+	return outcomes[0]
+}
+
+//AtLeast returns the probability of a result greater than or equal to x
+func (d Distribution) AtLeast(x float64) float64 {
+	var p float64
+	for k, v := range d {
+		if k >= x {
+			p += v
+		}
+	}
+	return p
+}
+
+//AtMost returns the probability of a result less than or equal to x
+func (d Distribution) AtMost(x float64) float64 {
+	var p float64
+	for k, v := range d {
+		if k <= x {
+			p += v
+		}
+	}
+	return p
+}
+
+//Stats summarizes the distribution
+func (d Distribution) Stats() Stats {
+	return Stats{
+		Mean:         d.Mean(),
+		StdDev:       d.StdDev(),
+		Percentile5:  d.Percentile(.05),
+		Percentile50: d.Percentile(.5),
+		Percentile95: d.Percentile(.95),
+	}
+}
+
+//mapCost is the cost of adding to a Distribution, which is much more than a floating point operation
+const mapCost = 10
+
+//combine builds the distribution of op(x, y) for independent x and y
+func combine(budget *Budget, a, b Distribution, op func(x, y float64) float64) (Distribution, error) {
+	if err := budget.spendWork(mapCost * float64(len(a)) * float64(len(b))); err != nil {
+		return nil, err
+	}
+	d := make(Distribution)
+	for x, px := range a {
+		for y, py := range b {
+			d[op(x, y)] += px * py
+		}
+	}
+	return d, nil
+}
+
+//mixture weights each distribution returned by f by the probability of its key in d
+func mixture(budget *Budget, d Distribution, f func(x float64) (Distribution, error)) (Distribution, error) {
+	out := make(Distribution)
+	for x, px := range d {
+		dx, err := f(x)
+		if err != nil {
+			return nil, err
+		}
+		if err := budget.spendWork(mapCost * float64(len(dx))); err != nil {
+			return nil, err
+		}
+		for y, py := range dx {
+			out[y] += px * py
+		}
+	}
+	return out, nil
+}
+
+//repeat builds the distribution of the sum of n independent copies of d
+func repeat(budget *Budget, d Distribution, n int) (Distribution, error) {
+	//the ith sum has about i times as many results as d, so the work is quadratic in n. Checking that up front
+	//gives up at once, rather than after most of the work is done.
+	k := float64(len(d))
+	if mapCost*k*(float64(n)+(k-1)*float64(n)*float64(n-1)/2) > maxDistributionCost {
+		return nil, errTooComplex()
+	}
+	out := pointDistribution(0)
+	var err error
+	for i := 0; i < n; i++ {
+		out, err = combine(budget, out, d, add)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func add(x, y float64) float64 { return x + y }
+
+//Distribution returns the probability of every possible result of the AST, without rolling any dice.
+//Lists are not supported, and return an "Unsupported symbol" error.
+func (t *AST) Distribution() (Distribution, error) {
+	return t.DistributionWithBudget(nil)
+}
+
+//DistributionWithBudget is Distribution, which gives up with a Friendly error once b is out of time.
+//Every distribution built against b shares the same bound on its complexity.
+func (t *AST) DistributionWithBudget(b *Budget) (Distribution, error) {
+	return t.distribution(b.orUnlimited())
+}
+
+func (t *AST) distribution(budget *Budget) (Distribution, error) {
+	switch strings.ToUpper(t.Sym) {
+	case "(NUMBER)":
+		i, _ := strconv.ParseFloat(t.Value, 64)
+		return pointDistribution(i), nil
+	case "-H", "-L", "(IDENT)":
+		return pointDistribution(0), nil
+	case "D":
+		return t.diceDistribution(budget)
+	case "+", "-", "*", "/", "^":
+		return t.arithmeticDistribution(budget)
+	case "%":
+		if len(t.Children) < 1 {
+			return nil, errors.NewDicelangError("Invalid percentage", errors.InvalidAST, nil)
+		}
+		d, err := t.Children[0].distribution(budget)
+		if err != nil {
+			return nil, err
+		}
+		return combine(budget, d, pointDistribution(100), func(x, y float64) float64 { return x / y })
+	case "DC", "NEEDED":
+		x, err := t.threshold(&DiceSet{budget: budget})
+		if err != nil {
+			return nil, err
+		}
+		return pointDistribution(x), nil
+	case "DPR":
+		a, err := t.attack(&DiceSet{budget: budget})
+		if err != nil {
+			return nil, err
+		}
+		d, err := a.distribution(budget)
+		if err != nil {
+			return nil, err
+		}
+		return pointDistribution(d.Mean()), nil
+	case "{", "ROLL", "(ROOTNODE)":
+		out := pointDistribution(0)
+		for _, c := range t.Children {
+			d, err := c.distribution(budget)
+			if err != nil {
+				return nil, err
+			}
+			out, err = combine(budget, out, d, add)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	case "REP":
+		if len(t.Children) < 2 {
+			return nil, errors.NewDicelangError("Invalid repetition", errors.InvalidAST, nil)
+		}
+		reps, err := t.Children[1].distribution(budget)
+		if err != nil {
+			return nil, err
+		}
+		d, err := t.Children[0].distribution(budget)
+		if err != nil {
+			return nil, err
+		}
+		return mixture(budget, reps, func(n float64) (Distribution, error) {
+			return repeat(budget, d, int(n))
+		})
+	case "IF":
+		if len(t.Children) < 2 {
+			return nil, errors.NewDicelangError("Invalid if", errors.InvalidAST, nil)
+		}
+		p, err := t.Children[0].probabilityTrue(budget)
+		if err != nil {
+			return nil, err
+		}
+		yes, err := t.Children[1].distribution(budget)
+		if err != nil {
+			return nil, err
+		}
+		no := pointDistribution(0)
+		if len(t.Children) > 2 {
+			no, err = t.Children[2].distribution(budget)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return mixture(budget, Distribution{1: p, 0: 1 - p}, func(branch float64) (Distribution, error) {
+			if branch == 1 {
+				return yes, nil
+			}
+			return no, nil
+		})
+	default:
+		return nil, errors.Newf("Unsupported symbol: %s", t.Sym)
+	}
+}
+
+func (t *AST) diceDistribution(budget *Budget) (Distribution, error) {
+	var operands []Distribution
+	H, L := pointDistribution(0), pointDistribution(0)
+	for _, c := range t.Children {
+		var d Distribution
+		var err error
+		switch c.Sym {
+		case "(IDENT)":
+			continue
+		case "-H", "-L":
+			if len(c.Children) < 1 {
+				return nil, errors.NewDicelangError("Invalid drop", errors.InvalidAST, nil)
+			}
+			if c.Sym == "-H" {
+				H, err = c.Children[0].distribution(budget)
+			} else {
+				L, err = c.Children[0].distribution(budget)
+			}
+		default:
+			d, err = c.distribution(budget)
+			operands = append(operands, d)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(operands) < 2 {
+		return nil, errors.NewDicelangError("Invalid dice", errors.InvalidAST, nil)
+	}
+	return mixture(budget, operands[0], func(count float64) (Distribution, error) {
+		return mixture(budget, operands[1], func(sides float64) (Distribution, error) {
+			return mixture(budget, H, func(h float64) (Distribution, error) {
+				return mixture(budget, L, func(l float64) (Distribution, error) {
+					if err := budget.spendWork(diceCost(int64(count), int64(sides), int64(h), int64(l))); err != nil {
+						return nil, err
+					}
+					return DiceDistribution(int64(count), int64(sides), int64(h), int64(l))
+				})
+			})
+		})
+	})
+}
+
+func (t *AST) arithmeticDistribution(budget *Budget) (Distribution, error) {
+	var operands []Distribution
+	for _, c := range t.Children {
+		if c.Sym == "(IDENT)" {
+			continue
+		}
+		d, err := c.distribution(budget)
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, d)
+	}
+	if len(operands) == 0 {
+		return nil, errors.NewDicelangError("Invalid arithmetic", errors.InvalidAST, nil)
+	}
+	if t.Sym == "-" && len(operands) == 1 {
+		return combine(budget, pointDistribution(0), operands[0], func(x, y float64) float64 { return x - y })
+	}
+	var op func(x, y float64) float64
+	switch t.Sym {
+	case "+":
+		op = add
+	case "-":
+		op = func(x, y float64) float64 { return x - y }
+	case "*":
+		op = func(x, y float64) float64 { return x * y }
+	case "/":
+		op = func(x, y float64) float64 { return x / y }
+	case "^":
+		op = math.Pow
+	}
+	out := operands[0]
+	var err error
+	for _, d := range operands[1:] {
+		out, err = combine(budget, out, d, op)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+//probabilityTrue returns the chance a boolean expression evaluates to true
+func (t *AST) probabilityTrue(budget *Budget) (float64, error) {
+	if len(t.Children) < 2 {
+		return 0, errors.New("Bad bool")
+	}
+	left, err := t.Children[0].distribution(budget)
+	if err != nil {
+		return 0, err
+	}
+	right, err := t.Children[1].distribution(budget)
+	if err != nil {
+		return 0, err
+	}
+	var compare func(x, y float64) bool
+	switch t.Sym {
+	case ">":
+		compare = func(x, y float64) bool { return x > y }
+	case "<":
+		compare = func(x, y float64) bool { return x < y }
+	case "<=":
+		compare = func(x, y float64) bool { return x <= y }
+	case ">=":
+		compare = func(x, y float64) bool { return x >= y }
+	case "==":
+		compare = func(x, y float64) bool { return x == y }
+	case "!=":
+		compare = func(x, y float64) bool { return x != y }
+	default:
+		return 0, errors.New("Bad bool")
+	}
+	if err := budget.spendWork(float64(len(left)) * float64(len(right))); err != nil {
+		return 0, err
+	}
+	var p float64
+	for x, px := range left {
+		for y, py := range right {
+			if compare(x, y) {
+				p += px * py
+			}
+		}
+	}
+	return p, nil
+}
+
+//threshold solves DC(expression, probability) and NEEDED(expression, probability).
+//DC returns the highest target the expression meets or beats with the given probability,
+//NEEDED returns the lowest target the expression stays at or under with the given probability.
+func (t *AST) threshold(ds *DiceSet) (float64, error) {
+	if len(t.Children) < 2 {
+		return 0, errors.NewDicelangError(fmt.Sprintf("Invalid %s", strings.ToLower(t.Value)), errors.InvalidAST, nil)
+	}
+	p, _, err := t.Children[1].eval(ds)
+	if err != nil {
+		return 0, err
+	}
+	if p <= 0 || p > 1 {
+		return 0, errors.NewDicelangError(fmt.Sprintf("%s needs a probability between 0 and 1, or a percentage like 65%%", strings.ToLower(t.Value)), errors.Friendly, nil)
+	}
+	d, err := t.Children[0].distribution(ds.budget.orUnlimited())
+	if err != nil {
+		return 0, err
+	}
+	if t.Sym == "DC" {
+		return d.DifficultyClass(p), nil
+	}
+	return d.Percentile(p), nil
+}
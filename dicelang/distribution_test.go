@@ -0,0 +1,223 @@
+package dicelang
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAST_Distribution(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  string
+		want map[int64]float64
+	}{
+		{
+			name: "1d6+1d6 is 2d6",
+			cmd:  "1d6+1d6",
+			want: DiceProbability(2, 6, 0, 0)},
+		{
+			name: "drop lowest",
+			cmd:  "roll 4d6-L",
+			want: DiceProbability(4, 6, 0, 1)},
+		{
+			name: "arithmetic",
+			cmd:  "(1d4+1)*2",
+			want: map[int64]float64{4: 25, 6: 25, 8: 25, 10: 25}},
+		{
+			name: "rep",
+			cmd:  "1d2 rep 3",
+			want: map[int64]float64{3: 12.5, 4: 37.5, 5: 37.5, 6: 12.5}},
+		{
+			name: "colors",
+			cmd:  "1d4 fire and 3 ice",
+			want: map[int64]float64{4: 25, 5: 25, 6: 25, 7: 25}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := NewParser(tt.cmd).testStatements().Distribution()
+			if err != nil {
+				t.Fatalf("AST.Distribution() error = %v", err)
+			}
+			got := make(map[int64]float64)
+			for k, v := range d {
+				got[int64(k)] = v * 100
+			}
+			if !deepEqualFloatMap(got, tt.want) {
+				t.Errorf("AST.Distribution() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDistribution_Stats(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  string
+		want Stats
+	}{
+		{
+			name: "1d20",
+			cmd:  "1d20",
+			want: Stats{Mean: 10.5, StdDev: 5.766281297335398, Percentile5: 1, Percentile50: 10, Percentile95: 19}},
+		{
+			name: "3d6+3",
+			cmd:  "3d6+3",
+			want: Stats{Mean: 13.5, StdDev: 2.958039891549808, Percentile5: 9, Percentile50: 13, Percentile95: 18}},
+		{
+			name: "constant",
+			cmd:  "5",
+			want: Stats{Mean: 5, StdDev: 0, Percentile5: 5, Percentile50: 5, Percentile95: 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := NewParser(tt.cmd).testStatements().Distribution()
+			if err != nil {
+				t.Fatalf("AST.Distribution() error = %v", err)
+			}
+			got := d.Stats()
+			if !floatEquals(got.Mean, tt.want.Mean) || !floatEquals(got.StdDev, tt.want.StdDev) ||
+				got.Percentile5 != tt.want.Percentile5 || got.Percentile50 != tt.want.Percentile50 || got.Percentile95 != tt.want.Percentile95 {
+				t.Errorf("Distribution.Stats() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDistribution_AtLeast(t *testing.T) {
+	d, err := DiceDistribution(1, 20, 0, 0)
+	if err != nil {
+		t.Fatalf("DiceDistribution() error = %v", err)
+	}
+	if got := d.AtLeast(17); !floatEquals(got, .2) {
+		t.Errorf("Distribution.AtLeast() = %v, want %v", got, .2)
+	}
+	if got := d.AtMost(17); !floatEquals(got, .85) {
+		t.Errorf("Distribution.AtMost() = %v, want %v", got, .85)
+	}
+}
+
+func TestDiceDistribution_TooComplex(t *testing.T) {
+	if _, err := DiceDistribution(1000, 1000, 0, 0); err == nil {
+		t.Errorf("DiceDistribution() expected an error for 1000d1000")
+	}
+}
+
+func TestAST_Distribution_Malformed(t *testing.T) {
+	one := &AST{Sym: "(NUMBER)", Value: "1"}
+	comparison := &AST{Sym: ">", Value: ">", Children: []*AST{one, one}}
+	for _, root := range []*AST{
+		{Sym: "%", Value: "%"},
+		{Sym: "REP", Value: "REP", Children: []*AST{one}},
+		{Sym: "IF", Value: "IF", Children: []*AST{comparison}},
+		{Sym: "IF", Value: "IF"},
+		{Sym: "D", Value: "d", Children: []*AST{one, one, {Sym: "-H", Value: "-H"}}},
+	} {
+		if _, err := root.Distribution(); err == nil {
+			t.Errorf("AST.Distribution() of %s error = nil, want an error", astString(root))
+		}
+	}
+}
+
+func TestAST_DistributionWithBudget(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		name   string
+		cmd    string
+		budget *Budget
+	}{
+		{"quadratic repetition", "(1d2 rep 3000)^1", nil},
+		{"threshold", "dc((1d2 rep 3000), 50%)", nil},
+		{"cancelled", "1d20", NewBudget(cancelled, Limits{})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			_, err := NewParser(tt.cmd).testStatements().DistributionWithBudget(tt.budget)
+			if err == nil {
+				t.Fatalf("AST.DistributionWithBudget() expected an error")
+			}
+			if took := time.Since(start); took > time.Second {
+				t.Errorf("AST.DistributionWithBudget() took %v to give up", took)
+			}
+		})
+	}
+}
+
+func TestAST_DistributionWithBudget_Shared(t *testing.T) {
+	budget := NewBudget(context.Background(), Limits{})
+	root := NewParser("1000d6").testStatements()
+	if _, err := root.DistributionWithBudget(budget); err != nil {
+		t.Fatalf("AST.DistributionWithBudget() error = %v", err)
+	}
+	if _, err := root.DistributionWithBudget(budget); err == nil {
+		t.Errorf("AST.DistributionWithBudget() should run out of work on the second distribution")
+	}
+}
+
+func TestAST_Threshold(t *testing.T) {
+	tests := []struct {
+		name    string
+		cmd     string
+		want    float64
+		wantErr bool
+	}{
+		{
+			name: "dc with percentage",
+			cmd:  "dc(1d20+7, 65%)",
+			want: 15},
+		{
+			name: "dc with fraction",
+			cmd:  "dc(1d20, 0.5)",
+			want: 11},
+		{
+			name: "needed",
+			cmd:  "needed(3d6, 0.9)",
+			want: 14},
+		{
+			name: "needed with color",
+			cmd:  "roll needed(3d6, 0.9) fire",
+			want: 14},
+		{
+			name: "used in arithmetic",
+			cmd:  "dc(1d20, 100%) + 1",
+			want: 2},
+		{
+			name:    "probability out of range",
+			cmd:     "dc(1d20, 2)",
+			wantErr: true},
+		{
+			name:    "missing argument",
+			cmd:     "dc(1d20)",
+			wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := NewParser(tt.cmd).Statements()
+			if err == nil {
+				var got float64
+				got, _, err = root.GetDiceSet()
+				if err == nil && got != tt.want {
+					t.Errorf("AST.GetDiceSet() = %v, want %v", got, tt.want)
+				}
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDistribution_DifficultyClass(t *testing.T) {
+	d, err := DiceDistribution(1, 20, 0, 0)
+	if err != nil {
+		t.Fatalf("DiceDistribution() error = %v", err)
+	}
+	if got := d.DifficultyClass(.65); got != 8 {
+		t.Errorf("Distribution.DifficultyClass() = %v, want %v", got, 8)
+	}
+	if got := d.Percentile(.65); got != 13 {
+		t.Errorf("Distribution.Percentile() = %v, want %v", got, 13)
+	}
+}
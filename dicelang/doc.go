@@ -0,0 +1,40 @@
+//Package dicelang parses and rolls dice expressions, like "roll 1d20+5 fire, 2d6 cold".
+//
+//Evaluate is the simplest way to roll some source:
+//
+//	res, err := dicelang.Evaluate(ctx, "roll 4d6-L", dicelang.WithSeed(42))
+//
+//Parser, AST and DiceSet give finer control, and Register adds house rules to the language.
+//Compile turns an AST into a Program, which runs the same rolls without walking the tree.
+//It is only modestly faster, 1.2 to 1.5 times in this package's benchmarks, so it pays off only for a statement
+//run many thousands of times, as a simulation does. Rolling a command once should evaluate its AST.
+//An AST or Program may be evaluated by several goroutines at once, as long as each evaluation has its own DiceSet,
+//and EvalEach rolls independent statements at the same time.
+//
+//Lists
+//
+//"[4d6dl1; 6]" rolls 4d6dl1 six times into a list, as "[1d20, 1d20 + 5]" lists each of its values.
+//sort orders a list from highest to lowest, sum and max reduce it to a number, and "list[1]" is its first value.
+//Where a number is needed a list is the sum of its values. AST.GetList returns every value.
+//Analyze and Distribution don't support lists yet, and return an "Unsupported symbol" error for any expression with one.
+//
+//Language versions
+//
+//Source may start with a pragma naming the version of the language it is written in, like "#dicelang 2".
+//Source without one is read as version 1, so commands saved before a version was released keep their meaning.
+//Version 2 drops dice with "dl" and "dh", as in "4d6dl1", where version 1 wrote "4d6-L1".
+//It also keeps dice with "kh" and "kl", so "4d6kh3" is "4d6dl1".
+//A roll drops its highest or its lowest dice, never both: "4d6-H1-L1" drops only the highest, in every version.
+//Migrate rewrites source in LatestVersion.
+//
+//Compatibility
+//
+//dicelang follows semantic versioning, and Version is the version of this copy.
+//Within a major version the exported API is only added to, and source that evaluates keeps evaluating
+//to the same results from the same random draws, so a seed or transcript replays the same roll.
+//Error messages, warnings, and the text rendered by String, Format and Trace may change in any release.
+//Breaking changes are released under a new major version import path, e.g. github.com/aasmall/dicemagic/dicelang/v2.
+package dicelang
+
+//Version is the semantic version of the dicelang API
+const Version = "1.0.0"
@@ -0,0 +1,145 @@
+package dicelang
+
+import (
+	"context"
+)
+
+//Option configures Evaluate. Every EvalOption and ParserOption is an Option, as are WithLimits and WithProbabilities.
+type Option interface {
+	apply(e *evaluation)
+}
+
+type evaluation struct {
+	parser        []ParserOption
+	eval          []EvalOption
+	limits        Limits
+	probabilities bool
+}
+
+func (o EvalOption) apply(e *evaluation) {
+	e.eval = append(e.eval, o)
+}
+
+func (o ParserOption) apply(e *evaluation) {
+	e.parser = append(e.parser, o)
+}
+
+type evaluationOption func(e *evaluation)
+
+func (o evaluationOption) apply(e *evaluation) {
+	o(e)
+}
+
+//WithLimits bounds the work Evaluate may do, instead of DefaultLimits
+func WithLimits(limits Limits) Option {
+	return evaluationOption(func(e *evaluation) {
+		e.limits = limits
+	})
+}
+
+//WithProbabilities works out the Distribution and Stats of every statement
+func WithProbabilities() Option {
+	return evaluationOption(func(e *evaluation) {
+		e.probabilities = true
+	})
+}
+
+//Result is the outcome of Evaluate
+type Result struct {
+	//Total is the sum of every statement
+	Total float64
+	//TotalsByColor sums every statement by color. Uncolored dice are counted under "".
+	TotalsByColor map[string]float64
+	//Statements holds the result of each statement, in order
+	Statements []StatementResult
+	//Warnings are problems found in source that still parsed
+	Warnings []Diagnostic
+	//Transcript is every number drawn, in order. Replaying it with a ReplaySource rolls the same result.
+	Transcript []Draw
+}
+
+//StatementResult is the outcome of one statement, such as "1d20+5 fire" in "roll 1d20+5 fire, 2d6 cold"
+type StatementResult struct {
+	//Source is the statement, written out again
+	Source        string
+	Total         float64
+	TotalsByColor map[string]float64
+	//Values holds every value of a statement that is a list, like "[4d6dl1; 6]", whose Total is their sum
+	Values []float64
+	//Dice holds every roll, in the order it was rolled
+	Dice []Dice
+	//Distribution and Stats are set by WithProbabilities, unless the statement is too complex to work them out
+	Distribution Distribution
+	Stats        *Stats
+	//Trace is set by WithTrace, which traces every statement as a child of its own trace
+	Trace *Trace
+}
+
+//Evaluate parses and rolls every statement in source. Dice are rolled with crypto/rand unless an option says otherwise,
+//within DefaultLimits, and evaluation stops when ctx is done. Source that does not parse returns a *ParseError.
+//Statements are rolled at the same time when the random source allows it, as EvalEach does.
+func Evaluate(ctx context.Context, source string, opts ...Option) (*Result, error) {
+	e := evaluation{limits: DefaultLimits}
+	for _, o := range opts {
+		o.apply(&e)
+	}
+	p := NewParser(source, e.parser...)
+	tree, err := p.Statements()
+	if err != nil {
+		return nil, err
+	}
+	//find the source chosen by the options, so every draw from it can be recorded
+	chosen := &DiceSet{}
+	for _, o := range e.eval {
+		o(chosen)
+	}
+	src := chosen.randomSource()
+	budget := NewBudget(ctx, e.limits)
+
+	res := &Result{TotalsByColor: make(map[string]float64), Warnings: p.Diagnostics()}
+	res.Statements = make([]StatementResult, len(tree.Children))
+	res.Transcript, err = evalEach(len(tree.Children), src, concurrent(src), func(i int, src RandomSource) error {
+		stmt := tree.Children[i]
+		evalOpts := append([]EvalOption{WithBudget(budget)}, e.eval...)
+		//each statement fills a trace of its own, so statements can be traced at the same time
+		var trace *Trace
+		if chosen.tracer != nil {
+			trace = &Trace{}
+			evalOpts = append(evalOpts, WithTrace(trace))
+		}
+		values, ds, err := stmt.GetList(append(evalOpts, WithRandomSource(src))...)
+		if err != nil {
+			return err
+		}
+		s := StatementResult{TotalsByColor: ds.TotalsByColor, Dice: ds.Dice, Trace: trace}
+		for _, x := range values {
+			s.Total += x
+		}
+		if stmt.IsList() {
+			s.Values = values
+		}
+		s.Source, _ = FormatVersion(stmt, p.Version())
+		if e.probabilities {
+			if d, err := stmt.DistributionWithBudget(budget); err == nil {
+				stats := d.Stats()
+				s.Distribution, s.Stats = d, &stats
+			}
+		}
+		res.Statements[i] = s
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range res.Statements {
+		res.Total += s.Total
+		res.TotalsByColor = MergeDiceTotalMaps(res.TotalsByColor, s.TotalsByColor)
+	}
+	if chosen.tracer != nil {
+		*chosen.tracer.root = Trace{Sym: tree.Sym, Value: tree.Value, Result: res.Total}
+		for _, s := range res.Statements {
+			chosen.tracer.root.Children = append(chosen.tracer.root.Children, s.Trace)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,94 @@
+package dicelang
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEvaluate(t *testing.T) {
+	res, err := Evaluate(context.Background(), "roll 3d6 fire, 1d4 + 2", WithSeed(7), WithProbabilities())
+	if err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+	if len(res.Statements) != 2 {
+		t.Fatalf("Evaluate() statements = %d, want 2", len(res.Statements))
+	}
+	if got := res.Statements[0].Total + res.Statements[1].Total; got != res.Total {
+		t.Errorf("Evaluate() total = %v, want the sum of its statements %v", res.Total, got)
+	}
+	if res.Statements[1].Stats == nil || res.Statements[1].Stats.Mean != 4.5 {
+		t.Errorf("Evaluate() stats = %+v, want a mean of 4.5", res.Statements[1].Stats)
+	}
+	if len(res.Transcript) != 4 {
+		t.Errorf("Evaluate() transcript = %d draws, want 4", len(res.Transcript))
+	}
+	replayed, err := Evaluate(context.Background(), "roll 3d6 fire, 1d4 + 2", WithRandomSource(NewReplaySource(res.Transcript)))
+	if err != nil {
+		t.Fatalf("Evaluate() replay error = %v", err)
+	}
+	if replayed.Total != res.Total {
+		t.Errorf("Evaluate() replayed = %v, want %v", replayed.Total, res.Total)
+	}
+}
+
+func TestEvaluate_Limits(t *testing.T) {
+	_, err := Evaluate(context.Background(), "roll 10d6 rep 10", WithLimits(Limits{MaxDraws: 50, Timeout: time.Second}))
+	if err == nil {
+		t.Errorf("Evaluate() error = nil, want the budget spent")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Evaluate(ctx, "roll 1d6"); err == nil {
+		t.Errorf("Evaluate() error = nil, want the context's error")
+	}
+}
+
+func TestEvaluate_Warnings(t *testing.T) {
+	res, err := Evaluate(context.Background(), "1d20 adavntage", WithKnownWords("advantage"), WithSeed(1))
+	if err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+	if len(res.Warnings) != 1 {
+		t.Errorf("Evaluate() warnings = %+v, want 1", res.Warnings)
+	}
+}
+
+func TestEvaluate_Source(t *testing.T) {
+	res, err := Evaluate(context.Background(), "#dicelang 2\n4d6dl1", WithSeed(1))
+	if err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+	if got := res.Statements[0].Source; got != "4d6dl1" {
+		t.Errorf("Evaluate() source = %q, want it written in the version it was parsed as", got)
+	}
+}
+
+func TestEvaluate_Trace(t *testing.T) {
+	var tr Trace
+	res, err := Evaluate(context.Background(), "roll 1d6, 1d8 + 2, 1d10", WithSeed(3), WithTrace(&tr))
+	if err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+	if len(tr.Children) != 3 {
+		t.Fatalf("Evaluate() traced %d statements, want 3", len(tr.Children))
+	}
+	for i, s := range res.Statements {
+		if tr.Children[i] != s.Trace || s.Trace.Result != s.Total {
+			t.Errorf("Evaluate() statement %d trace = %+v, want its result %v", i, s.Trace, s.Total)
+		}
+	}
+	if tr.Result != res.Total {
+		t.Errorf("Evaluate() trace result = %v, want %v", tr.Result, res.Total)
+	}
+}
+
+func TestEvaluate_ProbabilitiesWithinBudget(t *testing.T) {
+	res, err := Evaluate(context.Background(), "1000d6, 1000d6", WithProbabilities(), WithSeed(1))
+	if err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+	if res.Statements[0].Stats != nil && res.Statements[1].Stats != nil {
+		t.Errorf("Evaluate() worked out both distributions, want them to share one budget")
+	}
+}
@@ -0,0 +1,34 @@
+package dicelang_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aasmall/dicemagic/dicelang"
+)
+
+func ExampleEvaluate() {
+	res, err := dicelang.Evaluate(context.Background(), "roll 1d20+5 fire, 2d6 cold", dicelang.WithSeed(42))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, s := range res.Statements {
+		fmt.Printf("%s: %v\n", s.Source, s.Total)
+	}
+	fmt.Println(res.Total == res.TotalsByColor["Fire"]+res.TotalsByColor["Cold"])
+	// Output:
+	// roll 1d20 + 5 Fire: 11
+	// 2d6 Cold: 9
+	// true
+}
+
+func ExampleEvaluate_parseError() {
+	_, err := dicelang.Evaluate(context.Background(), "1d20 + + 5")
+	if parseErr, ok := err.(*dicelang.ParseError); ok {
+		fmt.Print(parseErr.Caret())
+	}
+	// Output:
+	// 1d20 + + 5
+	//        ^ unexpected '+'
+}
@@ -0,0 +1,59 @@
+package dicelang
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+)
+
+//FairSource is a RandomSource for provably fair rolls.
+//Every number is derived from HMAC-SHA256(serverSeed, "clientSeed:nonce:round"), so once the server seed is revealed
+//anyone can recompute every roll, and compare the server seed to the commitment published before rolling.
+type FairSource struct {
+	serverSeed []byte
+	clientSeed string
+	nonce      int64
+	round      int64
+	buffer     []byte
+}
+
+//NewFairSource returns a FairSource for a single roll. Every roll in a session should use a new nonce.
+func NewFairSource(serverSeed string, clientSeed string, nonce int64) *FairSource {
+	return &FairSource{serverSeed: []byte(serverSeed), clientSeed: clientSeed, nonce: nonce}
+}
+
+//FairCommitment returns the hex encoded SHA-256 of serverSeed, which is published before rolling
+func FairCommitment(serverSeed string) string {
+	sum := sha256.Sum256([]byte(serverSeed))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *FairSource) next() uint64 {
+	if len(s.buffer) < 8 {
+		h := hmac.New(sha256.New, s.serverSeed)
+		fmt.Fprintf(h, "%s:%d:%d", s.clientSeed, s.nonce, s.round)
+		s.buffer = h.Sum(nil)
+		s.round++
+	}
+	v := binary.BigEndian.Uint64(s.buffer[:8])
+	s.buffer = s.buffer[8:]
+	return v
+}
+
+//Int63n returns a uniformly distributed number in [0, n)
+func (s *FairSource) Int63n(n int64) (int64, error) {
+	if n <= 0 {
+		return 0, fmt.Errorf("Cannot make a random int of size zero")
+	}
+	// reject the values that would bias the result towards low numbers
+	bound := uint64(n)
+	threshold := -bound % bound
+	for {
+		r := s.next()
+		if r >= threshold {
+			return int64(r % bound), nil
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package dicelang
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFairCommitment(t *testing.T) {
+	if got, want := FairCommitment("abc"), "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"; got != want {
+		t.Errorf("FairCommitment() = %v, want %v", got, want)
+	}
+}
+
+func TestFairSource(t *testing.T) {
+	rollWith := func(serverSeed, clientSeed string, nonce int64) []Dice {
+		_, ds, err := NewParser("roll 10d20 and 8d6").testStatements().GetDiceSet(WithRandomSource(NewFairSource(serverSeed, clientSeed, nonce)))
+		if err != nil {
+			t.Fatalf("AST.GetDiceSet() error = %v", err)
+		}
+		return ds.Dice
+	}
+	first := rollWith("server", "client", 1)
+	if again := rollWith("server", "client", 1); !reflect.DeepEqual(first, again) {
+		t.Errorf("same seeds rolled %+v and %+v", first, again)
+	}
+	if other := rollWith("server", "client", 2); reflect.DeepEqual(first, other) {
+		t.Errorf("a new nonce rolled the same dice: %+v", first)
+	}
+	if other := rollWith("server", "another client", 1); reflect.DeepEqual(first, other) {
+		t.Errorf("a new client seed rolled the same dice: %+v", first)
+	}
+	if other := rollWith("another server", "client", 1); reflect.DeepEqual(first, other) {
+		t.Errorf("a new server seed rolled the same dice: %+v", first)
+	}
+}
+
+func TestFairSource_Int63n(t *testing.T) {
+	src := NewFairSource("server", "client", 1)
+	m := make(map[int64]int)
+	for i := 0; i < 60000; i++ {
+		x, err := src.Int63n(6)
+		if err != nil {
+			t.Fatalf("FairSource.Int63n() error = %v", err)
+		}
+		m[x]++
+	}
+	if len(m) != 6 {
+		t.Fatalf("FairSource.Int63n() returned %v", m)
+	}
+	for k, v := range m {
+		if v < 9000 || v > 11000 {
+			t.Errorf("FairSource.Int63n() returned %d %d times out of 60000", k, v)
+		}
+	}
+}
@@ -0,0 +1,364 @@
+package dicelang
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/aasmall/dicemagic/dicelang/errors"
+)
+
+//primary is the binding power of text nothing can split, such as a number or anything in parentheses
+const primary = 1000
+
+//infixOperators maps the symbol of every left associative infix operator to its binding power and source text
+var infixOperators = map[string]struct {
+	bp   int
+	text string
+}{
+	"REP": {20, "rep"},
+	"<":   {30, "<"},
+	">":   {30, ">"},
+	"<=":  {30, "<="},
+	">=":  {30, ">="},
+	"==":  {30, "=="},
+	"!=":  {30, "!="},
+	"+":   {50, "+"},
+	"-":   {50, "-"},
+	"*":   {60, "*"},
+	"/":   {60, "/"},
+	"^":   {70, "^"},
+	"MOD": {95, "mod"},
+}
+
+//formatted is the source of an expression, with what the parser needs to know to keep it whole
+type formatted struct {
+	s string
+	//tail is the binding power its last operand was parsed with. Any operator binding tighter would steal that operand.
+	tail int
+	//min is the loosest binding power of the operators holding it together
+	min int
+	//ident is set when it ends with a color, which would swallow a "d" that follows
+	ident bool
+}
+
+func (f formatted) wrap() formatted {
+	return formatted{s: "(" + f.s + ")", tail: primary, min: primary}
+}
+
+//formatter writes source in one version of the language
+type formatter struct {
+	version int
+}
+
+//Format returns canonical source for an AST. The source is minimally parenthesized and parses to an identical AST.
+func Format(t *AST) (string, error) {
+	return FormatVersion(t, 1)
+}
+
+//FormatVersion returns canonical source for an AST in a version of the language. It does not write a pragma.
+func FormatVersion(t *AST, version int) (string, error) {
+	if t == nil {
+		return "", nil
+	}
+	fm := formatter{version: version}
+	if strings.ToUpper(t.Sym) == "(ROOTNODE)" {
+		return fm.formatStatements(t.Children)
+	}
+	return fm.formatStatement(t)
+}
+
+//FormatSource parses source and returns its canonical form, in the version of the language it was written in
+func FormatSource(source string) (string, error) {
+	parse := NewParser(source)
+	root, err := parse.Statements()
+	if err != nil {
+		return "", err
+	}
+	s, err := FormatVersion(root, parse.Version())
+	if err != nil || !parse.pragma {
+		return s, err
+	}
+	return Pragma(parse.Version()) + "\n" + s, nil
+}
+
+func (fm formatter) formatStatements(stmts []*AST) (string, error) {
+	var b strings.Builder
+	for i, stmt := range stmts {
+		s, err := fm.formatStatement(stmt)
+		if err != nil {
+			return "", err
+		}
+		if i > 0 {
+			//a comma is only consumed by the expression before it, and only ends a statement if nothing after it continues that expression
+			if endsInExpression(stmts[i-1]) && !fm.continuesExpression(s) {
+				b.WriteString(", ")
+			} else {
+				b.WriteString("\n")
+			}
+		}
+		b.WriteString(s)
+	}
+	return b.String(), nil
+}
+
+func (fm formatter) formatStatement(t *AST) (string, error) {
+	switch strings.ToUpper(t.Sym) {
+	case "ROLL":
+		if len(t.Children) == 0 {
+			return "roll", nil
+		}
+		s, err := fm.formatStatement(t.Children[0])
+		if err != nil {
+			return "", err
+		}
+		return "roll " + s, nil
+	case "{":
+		s, err := fm.formatStatements(t.Children)
+		if err != nil {
+			return "", err
+		}
+		if s == "" {
+			return "{}", nil
+		}
+		return "{ " + s + " }", nil
+	case "IF":
+		if !isIfStatement(t) {
+			break
+		}
+		cond, err := fm.formatExpression(t.Children[0])
+		if err != nil {
+			return "", err
+		}
+		var parts []string
+		for _, c := range t.Children[1:] {
+			s, err := fm.formatStatement(c)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, s)
+		}
+		return fmt.Sprintf("if %s %s", cond.s, strings.Join(parts, " else ")), nil
+	}
+	f, err := fm.formatExpression(t)
+	if err != nil {
+		return "", err
+	}
+	return f.s, nil
+}
+
+//isIfStatement reports whether t is "if cond { ... }" rather than "a if cond else b"
+func isIfStatement(t *AST) bool {
+	return strings.ToUpper(t.Sym) == "IF" && len(t.Children) > 1 && t.Children[1].Sym == "{"
+}
+
+func endsInExpression(t *AST) bool {
+	switch {
+	case strings.ToUpper(t.Sym) == "ROLL" && len(t.Children) > 0:
+		return endsInExpression(t.Children[0])
+	case t.Sym == "{", isIfStatement(t):
+		return false
+	}
+	return true
+}
+
+//continuesExpression reports whether the first token of s would continue an expression before it, like "(" or "-"
+func (fm formatter) continuesExpression(s string) bool {
+	l := NewLexer(s)
+	l.version = fm.version
+	tok, err := l.next()
+	return err != nil || tok.BindingPower > 0
+}
+
+func isExtra(t *AST) bool {
+	return t.Sym == "(IDENT)" || t.Sym == "-L" || t.Sym == "-H"
+}
+
+func (fm formatter) formatExpression(t *AST) (formatted, error) {
+	var operands, extras []*AST
+	for _, c := range t.Children {
+		if isExtra(c) {
+			extras = append(extras, c)
+		} else {
+			operands = append(operands, c)
+		}
+	}
+	f, err := fm.formatOperator(t, operands)
+	if err != nil {
+		return f, err
+	}
+	//colors and drops attach to whatever was parsed last, so the expression is wrapped unless it was parsed last
+	for _, e := range extras {
+		switch e.Sym {
+		case "(IDENT)":
+			//there is no way to spell such a color in version 2, where it is read as a keep or drop
+			if fm.version > 1 && keepsOrDrops(e.Value) {
+				return f, errors.NewDicelangError(fmt.Sprintf("The color '%s' would be read as a keep or drop in version %d, so it needs a new name", e.Value, fm.version), errors.Friendly, nil)
+			}
+			if f.tail < primary {
+				f = f.wrap()
+			}
+			f.s += " " + e.Value
+			f.ident = true
+		default:
+			if len(e.Children) != 1 {
+				return f, invalidFormat(e)
+			}
+			n, err := fm.formatExpression(e.Children[0])
+			if err != nil {
+				return f, err
+			}
+			if !startsWithDigit(n.s) || n.min <= 80 {
+				return f, invalidFormat(e)
+			}
+			if f.tail < 80 {
+				f = f.wrap()
+			}
+			f.s += fm.drop(e.Sym, f.ident) + n.s
+			f.ident = n.ident
+			f.tail = 80
+			f.min = minInt(f.min, 80)
+		}
+	}
+	return f, nil
+}
+
+//drop returns the source text of a drop, following an expression that may end with a color
+func (fm formatter) drop(sym string, ident bool) string {
+	if fm.version == 1 {
+		return sym
+	}
+	//"dl" would otherwise be read as part of the color
+	s := strings.ToLower(strings.Replace(sym, "-", "d", 1))
+	if ident {
+		return " " + s
+	}
+	return s
+}
+
+func (fm formatter) formatOperator(t *AST, operands []*AST) (formatted, error) {
+	sym := strings.ToUpper(t.Sym)
+	args, err := fm.formatAll(operands)
+	if err != nil {
+		return formatted{}, err
+	}
+	switch {
+	case sym == "(NUMBER)" && len(args) == 0:
+		return formatted{s: t.Value, tail: primary, min: primary}, nil
+	case sym == "D" && len(args) == 2:
+		count, sides := args[0], args[1]
+		if strings.ToUpper(operands[1].Sym) != "(NUMBER)" || !startsWithDigit(sides.s) {
+			//"d" only lexes as an operator when a digit follows
+			return formatted{}, invalidFormat(t)
+		}
+		if count.tail < 80 {
+			count = count.wrap()
+		}
+		if count.ident {
+			count.s += " "
+		}
+		return formatted{s: count.s + "d" + sides.s, tail: 80, min: minInt(80, count.min), ident: sides.ident}, nil
+	case sym == "-" && len(args) == 1:
+		x := args[0]
+		if x.min <= 200 {
+			x = x.wrap()
+		}
+		return formatted{s: "-" + x.s, tail: 200, min: primary, ident: x.ident}, nil
+	case sym == "%" && len(args) == 1:
+		x := args[0]
+		if x.tail < 75 {
+			x = x.wrap()
+		}
+		return formatted{s: x.s + "%", tail: primary, min: minInt(75, x.min)}, nil
+	case sym == "IF" && len(args) == 3:
+		cond, x, y := args[0], args[1], args[2]
+		if x.tail < 20 {
+			x = x.wrap()
+		}
+		return formatted{s: fmt.Sprintf("%s if %s else %s", x.s, cond.s, y.s), tail: 0, min: minInt(20, x.min), ident: y.ident}, nil
+	case sym == "DC" || sym == "NEEDED" || sym == "DPR" || sym == "SORT" || sym == "SUM" || sym == "MAX":
+		return formatted{s: fmt.Sprintf("%s(%s)", strings.ToLower(t.Value), joinFormatted(separated(args), ", ")), tail: primary, min: primary}, nil
+	case sym == "[" && len(args) > 0:
+		return formatted{s: "[" + joinFormatted(separated(args), ", ") + "]", tail: primary, min: primary}, nil
+	case sym == "(ARRAY)" && len(args) == 2:
+		args = separated(args)
+		return formatted{s: fmt.Sprintf("[%s; %s]", args[0].s, args[1].s), tail: primary, min: primary}, nil
+	case sym == "(INDEX)" && len(args) == 2:
+		left := args[0]
+		if left.tail < 90 {
+			left = left.wrap()
+		}
+		return formatted{s: fmt.Sprintf("%s[%s]", left.s, args[1].s), tail: primary, min: minInt(90, left.min)}, nil
+	case sym == "(" && len(args) > 0:
+		left := args[0]
+		if left.tail < 90 {
+			left = left.wrap()
+		}
+		return formatted{s: fmt.Sprintf("%s(%s)", left.s, joinFormatted(args[1:], ", ")), tail: primary, min: minInt(90, left.min)}, nil
+	}
+	if op, ok := infixOperators[sym]; ok && len(args) == 2 {
+		left, right := args[0], args[1]
+		if left.tail < op.bp {
+			left = left.wrap()
+		}
+		if right.min <= op.bp {
+			right = right.wrap()
+		}
+		return formatted{s: fmt.Sprintf("%s %s %s", left.s, op.text, right.s), tail: op.bp, min: minInt(op.bp, left.min), ident: right.ident}, nil
+	}
+	return formatted{}, invalidFormat(t)
+}
+
+func (fm formatter) formatAll(nodes []*AST) ([]formatted, error) {
+	var out []formatted
+	for _, n := range nodes {
+		f, err := fm.formatExpression(n)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+//separated wraps any of args which would swallow the "," or ";" after it, as arguments and values are parsed tighter
+func separated(args []formatted) []formatted {
+	for i := range args {
+		if args[i].min <= 25 {
+			args[i] = args[i].wrap()
+		}
+	}
+	return args
+}
+
+func joinFormatted(fs []formatted, sep string) string {
+	var s []string
+	for _, f := range fs {
+		s = append(s, f.s)
+	}
+	return strings.Join(s, sep)
+}
+
+func startsWithDigit(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return unicode.IsDigit(r)
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func invalidFormat(t *AST) error {
+	return errors.NewDicelangError(fmt.Sprintf("Cannot format %q with %d arguments", t.Value, len(t.Children)), errors.InvalidAST, nil)
+}
@@ -0,0 +1,164 @@
+package dicelang
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+func TestFormatSource(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"spacing", "roll 1d20+5", "roll 1d20 + 5"},
+		{"colors are titled", "roll 1d20 fire", "roll 1d20 Fire"},
+		{"words are numbers", "roll two d6", "roll 2d6"},
+		{"drops are explicit", "4d6-L", "4d6-L1"},
+		{"needed parentheses", "(2d6+3)*2", "(2d6 + 3) * 2"},
+		{"redundant parentheses", "((2d6))+(3*2)", "2d6 + 3 * 2"},
+		{"left associative", "1 - (2 - 3) - 4", "1 - (2 - 3) - 4"},
+		{"unary minus", "-(1d4)+-2", "-(1d4) + -2"},
+		{"percent", "(2d6+1)% + 50%", "(2d6 + 1)% + 50%"},
+		{"colored expression", "(1d4+1) ice", "(1d4 + 1) Ice"},
+		{"colored dice", "(3d6-L1) fire", "(3d6-L1) Fire"},
+		{"statements", "roll 1d4 fire and 1d8 ice", "roll 1d4 Fire, 1d8 Ice"},
+		{"statement starting with parentheses", "1d4\n(1+1)d8", "1d4\n(1 + 1)d8"},
+		{"repeat", "roll 1d20+3 rep 3", "roll 1d20 + 3 rep 3"},
+		{"if", "1d4 + 2 if 1d20 > 10 else 1d8", "1d4 + 2 if 1d20 > 10 else 1d8"},
+		{"functions", "roll DC( 1d20 + 5 , 0.5 )", "roll dc(1d20 + 5, 0.5)"},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FormatSource(tt.source)
+			if err != nil {
+				t.Fatalf("FormatSource() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("FormatSource() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormat_RoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		root := randomRoot(r)
+		source, err := Format(root)
+		if err != nil {
+			t.Fatalf("Format() error = %v for AST %s", err, astString(root))
+		}
+		parsed, err := NewParser(source).Statements()
+		if err != nil {
+			t.Fatalf("Format() = %q, which doesn't parse: %v\nAST %s", source, err, astString(root))
+		}
+		if !equalAST(root, parsed) {
+			t.Fatalf("Format() = %q, which parses to\n%s\nwant\n%s", source, astString(parsed), astString(root))
+		}
+		again, err := Format(parsed)
+		if err != nil || again != source {
+			t.Fatalf("Format() is not canonical: %q then %q (%v)", source, again, err)
+		}
+	}
+}
+
+func equalAST(a *AST, b *AST) bool {
+	if a.Sym != b.Sym || a.Value != b.Value || len(a.Children) != len(b.Children) {
+		return false
+	}
+	for i := range a.Children {
+		if !equalAST(a.Children[i], b.Children[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func astString(t *AST) string {
+	s := "(" + t.Sym + ":" + t.Value
+	for _, c := range t.Children {
+		s += " " + astString(c)
+	}
+	return s + ")"
+}
+
+func node(sym string, children ...*AST) *AST {
+	return &AST{Sym: sym, Value: sym, Children: children}
+}
+
+//"Kh3" and "Dl" are colors in version 1 that version 2 reads as a keep and a drop
+var randomColors = []string{"Fire", "Ice", "Radiant", "Acid_2", "Kh3", "Dl"}
+
+func randomNumber(r *rand.Rand) *AST {
+	value := strconv.Itoa(r.Intn(30) + 1)
+	if r.Intn(10) == 0 {
+		value += ".5"
+	}
+	return &AST{Sym: "(NUMBER)", Value: value}
+}
+
+func randomColor(r *rand.Rand) *AST {
+	return &AST{Sym: "(IDENT)", Value: randomColors[r.Intn(len(randomColors))]}
+}
+
+//randomRoot returns a random AST like the parser produces
+func randomRoot(r *rand.Rand) *AST {
+	root := &AST{Sym: "(rootnode)"}
+	for i := r.Intn(3) + 1; i > 0; i-- {
+		stmt := randomExpression(r, 4)
+		switch r.Intn(4) {
+		case 0:
+			stmt = node("ROLL", stmt)
+		case 1:
+			stmt = node("IF", node(">", randomExpression(r, 2), randomNumber(r)), node("{", randomExpression(r, 2), randomExpression(r, 2)))
+		}
+		root.Children = append(root.Children, stmt)
+	}
+	return root
+}
+
+func randomExpression(r *rand.Rand, depth int) *AST {
+	var t *AST
+	choice := 0
+	if depth > 0 {
+		choice = r.Intn(12)
+	}
+	switch choice {
+	case 0, 1:
+		t = randomNumber(r)
+	case 2, 3:
+		t = node("D", randomExpression(r, depth-1), randomNumber(r))
+		if r.Intn(5) == 0 {
+			t.Children[1].Children = append(t.Children[1].Children, randomColor(r))
+		}
+		for i := r.Intn(3); i > 0; i-- {
+			drop := node([]string{"-L", "-H"}[r.Intn(2)], randomNumber(r))
+			t.Children = append(t.Children, drop)
+		}
+	case 4:
+		t = node([]string{"+", "-", "*", "/", "^"}[r.Intn(5)], randomExpression(r, depth-1), randomExpression(r, depth-1))
+	case 5:
+		t = node([]string{"+", "-"}[r.Intn(2)], randomExpression(r, depth-1), randomExpression(r, depth-1))
+	case 6:
+		t = node("-", randomExpression(r, depth-1))
+	case 7:
+		t = node("%", randomExpression(r, depth-1))
+	case 8:
+		//"!=" is never lexed, because "!" is not an operator character
+		cond := node([]string{"<", ">", "<=", ">=", "=="}[r.Intn(5)], randomExpression(r, depth-1), randomExpression(r, depth-1))
+		t = node("IF", cond, randomExpression(r, depth-1), randomExpression(r, depth-1))
+	case 9:
+		t = node("REP", randomExpression(r, depth-1), randomExpression(r, depth-1))
+	case 10:
+		t = node([]string{"DC", "NEEDED"}[r.Intn(2)], randomExpression(r, depth-1), randomExpression(r, depth-1))
+	case 11:
+		t = node("DPR", randomExpression(r, depth-1), randomExpression(r, depth-1), randomExpression(r, depth-1))
+	}
+	if r.Intn(6) == 0 {
+		t.Children = append(t.Children, randomColor(r))
+	}
+	return t
+}
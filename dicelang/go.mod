@@ -1,9 +1,8 @@
-module github.com/aasmall/dicemagic/internal/dicelang
+module github.com/aasmall/dicemagic/dicelang
 
 go 1.12
 
 require (
-	github.com/aasmall/dicemagic v0.0.0-20190306205428-6b9ac5ae3d91
 	github.com/aasmall/word2number v0.0.0-20180508050052-3e177d961031
 	gopkg.in/yaml.v2 v2.2.2 // indirect
 )
@@ -0,0 +1,970 @@
+package dicelang
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aasmall/dicemagic/dicelang/errors"
+)
+
+//Dice represents a a throw of a single type of die
+type Dice struct {
+	Count       int64
+	Sides       int64
+	Total       int64
+	Faces       []int64
+	Max         int64
+	Min         int64
+	DropHighest int64
+	DropLowest  int64
+	Color       string
+	//ExplodeDepth is the number of times a die showing its highest face is rolled again and added. 0 to never explode.
+	ExplodeDepth int64
+	//SuccessTarget counts dice showing SuccessTarget or higher instead of summing them. 0 to sum.
+	SuccessTarget int64
+	//BotchTarget subtracts a success for every die showing BotchTarget or lower. 0 for no botches.
+	BotchTarget int64
+}
+
+//DiceSet represents a collection of Dice and their totals by type.
+//It belongs to a single evaluation, and is not safe for concurrent use.
+type DiceSet struct {
+	Dice          []Dice
+	TotalsByColor map[string]float64
+	dropHighest   int64
+	dropLowest    int64
+	colors        []string
+	colorDepth    int
+	random        RandomSource
+	budget        *Budget
+	//depth is how deeply nested the node being evaluated is
+	depth    int
+	tracer   *tracer
+	simplify bool
+	//list holds the values of the list evaluated last
+	list []float64
+}
+
+type flatToken struct {
+	sym   string
+	value string
+	rbp   int
+}
+
+//PrintAST prints a formatted version of the ast to StdOut
+func PrintAST(t *AST, identation int) {
+	fmt.Println()
+	for i := 0; i < identation; i++ {
+		fmt.Print(" ")
+	}
+	fmt.Print("(")
+	fmt.Print(t.Sym, ":", t.Value)
+	if len(t.Children) > 0 {
+		for _, c := range t.Children {
+			fmt.Print(" ")
+			PrintAST(c, identation+4)
+		}
+	}
+	fmt.Print(")")
+}
+
+//ReStringAST is a modified inverse shunting yard which converts an AST back to an infix expression.
+//It returns an empty string for an AST missing an operand.
+func ReStringAST(t *AST) string {
+	var s, post Stack
+	var buff bytes.Buffer
+	ch := make(chan *AST)
+	go func() {
+		emitTokens(ch, t)
+		close(ch)
+	}()
+	//drain the channel when returning early, so emitTokens can finish
+	defer func() {
+		for range ch {
+		}
+	}()
+	for token := range ch {
+		switch token.Sym {
+		case "-":
+			//fucking unary operators
+			var err error
+			if len(token.Children) == 1 {
+				err = shuntUnary(token, &s)
+			} else {
+				err = shuntBinary(token, &s, " ")
+			}
+			if err != nil {
+				return ""
+			}
+		case "+", "*", "^", "/", "<", ">", ">=", "<=":
+			if err := shuntBinary(token, &s, " "); err != nil {
+				return ""
+			}
+		case "-L", "-H":
+			//infix no space, no paren, not worth a function
+			op1, err := popAST(&s)
+			if err != nil {
+				return ""
+			}
+			op2, err := popAST(&s)
+			if err != nil {
+				return ""
+			}
+			s.Push(&AST{
+				Value:        fmt.Sprintf("%s%s%s", op2.Value, token.Value, op1.Value),
+				Sym:          token.Sym,
+				BindingPower: token.BindingPower})
+		case "d":
+			//infix dice
+			op1, err := popAST(&s)
+			if err != nil {
+				return ""
+			}
+			op2, err := popAST(&s)
+			if err != nil {
+				return ""
+			}
+			s.Push(&AST{
+				Value:        fmt.Sprintf("%s%s%s(%%s)", op2.Value, token.Value, op1.Value),
+				Sym:          token.Sym,
+				BindingPower: token.BindingPower})
+		case "(NUMBER)":
+			//operand
+			s.Push(token)
+		case "(IDENT)":
+			//postfix
+			post.Push(token)
+		case "{":
+			buff.WriteString(token.Value + " ")
+			post.Push(&AST{Value: "}"})
+		default:
+			//prefix
+			buff.WriteString(token.Value + " ")
+		}
+	}
+	for !s.Empty() {
+		buff.WriteString(", " + s.Pop().(*AST).Value)
+	}
+	for !post.Empty() {
+		buff.WriteString(" " + post.Pop().(*AST).Value)
+	}
+	//fmt.Printf("postStack:" + stringPostfix(&post))
+
+	return "\n" + buff.String()
+}
+
+func stringPostfix(s *Stack) string {
+	var buf bytes.Buffer
+	for !s.Empty() {
+		buf.WriteString(s.Pop().(*AST).Value + ", ")
+
+	}
+	buf.WriteRune('\n')
+	return buf.String()
+}
+
+//popAST pops the operand of an operator, failing when the AST is missing one
+func popAST(s *Stack) (*AST, error) {
+	if t, ok := s.Pop().(*AST); ok {
+		return t, nil
+	}
+	return nil, errors.New("Invalid AST. Cannot convert to infix expression")
+}
+
+func shuntBinary(token *AST, s *Stack, spacer string) error {
+	op1, err := popAST(s)
+	if err != nil {
+		return err
+	}
+	op2, err := popAST(s)
+	if err != nil {
+		return err
+	}
+	if token.BindingPower > op1.BindingPower {
+		s.Push(&AST{
+			Value:        fmt.Sprintf("(%s%s%s%s%s)", op2.Value, spacer, token.Value, spacer, op1.Value),
+			Sym:          "(COMPOUND)",
+			BindingPower: token.BindingPower})
+	} else {
+		s.Push(&AST{
+			Value:        fmt.Sprintf("%s%s%s%s%s", op2.Value, spacer, token.Value, spacer, op1.Value),
+			Sym:          "(COMPOUND)",
+			BindingPower: token.BindingPower})
+	}
+	return nil
+}
+func shuntUnary(token *AST, s *Stack) error {
+	op1, err := popAST(s)
+	if err != nil {
+		return err
+	}
+	s.Push(&AST{
+		Value:        fmt.Sprintf("%s%s", token.Value, op1.Value),
+		Sym:          "(COMPOUND)",
+		BindingPower: token.BindingPower})
+	return nil
+}
+
+func shuntPostfix(token *AST, s *Stack) {
+	s.Push(token)
+}
+
+func emitTokens(ch chan *AST, t *AST) {
+	if len(t.Children) > 0 {
+		for _, c := range t.Children {
+			emitTokens(ch, c)
+		}
+	}
+	ch <- t
+}
+
+//maxRepeatedLength bounds the infix expression written out for a REP, which is repeated in full
+const maxRepeatedLength = 1 << 16
+
+// Convert AST to Infix expression
+func (token *AST) String() (string, error) {
+	var buf bytes.Buffer
+	var preStack, postStack, s, reverse Stack
+	if len(token.Children) > 0 {
+		err := token.inverseShuntingYard(&buf, &preStack, &postStack, &s, "", 0)
+		if err != nil {
+			return "", errors.NewDicelangError(err.Error(), errors.InvalidAST, err)
+		}
+		for !preStack.Empty() {
+			reverse.Push(preStack.Pop())
+		}
+		for !reverse.Empty() {
+			buf.WriteString(reverse.Pop().(*AST).Value + " ")
+		}
+		for !s.Empty() {
+			reverse.Push(s.Pop())
+		}
+		for !reverse.Empty() {
+			buf.WriteString(reverse.Pop().(*AST).Value + " ")
+		}
+		for !postStack.Empty() {
+			reverse.Push(postStack.Pop())
+		}
+		for !reverse.Empty() {
+			buf.WriteString(reverse.Pop().(*AST).Value + " ")
+		}
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// GLWT Public License
+// Copyright (c) Everyone, except Author
+//
+// The author has absolutely no clue what the code in this function does.
+// It might just work or not, there is no third option.
+//
+// Everyone is permitted to copy, distribute, modify, merge, sell, publish,
+// sublicense or whatever they want with this function but at their OWN RISK.
+//
+//
+//                 GOOD LUCK WITH THAT PUBLIC LICENSE
+//    TERMS AND CONDITIONS FOR COPYING, DISTRIBUTION, AND MODIFICATION
+//
+// 0. You just DO WHATEVER YOU WANT TO as long as you NEVER LEAVE A
+// TRACE TO TRACK THE AUTHOR of the original product to blame for or held
+// responsible.
+//
+// IN NO EVENT SHALL THE AUTHORS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY,
+// WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE FUNCTION OR THE USE OR OTHER DEALINGS IN THE FUNCTION.
+//
+// Good luck and Godspeed.
+func (token *AST) inverseShuntingYard(buff *bytes.Buffer, preStack *Stack, postStack *Stack, s *Stack, lastSym string, childNum int) error {
+	if len(token.Children) > 0 {
+		for i, c := range token.Children {
+			err := c.inverseShuntingYard(buff, preStack, postStack, s, token.Sym, i)
+			if err != nil {
+				return err
+			}
+			if s.Top() == nil {
+				return errors.New("Invalid AST. Cannot convert to infix expression")
+			}
+			if top, ok := s.Top().(*AST); ok && top.Sym == "(COMPOUND)" {
+				for !postStack.Empty() {
+					left, err := popAST(s)
+					if err != nil {
+						return err
+					}
+					s.Push(&AST{
+						Value:        fmt.Sprintf("%s %s", left.Value, postStack.Pop().(*AST).Value),
+						Sym:          "(COMPOUND)",
+						BindingPower: token.BindingPower})
+				}
+			}
+			for !preStack.Empty() {
+				right, err := popAST(s)
+				if err != nil {
+					return err
+				}
+				pre, err := popAST(preStack)
+				if err != nil {
+					return err
+				}
+				s.Push(&AST{
+					Value:        fmt.Sprintf("%s %s", pre.Value, right.Value),
+					Sym:          "(COMPOUND)",
+					BindingPower: token.BindingPower})
+			}
+		}
+	}
+	switch sym := strings.ToUpper(token.Sym); sym {
+	case "-":
+		//fucking unary operators
+		if len(token.Children) == 1 {
+			return shuntUnary(token, s)
+		}
+		return shuntBinary(token, s, " ")
+	case "+", "*", "^", "/", "<", ">", ">=", "<=":
+		return shuntBinary(token, s, " ")
+	case "-L", "-H":
+		//binary no space, no paren, not worth a function
+		op1, err := popAST(s)
+		if err != nil {
+			return err
+		}
+		op2, err := popAST(s)
+		if err != nil {
+			return err
+		}
+		s.Push(&AST{
+			Value:        fmt.Sprintf("%s%s%s", op2.Value, token.Value, op1.Value),
+			Sym:          token.Sym,
+			BindingPower: token.BindingPower})
+	case "D":
+		//infix dice
+		op1, err := popAST(s)
+		if err != nil {
+			return err
+		}
+		op2, err := popAST(s)
+		if err != nil {
+			return err
+		}
+		var sym string
+		if lastSym == "D" || lastSym == "d" {
+			sym = "d"
+		} else {
+			sym = "(COMPOUND)"
+		}
+		s.Push(&AST{
+			Value:        fmt.Sprintf("%s%s%s(%%s)", op2.Value, "d", op1.Value),
+			Sym:          sym,
+			BindingPower: token.BindingPower})
+	case "REP":
+		op1, err := popAST(s)
+		if err != nil {
+			return err
+		}
+		op2, err := popAST(s)
+		if err != nil {
+			return err
+		}
+		sym = "(COMPOUND)"
+		var b [][]byte
+
+		reps, _ := strconv.Atoi(op1.Value)
+		if reps > maxRepeatedLength/maxInt(1, len(op2.Value)) {
+			return errors.New("Too many repetitions to write out")
+		}
+		for index := 0; index < reps; index++ {
+			b = append(b, []byte(op2.Value))
+		}
+		compoundValue := string(bytes.Join(b, []byte(", ")))
+		s.Push(&AST{
+			Value:        compoundValue,
+			Sym:          sym,
+			BindingPower: token.BindingPower})
+	case "%":
+		//postfix no space
+		op1, err := popAST(s)
+		if err != nil {
+			return err
+		}
+		s.Push(&AST{
+			Value:        fmt.Sprintf("%s%%", op1.Value),
+			Sym:          op1.Sym,
+			BindingPower: token.BindingPower})
+	case "DC", "NEEDED", "DPR":
+		//function, arguments are never rolled
+		var args []string
+		for _, c := range token.Children {
+			if c.Sym != "(IDENT)" {
+				args = append(args, "")
+			}
+		}
+		for i := len(args) - 1; i >= 0; i-- {
+			arg, err := popAST(s)
+			if err != nil {
+				return err
+			}
+			args[i] = strings.Replace(arg.Value, "(%s)", "", -1)
+		}
+		s.Push(&AST{
+			Value:        fmt.Sprintf("%s(%s)", strings.ToLower(token.Value), strings.Join(args, ", ")),
+			Sym:          "(NUMBER)",
+			BindingPower: token.BindingPower})
+	case "[", "(ARRAY)", "(INDEX)", "SORT", "SUM", "MAX":
+		//lists and their functions keep the faces of their dice
+		var args []string
+		for _, c := range token.Children {
+			if c.Sym != "(IDENT)" {
+				args = append(args, "")
+			}
+		}
+		for i := len(args) - 1; i >= 0; i-- {
+			arg, err := popAST(s)
+			if err != nil {
+				return err
+			}
+			args[i] = arg.Value
+		}
+		var value string
+		switch {
+		case sym == "[":
+			value = fmt.Sprintf("[%s]", strings.Join(args, ", "))
+		case sym == "(ARRAY)" && len(args) == 2:
+			//like REP, every roll of the value is written out for its faces
+			n, _ := strconv.Atoi(args[1])
+			if n > maxRepeatedLength/maxInt(1, len(args[0])) {
+				return errors.New("Too many values to write out")
+			}
+			values := make([]string, maxInt(n, 0))
+			for i := range values {
+				values[i] = args[0]
+			}
+			value = fmt.Sprintf("[%s]", strings.Join(values, ", "))
+		case sym == "(INDEX)" && len(args) == 2:
+			value = fmt.Sprintf("%s[%s]", args[0], args[1])
+		default:
+			value = fmt.Sprintf("%s(%s)", strings.ToLower(token.Value), strings.Join(args, ", "))
+		}
+		s.Push(&AST{
+			Value:        value,
+			Sym:          "(NUMBER)",
+			BindingPower: token.BindingPower})
+	case "(NUMBER)":
+		//operand
+		s.Push(token)
+	case "(IDENT)":
+		//postfix. Tokens are copied rather than changed, so an AST can be written out by several goroutines at once.
+		postStack.Push(&AST{Sym: token.Sym, Value: strings.Title(token.Value)})
+	case "{":
+		preStack.Push(token)
+		if lastSym == "if" && childNum == 1 {
+			postStack.Push(&AST{Value: "else"})
+		}
+		postStack.Push(&AST{Value: "}"})
+	case "IF":
+		preStack.Push(token)
+	case "(ROOTNODE)":
+	default:
+		//prefix
+		titled := *token
+		titled.Value = strings.Title(strings.ToLower(token.Value))
+		preStack.Push(&titled)
+	}
+	return nil
+}
+
+func (t *AST) eval(ds *DiceSet) (float64, *DiceSet, error) {
+	if ds.budget != nil {
+		ds.depth++
+		defer func() { ds.depth-- }()
+		if err := ds.budget.enter(ds.depth); err != nil {
+			return 0, ds, err
+		}
+	}
+	if ds.tracer != nil {
+		return t.traceEval(ds)
+	}
+	return t.evalNode(ds)
+}
+
+//evalNode evaluates t with the evaluator registered for its symbol
+func (t *AST) evalNode(ds *DiceSet) (float64, *DiceSet, error) {
+	if op, ok := registry.symTable[strings.ToUpper(t.Sym)]; ok && op.evaluate != nil {
+		return op.evaluate(t, ds)
+	}
+	return 0, ds, fmt.Errorf("Unsupported symbol: %s", t.Sym)
+}
+
+func evalNumber(t *AST, ds *DiceSet) (float64, *DiceSet, error) {
+	i, _ := strconv.ParseFloat(t.Value, 64)
+	if len(t.Children) > 0 {
+		//grab any color below, get it on ds
+		if _, _, err := t.Children[0].eval(ds); err != nil {
+			return 0, ds, err
+		}
+	}
+	return i, ds, nil
+}
+
+//evalDrop sets the dice to drop from the next roll
+func evalDrop(t *AST, ds *DiceSet) (float64, *DiceSet, error) {
+	var sum, z float64
+	var err error
+
+	for _, c := range t.Children {
+		z, ds, err = c.eval(ds)
+		if err != nil {
+			return 0, ds, err
+		}
+		sum += z
+	}
+	switch t.Sym {
+	case "-H":
+		ds.dropHighest = int64(sum)
+	case "-L":
+		ds.dropLowest = int64(sum)
+	}
+	return 0, ds, nil
+}
+
+func evalDice(t *AST, ds *DiceSet) (float64, *DiceSet, error) {
+	dice := Dice{}
+	var nums []int64
+	for i := 0; i < len(t.Children); i++ {
+		var num float64
+		var err error
+		num, ds, err = t.Children[i].eval(ds)
+		if err != nil {
+			return 0, nil, err
+		}
+		nums = append(nums, int64(num))
+
+	}
+	if len(nums) < 2 {
+		return 0, ds, errors.NewDicelangError("Invalid dice", errors.InvalidAST, nil)
+	}
+	dice.Count = nums[0]
+	dice.Sides = nums[1]
+	//actually roll dice here
+	res, err := ds.PushAndRoll(dice)
+
+	return float64(res), ds, err
+}
+
+func evalArithmetic(t *AST, ds *DiceSet) (float64, *DiceSet, error) {
+	x, ds, err := t.preformArithmitic(ds, t.Sym)
+	if err != nil {
+		return 0, ds, err
+	}
+	return x, ds, nil
+}
+
+func evalPercent(t *AST, ds *DiceSet) (float64, *DiceSet, error) {
+	if len(t.Children) < 1 {
+		return 0, ds, errors.NewDicelangError("Invalid percentage", errors.InvalidAST, nil)
+	}
+	x, ds, err := t.Children[0].eval(ds)
+	if err != nil {
+		return 0, ds, err
+	}
+	return x / 100, ds, nil
+}
+
+//evalThreshold evaluates DC and NEEDED
+func evalThreshold(t *AST, ds *DiceSet) (float64, *DiceSet, error) {
+	x, err := t.threshold(ds)
+	if err != nil {
+		return 0, ds, err
+	}
+	for _, c := range t.Children[2:] {
+		//grab any color below, get it on ds
+		if _, _, err := c.eval(ds); err != nil {
+			return 0, ds, err
+		}
+	}
+	return x, ds, nil
+}
+
+func evalDPR(t *AST, ds *DiceSet) (float64, *DiceSet, error) {
+	a, err := t.attack(ds)
+	if err != nil {
+		return 0, ds, err
+	}
+	d, err := a.distribution(ds.budget.orUnlimited())
+	if err != nil {
+		return 0, ds, err
+	}
+	return d.Mean(), ds, nil
+}
+
+//evalSum adds up every statement in a block, roll or the root
+func evalSum(t *AST, ds *DiceSet) (float64, *DiceSet, error) {
+	var x float64
+	for _, c := range t.Children {
+		y, ds, err := c.eval(ds)
+		if err != nil {
+			return 0, ds, err
+		}
+		x += y
+	}
+	return x, ds, nil
+}
+
+func evalColor(t *AST, ds *DiceSet) (float64, *DiceSet, error) {
+	ds.PushColor(t.Value)
+	return 0, ds, nil
+}
+
+func evalRep(t *AST, ds *DiceSet) (float64, *DiceSet, error) {
+	if len(t.Children) < 2 {
+		return 0, ds, errors.NewDicelangError("Invalid repetition", errors.InvalidAST, nil)
+	}
+	numberOfReps, ds, err := t.Children[1].eval(ds)
+	if err != nil {
+		return 0, ds, err
+	}
+	var x float64
+	for index := 0; index < int(numberOfReps); index++ {
+		y, ds, err := t.Children[0].eval(ds)
+		if err != nil {
+			return 0, ds, err
+		}
+		x += y
+	}
+	return x, ds, nil
+}
+
+func evalIf(t *AST, ds *DiceSet) (float64, *DiceSet, error) {
+	if len(t.Children) < 2 {
+		return 0, ds, errors.NewDicelangError("Invalid if", errors.InvalidAST, nil)
+	}
+	res, ds, err := t.Children[0].evaluateBoolean(ds)
+	if err != nil {
+		return 0, ds, err
+	}
+	var c *AST
+	if res {
+		c = t.Children[1]
+	} else {
+		if len(t.Children) < 3 {
+			return 0, ds, nil
+		}
+		c = t.Children[2]
+	}
+	var x float64
+	//Evaluate chosen child
+	y, ds, err := c.eval(ds)
+	if err != nil {
+		return 0, ds, err
+	}
+	x += y
+	return x, ds, nil
+}
+
+func (t *AST) preformArithmitic(ds *DiceSet, op string) (float64, *DiceSet, error) {
+	//arithmitic is always binary
+	//...except for the "-" unary operator
+	diceCount := len(ds.Dice)
+	var nums []float64
+	ds.colorDepth++
+	for _, c := range t.Children {
+		x, _, err := c.eval(ds)
+		if err != nil {
+			ds.colorDepth--
+			return 0, ds, err
+		}
+		if c.Sym != "(IDENT)" {
+			nums = append(nums, x)
+		}
+	}
+	ds.colorDepth--
+	x, err := foldArithmetic(op, nums)
+	if err != nil {
+		return 0, ds, err
+	}
+	if err := ds.colorArithmetic(len(ds.Dice)-diceCount, x); err != nil {
+		return 0, ds, err
+	}
+	return x, ds, nil
+}
+
+//foldArithmetic applies op to nums from left to right. "-" negates a single number.
+func foldArithmetic(op string, nums []float64) (float64, error) {
+	if len(nums) == 0 {
+		return 0, errors.NewDicelangError("Invalid arithmetic", errors.InvalidAST, nil)
+	}
+	var x float64
+	switch op {
+	case "+":
+		for _, y := range nums {
+			x += y
+		}
+	case "-":
+		if len(nums) < 2 {
+			x = -nums[0]
+		} else {
+			x = nums[0]
+			for i := 1; i < len(nums); i++ {
+				x -= nums[i]
+			}
+		}
+	case "*":
+		x = nums[0]
+		for i := 1; i < len(nums); i++ {
+			x *= nums[i]
+		}
+	case "/":
+		x = nums[0]
+		for i := 1; i < len(nums); i++ {
+			x /= nums[i]
+		}
+	case "^":
+		x = nums[0]
+		for i := 1; i < len(nums); i++ {
+			x = math.Pow(x, nums[i])
+		}
+	default:
+		return 0, fmt.Errorf("invalid operator: %s", op)
+	}
+	return x, nil
+}
+
+//colorArithmetic gives the color of an arithmetic expression to the newDice it rolled, and adds x to that color's total
+func (d *DiceSet) colorArithmetic(newDice int, x float64) error {
+	if len(d.colors) > 1 {
+		return fmt.Errorf("cannot preform aritimitic on different color dice, try \",\" or \"and\" instead")
+	}
+	if d.colorDepth == 0 {
+		color := d.PopColor()
+		for i := 0; i < newDice; i++ {
+			d.Top(i).Color = color
+		}
+		d.AddToColor(color, x)
+	}
+	return nil
+}
+func (t *AST) evaluateBoolean(ds *DiceSet) (bool, *DiceSet, error) {
+	if ds.tracer != nil {
+		return t.traceBoolean(ds)
+	}
+	return t.evalBoolean(ds)
+}
+
+func (t *AST) evalBoolean(ds *DiceSet) (bool, *DiceSet, error) {
+	if len(t.Children) < 2 {
+		return false, ds, errors.NewDicelangError("if needs a comparison, like 1d20 > 10", errors.Friendly, nil)
+	}
+	left, ds, err := t.Children[0].eval(ds)
+	if err != nil {
+		return false, ds, err
+	}
+	right, ds, err := t.Children[1].eval(ds)
+	if err != nil {
+		return false, ds, err
+	}
+	switch t.Sym {
+	case ">":
+		return left > right, ds, nil
+	case "<":
+		return left < right, ds, nil
+	case "<=":
+		return left <= right, ds, nil
+	case ">=":
+		return left >= right, ds, nil
+	case "==":
+		return left == right, ds, nil
+	case "!=":
+		return left != right, ds, nil
+	}
+	return false, ds, fmt.Errorf("Bad bool")
+}
+
+//PushAndRoll adds a dice roll to the "stack" applying any values from the set
+func (d *DiceSet) PushAndRoll(dice Dice) (int64, error) {
+	if d.colorDepth == 0 {
+		dice.Color = d.PopColor()
+	}
+	dice.DropHighest, dice.DropLowest = droppedDice(d.dropHighest, d.dropLowest)
+	d.dropLowest = 0
+	d.dropHighest = 0
+	if d.budget != nil {
+		if err := d.budget.spendDraws(dice.Count * (dice.ExplodeDepth + 1)); err != nil {
+			return 0, err
+		}
+	}
+	res, err := dice.RollWith(d.randomSource())
+	if err != nil {
+		return 0, err
+	}
+	d.Dice = append(d.Dice, dice)
+	d.AddToColor(dice.Color, float64(res))
+	return res, nil
+}
+
+//PushColor pushes a color to the "stack"
+func (d *DiceSet) PushColor(color string) {
+	d.colors = append(d.colors, color)
+}
+
+//PeekColor returns the most recently added color from the "stack"
+func (d *DiceSet) PeekColor() string {
+	if len(d.colors) > 0 {
+		color := d.colors[len(d.colors)-1]
+		return color
+	}
+	return ""
+}
+
+//PopColor pops a color from the "stack"
+func (d *DiceSet) PopColor() string {
+
+	if len(d.colors) > 0 {
+		color := d.colors[len(d.colors)-1]
+		d.colors = d.colors[:len(d.colors)-1]
+		return color
+	}
+	return ""
+}
+
+//Top returns a pointer to the most recently added dice roll
+func (d *DiceSet) Top(loc int) *Dice {
+	if len(d.Dice) > 0 {
+		return &d.Dice[len(d.Dice)-loc-1]
+	}
+	return nil
+}
+
+//AddToColor increments the total result for a given color
+func (d *DiceSet) AddToColor(color string, value float64) {
+	if d.TotalsByColor == nil {
+		d.TotalsByColor = make(map[string]float64)
+	}
+	if d.colorDepth == 0 {
+		d.TotalsByColor[color] += value
+	}
+}
+
+//Roll rolls the dice, sets Min, Max, and Faces. Returns the total. Can be called multiple times and returns the same value each time.
+func (d *Dice) Roll() (int64, error) {
+	return d.RollWith(NewCryptoSource())
+}
+
+//RollWith is Roll, with every die rolled by src
+func (d *Dice) RollWith(src RandomSource) (int64, error) {
+	if d.Total != 0 {
+		return d.Total, nil
+	}
+	if d.ExplodeDepth > 0 || d.SuccessTarget > 0 {
+		return d.rollPool(src)
+	}
+	faces, result, err := roll(src, d.Count, d.Sides, d.DropHighest, d.DropLowest)
+	if err != nil {
+		return 0, err
+	}
+	if H, L := droppedDice(d.DropHighest, d.DropLowest); H > 0 || L > 0 {
+		d.Min = d.Count - (H + L)
+		d.Max = (d.Count - (H + L)) * d.Sides
+	} else {
+		d.Min = d.Count
+		d.Max = d.Count * d.Sides
+	}
+	d.Faces = faces
+	d.Total = result
+	return result, nil
+}
+
+//rollPool rolls exploding dice and success pools. Faces holds the total of each die, including explosions.
+func (d *Dice) rollPool(src RandomSource) (int64, error) {
+	faces, _, err := roll(src, d.Count, d.Sides, 0, 0)
+	if err != nil {
+		return 0, err
+	}
+	for i := range faces {
+		last := faces[i]
+		for explosions := int64(0); explosions < d.ExplodeDepth && last == d.Sides && d.Sides > 1; explosions++ {
+			last, err = randomInt(src, 1, d.Sides)
+			if err != nil {
+				return 0, err
+			}
+			faces[i] += last
+		}
+	}
+	sort.Slice(faces, func(i, j int) bool { return faces[i] < faces[j] })
+	var result int64
+	if d.SuccessTarget > 0 {
+		for _, face := range faces {
+			if face >= d.SuccessTarget {
+				result++
+			} else if face <= d.BotchTarget {
+				result--
+			}
+		}
+		d.Min = 0
+		d.Max = d.Count
+		if d.BotchTarget > 0 {
+			d.Min = -d.Count
+		}
+	} else {
+		result = sumInt64(faces...)
+		d.Min = d.Count
+		d.Max = d.Count * d.Sides * (d.ExplodeDepth + 1)
+	}
+	d.Faces = faces
+	d.Total = result
+	return result, nil
+}
+
+//Roll creates a random number that represents the roll of
+//some dice
+func roll(src RandomSource, numberOfDice int64, sides int64, H int64, L int64) ([]int64, int64, error) {
+	var faces []int64
+	if numberOfDice > 1000 {
+		return faces, 0, errors.NewDicelangError("I can't hold that many dice!", errors.Friendly, nil)
+	} else if sides > 1000 {
+		return faces, 0, errors.NewDicelangError("A die with that many sides is basically round", errors.Friendly, nil)
+	} else if sides < 1 {
+		return faces, 0, errors.NewDicelangError("/me ponders the meaning of a zero sided die", errors.Friendly, nil)
+	} else {
+		total := int64(0)
+		for i := int64(0); i < numberOfDice; i++ {
+			face, err := randomInt(src, 1, int64(sides))
+			if err != nil {
+				return faces, 0, err
+			}
+			faces = append(faces, face)
+			total += face
+		}
+		sort.Slice(faces, func(i, j int) bool { return faces[i] < faces[j] })
+		//like DiceDistribution, dropping as many dice as were rolled keeps none
+		H, L = droppedDice(H, L)
+		if H+L >= int64(len(faces)) {
+			total = 0
+		} else if H > 0 || L > 0 {
+			total = sumInt64(faces[L : int64(len(faces))-H]...)
+		}
+		return faces, total, nil
+	}
+}
+
+//droppedDice returns how many of the highest and lowest dice a roll drops.
+//A roll drops its highest or its lowest dice, never both: -H wins, as it always has.
+func droppedDice(H, L int64) (int64, int64) {
+	if H > 0 {
+		return H, 0
+	}
+	return 0, max(L, 0)
+}
+
+func generateRandomInt(min int64, max int64) (int64, error) {
+	return randomInt(NewCryptoSource(), min, max)
+}
+
+func sumInt64(nums ...int64) int64 {
+	r := int64(0)
+	for _, n := range nums {
+		r += n
+	}
+	return r
+}
@@ -1,6 +1,7 @@
 package dicelang
 
 import (
+	"context"
 	"sort"
 	"testing"
 
@@ -124,6 +125,37 @@ func TestRoll(t *testing.T) {
 	}
 }
 
+func TestRoll_Drops(t *testing.T) {
+	tests := []struct {
+		name     string
+		H        int64
+		L        int64
+		faces    int
+		wantZero bool
+	}{
+		{name: "drop highest", H: 1, faces: 4},
+		{name: "drop highest, not lowest", H: 1, L: 3, faces: 4},
+		{name: "drop every die", H: 4, L: 2, faces: 4, wantZero: true},
+		{name: "drop more lowest than rolled", L: 5, faces: 4, wantZero: true},
+		{name: "drop more than rolled", H: 5, faces: 4, wantZero: true},
+		{name: "negative drops", H: -3, L: -3, faces: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			faces, total, err := roll(NewPCGSource(1), 4, 6, tt.H, tt.L)
+			if err != nil {
+				t.Fatalf("roll() error = %v", err)
+			}
+			if len(faces) != tt.faces {
+				t.Errorf("roll() rolled %d dice, want %d", len(faces), tt.faces)
+			}
+			if (total == 0) != tt.wantZero {
+				t.Errorf("roll() = %d, want zero %v", total, tt.wantZero)
+			}
+		})
+	}
+}
+
 type rollBucket struct {
 	result int64
 	count  int64
@@ -136,7 +168,7 @@ func testRoll(t *testing.T, biasMod int64, biasTo int64, biasFreq float64, loops
 	}
 	biasCount := 0
 	for i := 0; i < loops; i++ {
-		_, x, err := roll(numberOfDice, sides, 0, 0)
+		_, x, err := roll(NewCryptoSource(), numberOfDice, sides, 0, 0)
 		if err != nil {
 			return false, err
 		}
@@ -217,12 +249,12 @@ func TestMinMaxValues(t *testing.T) {
 		},
 	}
 	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			p := NewParser(tt.name)
+		t.Run(tt.cmd, func(t *testing.T) {
+			p := NewParser(tt.cmd)
 			stmts, err := p.Statements()
 			_ , diceSet, err := stmts.GetDiceSet()
 			if err != nil {
-				t.Errorf("There was an error parsing a test case: %v", tt.name)
+				t.Errorf("There was an error parsing a test case: %v", tt.cmd)
 			}
 			dice := diceSet.Dice[0]
 			if dice.Min != tt.expectedMin || dice.Max != tt.expectedMax {
@@ -231,3 +263,22 @@ func TestMinMaxValues(t *testing.T) {
 		})
 	}
 }
+
+func TestAST_GetDiceSet_ColorDepthAfterError(t *testing.T) {
+	tree, err := NewParser("1d4 fire + 1d6 cold").Statements()
+	if err != nil {
+		t.Fatalf("Statements() error = %v", err)
+	}
+	program, err := Compile(tree)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	limits := Limits{MaxDraws: 1}
+	//the second die is over budget, inside the arithmetic
+	if _, ds, err := tree.GetDiceSet(WithBudget(NewBudget(context.Background(), limits))); err == nil || ds.colorDepth != 0 {
+		t.Errorf("GetDiceSet() error = %v, colorDepth = %d, want an error leaving colorDepth 0", err, ds.colorDepth)
+	}
+	if _, ds, err := program.Run(WithBudget(NewBudget(context.Background(), limits))); err == nil || ds.colorDepth != 0 {
+		t.Errorf("Program.Run() error = %v, colorDepth = %d, want an error leaving colorDepth 0", err, ds.colorDepth)
+	}
+}
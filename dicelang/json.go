@@ -0,0 +1,118 @@
+package dicelang
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aasmall/dicemagic/dicelang/errors"
+)
+
+//astJSON is the JSON encoding of an AST. Everything the parser attaches to a token, like its binding power, is rebuilt from Sym.
+type astJSON struct {
+	Sym      string `json:"sym"`
+	Value    string `json:"value"`
+	Line     int    `json:"line,omitempty"`
+	Col      int    `json:"col,omitempty"`
+	Children []*AST `json:"children,omitempty"`
+}
+
+//MarshalJSON encodes the AST as {"sym", "value", "line", "col", "children"}
+func (t *AST) MarshalJSON() ([]byte, error) {
+	return json.Marshal(astJSON{
+		Sym:      t.Sym,
+		Value:    t.Value,
+		Line:     t.line,
+		Col:      t.col,
+		Children: t.Children,
+	})
+}
+
+//UnmarshalJSON decodes an AST encoded by MarshalJSON into a tree that can be evaluated
+func (t *AST) UnmarshalJSON(data []byte) error {
+	var j astJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*t = AST{Sym: j.Sym, Value: j.Value, line: j.Line, col: j.Col, Children: j.Children}
+	for i, c := range t.Children {
+		if c == nil {
+			return errors.NewDicelangError(fmt.Sprintf("Child %d of %q is null", i, t.Sym), errors.InvalidAST, nil)
+		}
+	}
+	if strings.ToUpper(t.Sym) == "(ROOTNODE)" {
+		return nil
+	}
+	token, ok := registry.symTable[t.Sym]
+	if !ok {
+		return errors.NewDicelangError(fmt.Sprintf("Unknown symbol %q in AST", t.Sym), errors.InvalidAST, nil)
+	}
+	if t.Sym == "(NUMBER)" {
+		if _, err := strconv.ParseFloat(t.Value, 64); err != nil {
+			return errors.NewDicelangError(fmt.Sprintf("%q is not a number", t.Value), errors.InvalidAST, err)
+		}
+	}
+	if err := t.checkArity(); err != nil {
+		return err
+	}
+	t.BindingPower = token.BindingPower
+	t.nud = token.nud
+	t.led = token.led
+	t.std = token.std
+	return nil
+}
+
+//arity is how many operands each built-in operator takes, not counting colors or the drops of dice.
+//Registered operators aren't checked, since only their Eval knows what it needs.
+var arity = map[string][2]int{
+	"(NUMBER)": {0, 0},
+	"(IDENT)":  {0, 0},
+	"+":        {2, 2},
+	"-":        {1, 2},
+	"*":        {2, 2},
+	"/":        {2, 2},
+	"^":        {2, 2},
+	"%":        {1, 1},
+	"D":        {2, 2},
+	"-H":       {1, 1},
+	"-L":       {1, 1},
+	"MOD":      {2, 2},
+	"REP":      {2, 2},
+	"IF":       {2, 3},
+	"<":        {2, 2},
+	">":        {2, 2},
+	"<=":       {2, 2},
+	">=":       {2, 2},
+	"==":       {2, 2},
+	"!=":       {2, 2},
+	"DC":       {2, 2},
+	"NEEDED":   {2, 2},
+	"DPR":      {3, 5},
+	"(ARRAY)":  {2, 2},
+	"(INDEX)":  {2, 2},
+	"SORT":     {1, 1},
+	"SUM":      {1, 1},
+	"MAX":      {1, 1},
+}
+
+//checkArity returns an InvalidAST error unless t has as many operands as its operator takes
+func (t *AST) checkArity() error {
+	want, ok := arity[strings.ToUpper(t.Sym)]
+	if !ok {
+		return nil
+	}
+	operands := 0
+	for _, c := range t.Children {
+		switch {
+		case c.Sym == "(IDENT)":
+		case strings.ToUpper(t.Sym) == "D" && (c.Sym == "-H" || c.Sym == "-L"):
+		default:
+			operands++
+		}
+	}
+	if operands < want[0] || operands > want[1] {
+		return errors.NewDicelangError(fmt.Sprintf("%q has %d operands, which it can't evaluate", t.Sym, operands), errors.InvalidAST, nil)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package dicelang
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestAST_MarshalJSON(t *testing.T) {
+	root := NewParser("roll 1d20+5 fire").testStatements()
+	got, err := json.Marshal(root)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	want := `{"sym":"(rootnode)","value":"","children":[{"sym":"ROLL","value":"ROLL","line":1,"col":1,"children":[` +
+		`{"sym":"+","value":"+","line":1,"col":10,"children":[` +
+		`{"sym":"D","value":"D","line":1,"col":7,"children":[{"sym":"(NUMBER)","value":"1","line":1,"col":6},{"sym":"(NUMBER)","value":"20","line":1,"col":8}]},` +
+		`{"sym":"(NUMBER)","value":"5","line":1,"col":11,"children":[{"sym":"(IDENT)","value":"Fire","line":1,"col":13}]}]}]}]}`
+	if string(got) != want {
+		t.Errorf("json.Marshal() = %s, want %s", got, want)
+	}
+}
+
+func TestAST_UnmarshalJSON(t *testing.T) {
+	for _, cmd := range []string{
+		"roll 3d6-L1 fire and 2d4+1 ice",
+		"(2d6+3)*2 rep 3",
+		"1d4 + 2 if 1d20 > 10 else 1d8",
+		"roll dc(1d20 + 5, 0.5)",
+	} {
+		t.Run(cmd, func(t *testing.T) {
+			root := NewParser(cmd).testStatements()
+			data, err := json.Marshal(root)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			var decoded AST
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			if !equalAST(root, &decoded) {
+				t.Fatalf("json.Unmarshal() = %s, want %s", astString(&decoded), astString(root))
+			}
+			wantTotal, want, err := root.GetDiceSet(WithSeed(7))
+			if err != nil {
+				t.Fatalf("AST.GetDiceSet() error = %v", err)
+			}
+			gotTotal, got, err := decoded.GetDiceSet(WithSeed(7))
+			if err != nil {
+				t.Fatalf("decoded AST.GetDiceSet() error = %v", err)
+			}
+			if gotTotal != wantTotal || !reflect.DeepEqual(got.Dice, want.Dice) {
+				t.Errorf("decoded AST rolled %v %+v, want %v %+v", gotTotal, got.Dice, wantTotal, want.Dice)
+			}
+			if gotString, _ := decoded.String(); gotString != mustString(root) {
+				t.Errorf("decoded AST.String() = %q, want %q", gotString, mustString(root))
+			}
+		})
+	}
+}
+
+func TestAST_UnmarshalJSON_Invalid(t *testing.T) {
+	for _, data := range []string{
+		`{"sym":"EXPLODE","value":"EXPLODE"}`,
+		`{"sym":"(NUMBER)","value":"six"}`,
+		`{"sym":"+","value":"+","children":[null,{"sym":"(NUMBER)","value":"1"}]}`,
+		`{"sym":"+","value":"+","children":{}}`,
+		`{"sym":"(rootnode)","value":"","children":[null]}`,
+		`{"sym":"+","value":"+"}`,
+		`{"sym":"+","value":"+","children":[{"sym":"(NUMBER)","value":"1"},{"sym":"(IDENT)","value":"Fire"}]}`,
+		`{"sym":"-","value":"-"}`,
+		`{"sym":"%","value":"%"}`,
+		`{"sym":"REP","value":"REP","children":[{"sym":"(NUMBER)","value":"1"}]}`,
+		`{"sym":"IF","value":"IF"}`,
+		`{"sym":"D","value":"D","children":[{"sym":"(NUMBER)","value":"1"},{"sym":"-L","value":"-L","children":[{"sym":"(NUMBER)","value":"1"}]}]}`,
+		`{"sym":"DPR","value":"DPR","children":[{"sym":"(NUMBER)","value":"1"},{"sym":"(NUMBER)","value":"1"}]}`,
+		`{"sym":"(NUMBER)","value":"1","children":[{"sym":"(NUMBER)","value":"1"}]}`,
+	} {
+		var decoded AST
+		if err := json.Unmarshal([]byte(data), &decoded); err == nil {
+			t.Errorf("json.Unmarshal(%s) = %s, want an error", data, astString(&decoded))
+		}
+	}
+}
+
+func mustString(t *AST) string {
+	s, _ := t.String()
+	return s
+}
@@ -8,7 +8,7 @@ import (
 	"unicode"
 	"unicode/utf8"
 
-	"github.com/aasmall/dicemagic/app/dicelang/errors"
+	"github.com/aasmall/dicemagic/dicelang/errors"
 
 	"github.com/aasmall/word2number"
 )
@@ -24,10 +24,13 @@ type Lexer struct {
 	cached bool
 	last   *AST
 	c      *word2number.Converter
+	//version is the version of the language being lexed
+	version int
 }
 
 type tokenRegistry struct {
-	symTable map[string]*AST
+	symTable      map[string]*AST
+	operatorChars string
 }
 
 func (registry *tokenRegistry) token(sym string, value string, line int, col int) *AST {
@@ -50,7 +53,7 @@ func (registry *tokenRegistry) defined(sym string) bool {
 	return false
 }
 
-func (registry *tokenRegistry) register(sym string, bp int, nud nudFn, led ledFn, std stdFn) {
+func (registry *tokenRegistry) register(sym string, bp int, nud NudFunc, led LedFunc, std stdFn) {
 	if val, ok := registry.symTable[sym]; ok {
 		if nud != nil && val.nud == nil {
 			val.nud = nud
@@ -69,52 +72,11 @@ func (registry *tokenRegistry) register(sym string, bp int, nud nudFn, led ledFn
 	}
 }
 
-// an infix token has two children, the exp on the left and the one that follows
-func (registry *tokenRegistry) infix(sym string, bp int) {
-	registry.register(sym, bp, nil, func(t *AST, p *Parser, left *AST) (*AST, error) {
-		t.Children = append(t.Children, left)
-		token, err := p.expression(t.BindingPower)
-		if err != nil {
-			return nil, err
-		}
-		t.Children = append(t.Children, token)
-		return t, nil
-	}, nil)
-}
-
-func (registry *tokenRegistry) infixLed(sym string, bp int, led ledFn) {
+func (registry *tokenRegistry) infixLed(sym string, bp int, led LedFunc) {
 	registry.register(sym, bp, nil, led, nil)
 }
 
-func (registry *tokenRegistry) infixRight(sym string, bp int) {
-	registry.register(sym, bp, nil, func(t *AST, p *Parser, left *AST) (*AST, error) {
-		t.Children = append(t.Children, left)
-		token, err := p.expression(t.BindingPower - 1)
-		if err != nil {
-			return nil, err
-		}
-		t.Children = append(t.Children, token)
-		return t, nil
-	}, nil)
-}
-
-func (registry *tokenRegistry) infixRightLed(sym string, bp int, led ledFn) {
-	registry.register(sym, bp, nil, led, nil)
-}
-
-// a prefix token has a single children, the expression that follows
-func (registry *tokenRegistry) prefix(sym string) {
-	registry.register(sym, 0, func(t *AST, p *Parser) (*AST, error) {
-		token, err := p.expression(200)
-		if err != nil {
-			return nil, err
-		}
-		t.Children = append(t.Children, token)
-		return t, nil
-	}, nil, nil)
-}
-
-func (registry *tokenRegistry) prefixNud(sym string, nud nudFn) {
+func (registry *tokenRegistry) prefixNud(sym string, nud NudFunc) {
 	registry.register(sym, 0, nud, nil, nil)
 }
 
@@ -122,10 +84,6 @@ func (registry *tokenRegistry) stmt(sym string, std stdFn) {
 	registry.register(sym, 0, nil, nil, std)
 }
 
-func (registry *tokenRegistry) symbol(sym string) {
-	registry.register(sym, 0, func(t *AST, p *Parser) (*AST, error) { return t, nil }, nil, nil)
-}
-
 func (registry *tokenRegistry) consumable(sym string) {
 	registry.register(sym, 0, nil, nil, nil)
 }
@@ -140,9 +98,9 @@ func (lex *Lexer) nextOperator() (*AST, error) {
 	var twoChar bytes.Buffer
 	twoChar.WriteRune(r)
 	r, size = utf8.DecodeRuneInString(lex.source[lex.index:])
-	if size > 0 && isOperatorChar(r) {
+	if size > 0 && lex.tokReg.operatorChar(r) {
 		twoChar.WriteRune(r)
-		if lex.tokReg.defined(twoChar.String()) {
+		if lex.tokReg.defined(twoChar.String()) && !lex.spelledDrop(twoChar.String()) {
 			lex.consumeRune(&text, r, size)
 			textStr := text.String()
 			return lex.tokReg.token(textStr, textStr, lex.line, col), nil
@@ -152,11 +110,22 @@ func (lex *Lexer) nextOperator() (*AST, error) {
 	// single character operator
 	textStr := strings.ToUpper(text.String())
 	if !lex.tokReg.defined(textStr) {
-		return nil, errors.NewLexError(fmt.Sprintf("operator not defined: %s", textStr), lex.line, col)
+		return nil, errors.NewLexError(fmt.Sprintf("operator not defined: %s", textStr), col, lex.line)
 	}
 	return lex.tokReg.token(textStr, textStr, lex.line, col), nil
 }
 
+//spelledDrop reports whether sym is a drop that is spelled "dl" or "dh" in this version, so "-" is always subtraction
+func (lex *Lexer) spelledDrop(sym string) bool {
+	return lex.version > 1 && (sym == "-L" || sym == "-H")
+}
+
+//drops maps the second letter of "dl" and "dh" to the symbol of the drop
+var drops = map[rune]string{'L': "-L", 'H': "-H"}
+
+//keeps maps the second letter of "kh" and "kl" to the symbol of the keep
+var keeps = map[rune]string{'H': "(KEEPHIGHEST)", 'L': "(KEEPLOWEST)"}
+
 func (lex *Lexer) nextIdent() (*AST, error) {
 	var text bytes.Buffer
 	col := lex.col
@@ -166,6 +135,25 @@ func (lex *Lexer) nextIdent() (*AST, error) {
 		if unicode.IsDigit(r1) {
 			return lex.nextOperator()
 		}
+		if drop, ok := drops[unicode.ToUpper(r1)]; ok && lex.version > 1 {
+			r2, _ := utf8.DecodeRuneInString(lex.source[lex.index+2:])
+			if !isFirstIdentChar(r2) {
+				lex.consumeRune(&text, r, size)
+				lex.consumeRune(&text, r1, 1)
+				return lex.tokReg.token(drop, drop, lex.line, col), nil
+			}
+		}
+	}
+	if r == 'k' || r == 'K' {
+		r1, _ := utf8.DecodeRuneInString(lex.source[lex.index+1:])
+		if keep, ok := keeps[unicode.ToUpper(r1)]; ok && lex.version > 1 {
+			r2, _ := utf8.DecodeRuneInString(lex.source[lex.index+2:])
+			if !isFirstIdentChar(r2) {
+				lex.consumeRune(&text, r, size)
+				lex.consumeRune(&text, r1, 1)
+				return lex.tokReg.token(keep, strings.ToLower(text.String()), lex.line, col), nil
+			}
+		}
 	}
 	lex.consumeRune(&text, r, size)
 	for {
@@ -178,13 +166,22 @@ func (lex *Lexer) nextIdent() (*AST, error) {
 	}
 	symbol := text.String()
 
-	if sym := strings.ToUpper(symbol); lex.tokReg.defined(sym) {
+	if sym := strings.ToUpper(symbol); lex.tokReg.defined(sym) && (!listFunctions[sym] || lex.calls()) {
 		return lex.tokReg.token(sym, sym, lex.line, col), nil
 	} else if found, value := convertToNumeric(lex.c, symbol); found {
 		return lex.tokReg.token("(NUMBER)", strconv.Itoa(value), lex.line, col), nil
 	}
 	return lex.tokReg.token("(IDENT)", symbol, lex.line, col), nil
 }
+//listFunctions were colors before lists, so they are only functions when called, and "1d6 max" is still a color
+var listFunctions = map[string]bool{"SORT": true, "SUM": true, "MAX": true}
+
+//calls reports whether the next rune other than a space opens parentheses
+func (lex *Lexer) calls() bool {
+	rest := strings.TrimLeftFunc(lex.source[lex.index:], isSpaceNotNewline)
+	return strings.HasPrefix(rest, "(")
+}
+
 func convertToNumeric(c *word2number.Converter, word string) (bool, int) {
 	n := c.Words2Number(word)
 	if n == 0 {
@@ -237,11 +234,13 @@ func (lex *Lexer) next() (*AST, error) {
 			}
 			return lex.tokReg.token("(NUMBER)", text.String(), lex.line, col), nil
 		} else if r == '\n' {
+			col := lex.col
 			lex.line++
 			lex.consumeRune(&text, r, size)
-			return lex.tokReg.token("(NEWLINE)", "\n", lex.line-1, lex.col), nil
+			lex.col = 1
+			return lex.tokReg.token("(NEWLINE)", "\n", lex.line-1, col), nil
 
-		} else if isOperatorChar(r) { // parse operators
+		} else if lex.tokReg.operatorChar(r) { // parse operators
 			return lex.nextOperator()
 		} else {
 			break
@@ -284,6 +283,18 @@ func (lex *Lexer) consumeRune(text *bytes.Buffer, r rune, size int) {
 	lex.index += size
 }
 
+//skipRune steps over the next rune, so lexing can continue past an invalid character
+func (lex *Lexer) skipRune() {
+	lex.cached = false
+	r, size := utf8.DecodeRuneInString(lex.source[lex.index:])
+	if r == '\n' {
+		lex.line++
+		lex.col = 0
+	}
+	lex.col++
+	lex.index += size
+}
+
 func (lex *Lexer) peek() (*AST, error) {
 	if lex.cached {
 		return lex.tok, nil
@@ -296,6 +307,9 @@ func (lex *Lexer) peek() (*AST, error) {
 	// get token and cache it
 	nextToken, err := lex.next()
 	if err != nil {
+		lex.index = index
+		lex.line = line
+		lex.col = col
 		return nil, err
 	}
 	lex.tok = nextToken
@@ -312,93 +326,82 @@ func (lex *Lexer) peek() (*AST, error) {
 //NewLexer creates a new Lexer, initializes the word2number converter and token registry.
 func NewLexer(source string) *Lexer {
 	c, _ := word2number.NewConverter("en")
-	return &Lexer{tokReg: getTokenRegistry(), source: source, index: 0, line: 1, col: 1, c: c}
+	return &Lexer{tokReg: registry, source: source, index: 0, line: 1, col: 1, c: c, version: 1}
+}
+
+//dropLed parses "-L" and "-H", which drop one die unless followed by a number
+func dropLed(t *AST, p *Parser, left *AST) (*AST, error) {
+	next, err := p.lexer.peek()
+	if err != nil {
+		return nil, err
+	}
+	if next.Sym == "(NUMBER)" {
+		token, err := p.expression(t.BindingPower)
+		if err != nil {
+			return nil, err
+		}
+		t.Children = append(t.Children, token)
+	} else {
+		t.Children = append(t.Children, p.lexer.tokReg.token("(NUMBER)", "1", p.lexer.line, p.lexer.col))
+	}
+	left.Children = append(left.Children, t)
+	return left, nil
 }
 
 func getTokenRegistry() *tokenRegistry {
-	t := &tokenRegistry{symTable: make(map[string]*AST)}
+	t := &tokenRegistry{symTable: make(map[string]*AST), operatorChars: "^*()-+=/?.,:;\"|/{}[]><dDLH%"}
 
-	t.symbol("(NUMBER)")
+	t.define(Operator{Symbol: "(NUMBER)", Nud: func(t *AST, p *Parser) (*AST, error) { return t, nil }, Eval: evalNumber})
 
 	t.consumable(")")
 	t.consumable(",")
 	t.consumable("AND")
 	t.consumable("ELSE")
 
-	t.consumable("(ROOTNODE)")
+	t.define(Operator{Symbol: "(ROOTNODE)", Eval: evalSum})
 	t.consumable("(EOF)")
-	t.consumable("{")
+	t.define(Operator{Symbol: "{", Eval: evalSum})
 	t.consumable("}")
-	t.consumable("ROLL")
-	t.consumable("REP")
+	t.define(Operator{Symbol: "ROLL", Eval: evalSum})
 	t.consumable("(NEWLINE)")
 
-	t.infix("+", 50)
-	t.infix("-", 50)
+	t.define(Infix("+", 50, evalArithmetic))
+	t.define(Infix("-", 50, evalArithmetic))
 
-	t.infix("*", 60)
-	t.infix("/", 60)
-	t.infix("^", 70)
-	t.infix("D", 80)
+	t.define(Infix("*", 60, evalArithmetic))
+	t.define(Infix("/", 60, evalArithmetic))
+	t.define(Infix("^", 70, evalArithmetic))
+	t.define(Infix("D", 80, evalDice))
 
-	t.infixLed("-L", 80, func(t *AST, p *Parser, left *AST) (*AST, error) {
-		next, err := p.lexer.peek()
-		if err != nil {
-			return nil, err
-		}
-		if next.Sym == "(NUMBER)" {
-			token, err := p.expression(t.BindingPower)
-			if err != nil {
-				return nil, err
-			}
-			t.Children = append(t.Children, token)
-		} else {
-			t.Children = append(t.Children, p.lexer.tokReg.token("(NUMBER)", "1", p.lexer.line, p.lexer.col))
-		}
-		left.Children = append(left.Children, t)
-		return left, nil
-	})
-	t.infixLed("-H", 80, func(t *AST, p *Parser, left *AST) (*AST, error) {
-		next, err := p.lexer.peek()
-		if err != nil {
-			return nil, err
-		}
-		if next.Sym == "(NUMBER)" {
-			token, err := p.expression(t.BindingPower)
-			if err != nil {
-				return nil, err
-			}
-			t.Children = append(t.Children, token)
-		} else {
-			t.Children = append(t.Children, p.lexer.tokReg.token("(NUMBER)", "1", p.lexer.line, p.lexer.col))
-		}
-		left.Children = append(left.Children, t)
-		return left, nil
-	})
+	t.define(Operator{Symbol: "-L", BindingPower: 80, Led: dropLed, Eval: evalDrop})
+	t.define(Operator{Symbol: "-H", BindingPower: 80, Led: dropLed, Eval: evalDrop})
 
-	t.infix("mod", 95)
-	t.infix("REP", 20)
+	t.define(Infix("mod", 95, nil))
+	t.define(Infix("REP", 20, evalRep))
 
-	t.infix("<", 30)
-	t.infix(">", 30)
-	t.infix("<=", 30)
-	t.infix(">=", 30)
-	t.infix("==", 30)
-	t.infix("!=", 30)
+	t.define(Infix("<", 30, nil))
+	t.define(Infix(">", 30, nil))
+	t.define(Infix("<=", 30, nil))
+	t.define(Infix(">=", 30, nil))
+	t.define(Infix("==", 30, nil))
+	t.define(Infix("!=", 30, nil))
 
-	t.infixLed("(IDENT)", 300, func(t *AST, p *Parser, left *AST) (*AST, error) {
+	t.define(Operator{Symbol: "(IDENT)", BindingPower: 300, Eval: evalColor, Led: func(t *AST, p *Parser, left *AST) (*AST, error) {
+		p.colorWarning(t, left)
 		t.Value = strings.Title(t.Value)
 		left.Children = append(left.Children, t)
 		return left, nil
-	})
+	}})
 
-	t.infixLed("IF", 20, func(t *AST, p *Parser, left *AST) (*AST, error) {
+	t.define(Operator{Symbol: "IF", BindingPower: 20, Eval: evalIf, Led: func(t *AST, p *Parser, left *AST) (*AST, error) {
 		cond, err := p.expression(0)
 		if err != nil {
 			return nil, err
 		}
 		t.Children = append(t.Children, cond)
-		p.advance("else")
+		if _, err := p.advance("ELSE"); err != nil {
+			return nil, err
+		}
 		t.Children = append(t.Children, left)
 		token, err := p.expression(0)
 		if err != nil {
@@ -406,7 +409,7 @@ func getTokenRegistry() *tokenRegistry {
 		}
 		t.Children = append(t.Children, token)
 		return t, nil
-	})
+	}})
 
 	t.infixLed("(", 90, func(token *AST, p *Parser, left *AST) (*AST, error) {
 		token.Children = append(token.Children, left)
@@ -428,11 +431,13 @@ func getTokenRegistry() *tokenRegistry {
 				if token.Sym != "," {
 					break
 				}
-				p.advance(",")
+				if _, err := p.advance(","); err != nil {
+					return nil, err
+				}
 			}
-			p.advance(")")
-		} else {
-			p.advance(")")
+		}
+		if _, err := p.advance(")"); err != nil {
+			return nil, err
 		}
 		return token, nil
 	})
@@ -445,7 +450,23 @@ func getTokenRegistry() *tokenRegistry {
 		left.Children = append(left.Children, t.Children...)
 		return left, nil
 	})
-	t.prefix("-")
+	t.define(Postfix("%", 75, evalPercent))
+	t.define(Prefix("-", evalArithmetic))
+
+	t.define(Function("DC", 2, 2, evalThreshold))
+	t.define(Function("NEEDED", 2, 2, evalThreshold))
+	t.define(Function("DPR", 3, 5, evalDPR))
+
+	t.define(Operator{Symbol: "[", BindingPower: 90, Nud: listNud, Led: listLed, Eval: evalListLiteral})
+	t.define(Operator{Symbol: "(ARRAY)", Eval: evalArray})
+	t.define(Operator{Symbol: "(INDEX)", Eval: evalIndex})
+	t.consumable("]")
+	t.consumable(";")
+	t.define(Function("SORT", 1, 1, evalSort))
+	t.define(Function("SUM", 1, 1, evalListSum))
+	t.define(Function("MAX", 1, 1, evalListMax))
+	t.define(Operator{Symbol: "(KEEPHIGHEST)", BindingPower: 80, Led: keepLed})
+	t.define(Operator{Symbol: "(KEEPLOWEST)", BindingPower: 80, Led: keepLed})
 
 	t.prefixNud("(", func(t *AST, p *Parser) (*AST, error) {
 		next, err := p.lexer.peek()
@@ -468,7 +489,12 @@ func getTokenRegistry() *tokenRegistry {
 				t.Children = append(t.Children, token)
 			}
 		}
-		p.advance(")")
+		if _, err := p.advance(")"); err != nil {
+			return nil, err
+		}
+		if len(t.Children) == 0 {
+			return nil, p.errorAt(t, "empty parentheses")
+		}
 		return t.Children[0], nil
 	})
 
@@ -539,12 +565,10 @@ func getTokenRegistry() *tokenRegistry {
 		return t, nil
 	})
 	t.stmt("{", func(t *AST, p *Parser) (*AST, error) {
-		root, err := p.Statements()
-		if err != nil {
+		t.Children = append(t.Children, p.statements()...)
+		if _, err := p.advance("}"); err != nil {
 			return nil, err
 		}
-		t.Children = append(t.Children, root.Children...)
-		p.advance("}")
 		return t, nil
 	})
 
@@ -559,12 +583,3 @@ func isIdentChar(r rune) bool {
 	return isFirstIdentChar(r) || unicode.IsDigit(r)
 }
 
-func isOperatorChar(r rune) bool {
-	operators := "^*()-+=/?.,:;\"|/{}[]><dDLH"
-	for _, c := range operators {
-		if c == r {
-			return true
-		}
-	}
-	return false
-}
@@ -0,0 +1,298 @@
+package dicelang
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aasmall/dicemagic/dicelang/errors"
+)
+
+//maxListLength bounds the values in a list like "[4d6dl1; 6]", which are all held at once
+const maxListLength = 10000
+
+//IsList reports whether t evaluates to a list, like "[4d6dl1; 6]" or "sort([1d20, 1d20])", rather than a number.
+//A list is a number too, the sum of its values.
+func (t *AST) IsList() bool {
+	switch strings.ToUpper(t.Sym) {
+	case "[", "(ARRAY)", "SORT":
+		return true
+	case "ROLL", "{", "(ROOTNODE)":
+		return len(t.Children) == 1 && t.Children[0].IsList()
+	}
+	return false
+}
+
+//GetList evaluates t like GetDiceSet, returning every value of a list rather than their sum.
+//Anything but a list is a list of its one value.
+func (t *AST) GetList(opts ...EvalOption) ([]float64, DiceSet, error) {
+	ds := &DiceSet{}
+	for _, o := range opts {
+		o(ds)
+	}
+	if ds.simplify {
+		t = Simplify(t)
+	}
+	values, err := t.evalList(ds)
+	if err != nil {
+		return nil, *ds, err
+	}
+	return values, *ds, nil
+}
+
+//evalList evaluates t, returning its values
+func (t *AST) evalList(ds *DiceSet) ([]float64, error) {
+	ds.list = nil
+	x, _, err := t.eval(ds)
+	if err != nil {
+		return nil, err
+	}
+	if t.IsList() {
+		return ds.list, nil
+	}
+	return []float64{x}, nil
+}
+
+//setList makes values the list evaluated last, and returns their sum
+func (d *DiceSet) setList(values []float64) (float64, *DiceSet, error) {
+	d.list = values
+	var x float64
+	for _, y := range values {
+		x += y
+	}
+	return x, d, nil
+}
+
+//listColors evaluates the colors of the list t, which apply to each of its values
+func (t *AST) listColors(ds *DiceSet) ([]string, error) {
+	n := len(ds.colors)
+	for _, c := range t.Children {
+		if c.Sym == "(IDENT)" {
+			if _, _, err := c.eval(ds); err != nil {
+				return nil, err
+			}
+		}
+	}
+	colors := append([]string(nil), ds.colors[n:]...)
+	ds.colors = ds.colors[:n]
+	return colors, nil
+}
+
+//evalElement evaluates a value of a list in the colors of the list
+func evalElement(c *AST, colors []string, ds *DiceSet) (float64, error) {
+	n := len(ds.colors)
+	for _, color := range colors {
+		ds.PushColor(color)
+	}
+	x, _, err := c.eval(ds)
+	//a value that rolled no dice leaves its colors behind
+	if len(ds.colors) > n {
+		ds.colors = ds.colors[:n]
+	}
+	return x, err
+}
+
+//evalListLiteral evaluates "[a, b, c]", the list of the value of each element
+func evalListLiteral(t *AST, ds *DiceSet) (float64, *DiceSet, error) {
+	colors, err := t.listColors(ds)
+	if err != nil {
+		return 0, ds, err
+	}
+	var values []float64
+	for _, c := range t.Children {
+		if c.Sym == "(IDENT)" {
+			continue
+		}
+		x, err := evalElement(c, colors, ds)
+		if err != nil {
+			return 0, ds, err
+		}
+		values = append(values, x)
+	}
+	return ds.setList(values)
+}
+
+//evalArray evaluates "[x; n]", the list of n rolls of x
+func evalArray(t *AST, ds *DiceSet) (float64, *DiceSet, error) {
+	if len(t.Children) < 2 {
+		return 0, ds, errors.NewDicelangError("Invalid list", errors.InvalidAST, nil)
+	}
+	colors, err := t.listColors(ds)
+	if err != nil {
+		return 0, ds, err
+	}
+	n, ds, err := t.Children[1].eval(ds)
+	if err != nil {
+		return 0, ds, err
+	}
+	if n > maxListLength {
+		return 0, ds, errors.NewDicelangError(fmt.Sprintf("That's too many values! A list holds at most %d", maxListLength), errors.Friendly, nil)
+	}
+	if n < 0 || n != math.Trunc(n) {
+		return 0, ds, errors.NewDicelangError(fmt.Sprintf("A list can't hold %s values, only a whole number of them", strconv.FormatFloat(n, 'f', -1, 64)), errors.Friendly, nil)
+	}
+	values := make([]float64, 0, int(n))
+	for i := 0; i < int(n); i++ {
+		x, err := evalElement(t.Children[0], colors, ds)
+		if err != nil {
+			return 0, ds, err
+		}
+		values = append(values, x)
+	}
+	return ds.setList(values)
+}
+
+//evalIndex evaluates "list[i]", the ith value of a list. The first value is 1, and i must be a whole number.
+func evalIndex(t *AST, ds *DiceSet) (float64, *DiceSet, error) {
+	if len(t.Children) < 2 {
+		return 0, ds, errors.NewDicelangError("Invalid index", errors.InvalidAST, nil)
+	}
+	values, err := t.Children[0].evalList(ds)
+	if err != nil {
+		return 0, ds, err
+	}
+	i, ds, err := t.Children[1].eval(ds)
+	if err != nil {
+		return 0, ds, err
+	}
+	if i < 1 || i != math.Trunc(i) || int(i) > len(values) {
+		return 0, ds, errors.NewDicelangError(fmt.Sprintf("There is no value %s in a list of %d", strconv.FormatFloat(i, 'f', -1, 64), len(values)), errors.Friendly, nil)
+	}
+	return values[int(i)-1], ds, nil
+}
+
+//evalSort evaluates sort(list), the values of list from highest to lowest, as stat arrays are written
+func evalSort(t *AST, ds *DiceSet) (float64, *DiceSet, error) {
+	values, err := t.Children[0].evalList(ds)
+	if err != nil {
+		return 0, ds, err
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Sort(sort.Reverse(sort.Float64Slice(sorted)))
+	return ds.setList(sorted)
+}
+
+//evalListSum evaluates sum(list)
+func evalListSum(t *AST, ds *DiceSet) (float64, *DiceSet, error) {
+	values, err := t.Children[0].evalList(ds)
+	if err != nil {
+		return 0, ds, err
+	}
+	var x float64
+	for _, y := range values {
+		x += y
+	}
+	return x, ds, nil
+}
+
+//evalListMax evaluates max(list), its highest value
+func evalListMax(t *AST, ds *DiceSet) (float64, *DiceSet, error) {
+	values, err := t.Children[0].evalList(ds)
+	if err != nil {
+		return 0, ds, err
+	}
+	if len(values) == 0 {
+		return 0, ds, errors.NewDicelangError("An empty list has no highest value", errors.Friendly, nil)
+	}
+	x := values[0]
+	for _, y := range values[1:] {
+		if y > x {
+			x = y
+		}
+	}
+	return x, ds, nil
+}
+
+//listLed parses "list[i]"
+func listLed(t *AST, p *Parser, left *AST) (*AST, error) {
+	index, err := p.expression(0)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.advance("]"); err != nil {
+		return nil, err
+	}
+	t.Sym = "(INDEX)"
+	t.Children = append(t.Children, left, index)
+	return t, nil
+}
+
+//listNud parses "[a, b, c]" and "[x; n]"
+func listNud(t *AST, p *Parser) (*AST, error) {
+	for {
+		//bind tighter than "," so it separates values instead of merging them
+		x, err := p.expression(25)
+		if err != nil {
+			return nil, err
+		}
+		t.Children = append(t.Children, x)
+		next, err := p.lexer.peek()
+		if err != nil {
+			return nil, err
+		}
+		if next.Sym == ";" && len(t.Children) == 1 {
+			if _, err := p.advance(";"); err != nil {
+				return nil, err
+			}
+			n, err := p.expression(25)
+			if err != nil {
+				return nil, err
+			}
+			t.Sym = "(ARRAY)"
+			t.Children = append(t.Children, n)
+			break
+		}
+		if next.Sym != "," {
+			break
+		}
+		if _, err := p.advance(","); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := p.advance("]"); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+//keepLed parses "kh" and "kl" as the dice they drop, so "4d6kh3" is "4d6dl1".
+//The number of dice must be a number, to know how many that is.
+func keepLed(t *AST, p *Parser, left *AST) (*AST, error) {
+	n := p.lexer.tokReg.token("(NUMBER)", "1", t.line, t.col)
+	next, err := p.lexer.peek()
+	if err != nil {
+		return nil, err
+	}
+	if next.Sym == "(NUMBER)" {
+		//the number may carry a color, which is kept with it
+		if n, err = p.expression(t.BindingPower); err != nil {
+			return nil, err
+		}
+		if n.Sym != "(NUMBER)" {
+			return nil, p.errorAt(n, "%s keeps a number of dice, like 4d6%s3", t.Value, t.Value)
+		}
+	}
+	if strings.ToUpper(left.Sym) != "D" || len(left.Children) < 2 || left.Children[0].Sym != "(NUMBER)" {
+		return nil, p.errorAt(t, "%s needs a number of dice to keep from, like 4d6%s3", t.Value, t.Value)
+	}
+	count, _ := strconv.ParseFloat(left.Children[0].Value, 64)
+	keep, _ := strconv.ParseFloat(n.Value, 64)
+	n.Value = strconv.FormatFloat(maxFloat(count-keep, 0), 'f', -1, 64)
+	drop := "-L"
+	if t.Sym == "(KEEPLOWEST)" {
+		drop = "-H"
+	}
+	d := p.lexer.tokReg.token(drop, drop, t.line, t.col)
+	d.Children = append(d.Children, n)
+	left.Children = append(left.Children, d)
+	return left, nil
+}
+
+func maxFloat(a float64, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
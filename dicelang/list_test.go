@@ -0,0 +1,171 @@
+package dicelang
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aasmall/dicemagic/dicelang/errors"
+)
+
+func TestAST_GetList(t *testing.T) {
+	tests := []struct {
+		source string
+		want   []float64
+	}{
+		{"[1, 2 + 3, 4]", []float64{1, 5, 4}},
+		{"[2; 3]", []float64{2, 2, 2}},
+		{"[1d1 + 1; 2]", []float64{2, 2}},
+		{"sort([3, 1, 2])", []float64{3, 2, 1}},
+		{"sort([5, 7, 6])[2]", []float64{6}},
+		{"sum([1, 2, 3])", []float64{6}},
+		{"max([4, 9, 2]) + 1", []float64{10}},
+		{"roll [1, 2]", []float64{1, 2}},
+		{"[1; 0]", nil},
+		{"1d1\n[1, 2]", []float64{4}},
+		{"1d1 max", []float64{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			root, err := NewParser(tt.source).Statements()
+			if err != nil {
+				t.Fatalf("Statements() error = %v", err)
+			}
+			got, _, err := root.GetList()
+			if err != nil {
+				t.Fatalf("GetList() error = %v", err)
+			}
+			if len(got) != len(tt.want) || (len(got) > 0 && !reflect.DeepEqual(got, tt.want)) {
+				t.Errorf("GetList() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAST_GetListRolled(t *testing.T) {
+	root, err := NewParser("#dicelang 2\n[4d6kh3 fire; 6]").Statements()
+	if err != nil {
+		t.Fatalf("Statements() error = %v", err)
+	}
+	values, ds, err := root.GetList(WithSeed(3))
+	if err != nil {
+		t.Fatalf("GetList() error = %v", err)
+	}
+	total, _, _ := root.GetDiceSet(WithSeed(3))
+	var sum float64
+	for i, x := range values {
+		if x < 3 || x > 18 {
+			t.Errorf("value %d = %v, want between 3 and 18", i, x)
+		}
+		if d := ds.Dice[i]; d.DropLowest != 1 || d.Color != "Fire" || float64(d.Total) != x {
+			t.Errorf("dice %d = %+v, want 4d6 dropping the lowest, in fire, totalling %v", i, d, x)
+		}
+		sum += x
+	}
+	if len(values) != 6 || sum != total || ds.TotalsByColor["Fire"] != total {
+		t.Errorf("GetList() = %v %v, want 6 values adding up to %v", values, ds.TotalsByColor, total)
+	}
+}
+
+func TestAST_GetListErrors(t *testing.T) {
+	for _, source := range []string{"[1; 2][3]", "[1, 2][0]", "[1, 2][1.5]", "max([1; 0])", "[1; 100000]", "[1; -1]", "[1; 2.5]"} {
+		root, err := NewParser(source).Statements()
+		if err != nil {
+			t.Fatalf("Statements(%q) error = %v", source, err)
+		}
+		if _, _, err := root.GetList(); err == nil {
+			t.Errorf("GetList(%q) succeeded, want an error", source)
+		} else if e, ok := err.(*errors.DicelangError); !ok || e.Code != errors.Friendly {
+			t.Errorf("GetList(%q) error = %v, want a Friendly error", source, err)
+		}
+	}
+}
+
+func TestParser_Lists(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"list", "[1d20+5,2d6 fire]", "[1d20 + 5, 2d6 Fire]"},
+		{"repeated", "[ 4d6-L ; 6 ]", "[4d6-L1; 6]"},
+		{"colored", "[1d6; 3] fire", "[1d6; 3] Fire"},
+		{"functions", "sort([1d6, 1d8])[1] + sum([(1 rep 2), 3]) * max([2])", "sort([1d6, 1d8])[1] + sum([(1 rep 2), 3]) * max([2])"},
+		{"statements", "1d20\n[1; 2]", "1d20\n[1; 2]"},
+		{"keep highest", "#dicelang 2\n4d6kh3", "#dicelang 2\n4d6dl1"},
+		{"keep lowest", "#dicelang 2\n2d20KL fire", "#dicelang 2\n(2d20dh1) Fire"},
+		{"keep more than rolled", "#dicelang 2\n2d6kh3", "#dicelang 2\n2d6dl0"},
+		{"v1 colors", "4d6 kh3 + 1d6 max", "4d6 Kh3 + 1d6 Max"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FormatSource(tt.source)
+			if err != nil {
+				t.Fatalf("FormatSource() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("FormatSource() = %q, want %q", got, tt.want)
+			}
+			again, err := FormatSource(got)
+			if err != nil || again != got {
+				t.Errorf("FormatSource(%q) = %q, %v, want it unchanged", got, again, err)
+			}
+		})
+	}
+}
+
+func TestParser_ListErrors(t *testing.T) {
+	for _, source := range []string{"[]", "[1, 2", "[1; 2; 3]", "#dicelang 2\n(1d4)d6kh2"} {
+		if _, err := NewParser(source).Statements(); err == nil {
+			t.Errorf("Statements(%q) succeeded, want an error", source)
+		}
+	}
+}
+
+func TestTrace_List(t *testing.T) {
+	root, err := NewParser("sort([3, 1, 2])").Statements()
+	if err != nil {
+		t.Fatalf("Statements() error = %v", err)
+	}
+	var tr Trace
+	if _, _, err := root.GetDiceSet(WithTrace(&tr)); err != nil {
+		t.Fatalf("GetDiceSet() error = %v", err)
+	}
+	if got := tr.String(); !strings.HasSuffix(got, "= 3, 2, 1") {
+		t.Errorf("String() = %q, want it to end with the sorted values", got)
+	}
+	if got := tr.ResultTree().String(nil); got != "sort([3, 1, 2])" {
+		t.Errorf("ResultTree() = %q", got)
+	}
+	if !reflect.DeepEqual(tr.Values, []float64{3, 2, 1}) {
+		t.Errorf("Values = %v, want [3 2 1]", tr.Values)
+	}
+}
+
+func TestEvaluate_List(t *testing.T) {
+	res, err := Evaluate(context.Background(), "sort([1d1 + 2, 1d1 + 4]), 1d1", WithSeed(1))
+	if err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+	if got := res.Statements[0].Values; !reflect.DeepEqual(got, []float64{5, 3}) {
+		t.Errorf("Values = %v, want [5 3]", got)
+	}
+	if res.Statements[1].Values != nil || res.Total != 9 {
+		t.Errorf("Evaluate() = %+v, want a total of 9 and no values for 1d1", res)
+	}
+}
+
+func TestEvaluate_ListKeepWithoutPragma(t *testing.T) {
+	//version 1 reads "kh3" as a color, so the roll warns rather than silently keeping every die
+	res, err := Evaluate(context.Background(), "[4d6kh3; 6]", WithSeed(3))
+	if err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+	if len(res.Warnings) != 1 || !strings.Contains(res.Warnings[0].Message, Pragma(2)) {
+		t.Errorf("Evaluate() warnings = %+v, want one pointing to %q", res.Warnings, Pragma(2))
+	}
+	if d := res.Statements[0].Dice[0]; d.DropLowest != 0 || d.Color != "Kh3" {
+		t.Errorf("Evaluate() dice = %+v, want 4d6 in the color Kh3, as version 1 reads it", d)
+	}
+}
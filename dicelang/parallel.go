@@ -0,0 +1,66 @@
+package dicelang
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+//EvalEach calls eval once for each of n independent statements, giving each its own RandomSource which draws from src
+//and records every draw. The calls are made at the same time when src can be drawn from concurrently, like a CryptoSource,
+//and one after another otherwise, so seeded, fair and replayed rolls draw the same numbers in the same order every time.
+//eval should evaluate statement i on its own DiceSet. Sharing a Budget between statements is safe.
+//
+//EvalEach returns the draws of every statement in statement order, which replay the whole evaluation,
+//or the error of the first statement that failed.
+func EvalEach(n int, src RandomSource, eval func(i int, src RandomSource) error) ([]Draw, error) {
+	return evalEach(n, src, concurrent(src), eval)
+}
+
+//concurrent reports whether draws from src don't depend on the order they are made in, and may be made from any goroutine
+func concurrent(src RandomSource) bool {
+	_, ok := src.(*CryptoSource)
+	return ok
+}
+
+func evalEach(n int, src RandomSource, parallel bool, eval func(i int, src RandomSource) error) ([]Draw, error) {
+	recorders := make([]*RecordingSource, n)
+	errs := make([]error, n)
+	for i := range recorders {
+		recorders[i] = NewRecordingSource(src)
+	}
+	if parallel && n > 1 {
+		var wg sync.WaitGroup
+		slots := make(chan struct{}, runtime.GOMAXPROCS(0))
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				slots <- struct{}{}
+				defer func() { <-slots }()
+				//a panic here would take down the whole process, rather than the request that caused it
+				defer func() {
+					if r := recover(); r != nil {
+						errs[i] = fmt.Errorf("statement %d panicked: %v", i+1, r)
+					}
+				}()
+				errs[i] = eval(i, recorders[i])
+			}(i)
+		}
+		wg.Wait()
+	} else {
+		for i := 0; i < n; i++ {
+			if errs[i] = eval(i, recorders[i]); errs[i] != nil {
+				break
+			}
+		}
+	}
+	var transcript []Draw
+	for i, r := range recorders {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		transcript = append(transcript, r.Transcript()...)
+	}
+	return transcript, nil
+}
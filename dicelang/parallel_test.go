@@ -0,0 +1,79 @@
+package dicelang
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEvalEach_Replay(t *testing.T) {
+	source := strings.Repeat("4d6-L fire, 1d20 + 5 ice, 2d8 rep 3\n", 20)
+	res, err := Evaluate(context.Background(), source)
+	if err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+	replay := NewReplaySource(res.Transcript)
+	again, err := Evaluate(context.Background(), source, WithRandomSource(replay))
+	if err != nil {
+		t.Fatalf("Evaluate() replay error = %v", err)
+	}
+	if err := replay.Done(); err != nil {
+		t.Fatal(err)
+	}
+	for i := range res.Statements {
+		if !reflect.DeepEqual(res.Statements[i].Dice, again.Statements[i].Dice) {
+			t.Fatalf("statement %d replayed %+v, want %+v", i, again.Statements[i].Dice, res.Statements[i].Dice)
+		}
+	}
+	if again.Total != res.Total {
+		t.Errorf("replayed total = %v, want %v", again.Total, res.Total)
+	}
+}
+
+func TestEvalEach_Sequential(t *testing.T) {
+	src := NewPCGSource(7)
+	var order []int
+	transcript, err := EvalEach(3, src, func(i int, src RandomSource) error {
+		order = append(order, i)
+		_, err := src.Int63n(6)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("EvalEach() error = %v", err)
+	}
+	if !reflect.DeepEqual(order, []int{0, 1, 2}) {
+		t.Errorf("EvalEach() evaluated %v, want them in order", order)
+	}
+	want := NewPCGSource(7)
+	for i, d := range transcript {
+		if v, _ := want.Int63n(6); d.Value != v {
+			t.Errorf("draw %d = %d, want %d", i, d.Value, v)
+		}
+	}
+}
+
+func TestEvalEach_FirstError(t *testing.T) {
+	_, err := EvalEach(10, NewCryptoSource(), func(i int, src RandomSource) error {
+		if i >= 4 {
+			return fmt.Errorf("statement %d", i)
+		}
+		return nil
+	})
+	if err == nil || err.Error() != "statement 4" {
+		t.Errorf("EvalEach() error = %v, want the error of statement 4", err)
+	}
+}
+
+func TestBudget_Concurrent(t *testing.T) {
+	budget := NewBudget(context.Background(), Limits{MaxDraws: 1000})
+	stmt := NewParser("10d6").testStatements()
+	_, err := EvalEach(200, NewCryptoSource(), func(i int, src RandomSource) error {
+		_, _, err := stmt.GetDiceSet(WithBudget(budget), WithRandomSource(src))
+		return err
+	})
+	if err == nil {
+		t.Errorf("EvalEach() rolled 2000 dice with a budget of 1000")
+	}
+}
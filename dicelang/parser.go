@@ -0,0 +1,193 @@
+package dicelang
+
+import (
+	"strings"
+)
+
+type stdFn func(*AST, *Parser) (*AST, error)
+
+//AST represents a node in an abstract syntax tree
+type AST struct {
+	Sym          string
+	Value        string
+	line         int
+	col          int
+	BindingPower int
+	nud          NudFunc
+	led          LedFunc
+	std          stdFn
+	evaluate     EvalFunc
+	Children     []*AST
+}
+
+// Parser holds a Lexer and implements a top down operator precedence parser (https://tdop.github.io/)
+// credit to: https://github.com/cristiandima/tdop for most of this code.
+type Parser struct {
+	lexer       *Lexer
+	diagnostics []Diagnostic
+	words       []string
+	//pragma is set when the source names its version with "#dicelang N"
+	pragma bool
+}
+
+//NewParser creates a new Parser from an input string
+func NewParser(source string, opts ...ParserOption) *Parser {
+	l := NewLexer(source)
+	parse := &Parser{lexer: l}
+	if version, pos, found := findPragma(source); found {
+		parse.pragma = true
+		parse.setVersion(version, pos)
+	}
+	for _, o := range opts {
+		o(parse)
+	}
+	return parse
+}
+
+//Diagnostics returns every problem found by Statements, including warnings about source that parsed
+func (parse *Parser) Diagnostics() []Diagnostic {
+	return parse.diagnostics
+}
+
+func (parse *Parser) expression(rbp int) (*AST, error) {
+	var left *AST
+	t, err := parse.lexer.peek()
+	if err != nil {
+		return nil, err
+	}
+	if t.nud == nil {
+		//the end of a statement is left for the next one
+		if !endsStatement(t) {
+			parse.lexer.next()
+		}
+		if s, ok := parse.suggest(t.Value, maxInt(1, len([]rune(t.Value))/3)); ok && t.Sym == "(IDENT)" {
+			return nil, parse.errorAt(t, "unexpected %s, did you mean '%s'?", describe(t), s)
+		}
+		return nil, parse.errorAt(t, "unexpected %s", describe(t))
+	}
+	t, err = parse.lexer.next()
+	if err != nil {
+		return nil, err
+	}
+	left, err = t.nud(t, parse)
+	if err != nil {
+		return nil, err
+	}
+	t, err = parse.lexer.peek()
+	if err != nil {
+		return nil, err
+	}
+	for rbp < t.BindingPower {
+		t, err = parse.lexer.next()
+		if err != nil {
+			return nil, err
+		}
+		if t.led != nil {
+			left, err = t.led(t, parse, left)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			return nil, parse.errorAt(t, "unexpected %s", describe(t))
+		}
+		t, err = parse.lexer.peek()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return left, nil
+}
+
+//Statements returns all statements from the parser as []*AST.
+//When the source does not parse, it returns a *ParseError with every problem found.
+func (parse *Parser) Statements() (*AST, error) {
+	root := &AST{Value: "", Sym: "(rootnode)"}
+	for {
+		root.Children = append(root.Children, parse.statements()...)
+		next, err := parse.lexer.next()
+		if err != nil || next.Sym == "(EOF)" {
+			break
+		}
+		//statements stops at "}", which is only expected at the end of a block
+		parse.diagnose(parse.errorAt(next, "unexpected %s", describe(next)))
+	}
+	for _, d := range parse.diagnostics {
+		if d.Severity == SeverityError {
+			return nil, &ParseError{Source: parse.lexer.source, Diagnostics: parse.diagnostics}
+		}
+	}
+	return root, nil
+}
+
+//statements parses statements up to the end of the source or block. Statements that do not parse are diagnosed and skipped.
+func (parse *Parser) statements() []*AST {
+	var stmts []*AST
+	for {
+		next, err := parse.lexer.peek()
+		if err != nil {
+			parse.diagnose(err)
+			parse.synchronize()
+			continue
+		}
+		if next.Sym == "(EOF)" || next.Sym == "}" {
+			return stmts
+		}
+		stmt, err := parse.Statement()
+		if err != nil {
+			parse.diagnose(err)
+			parse.synchronize()
+			continue
+		}
+		if stmt.Sym != "(EOF)" {
+			stmts = append(stmts, stmt)
+		}
+	}
+}
+
+//For tests only
+func (parse *Parser) testStatements() *AST {
+	root, err := parse.Statements()
+	if err != nil {
+		panic(err)
+	}
+	return root
+}
+
+func (parse *Parser) block() (*AST, error) {
+	tok, err := parse.lexer.next()
+	if err != nil {
+		return nil, err
+	}
+	if tok.Sym != "{" {
+		return nil, parse.errorAt(tok, "expected '{', found %s", describe(tok))
+	}
+	return tok.std(tok, parse)
+}
+
+//Statement returns the next statement from the parser as *AST
+func (parse *Parser) Statement() (*AST, error) {
+	tok, err := parse.lexer.peek()
+	if err != nil {
+		return nil, err
+	}
+	if tok.std != nil {
+		tok, err = parse.lexer.next()
+		if err != nil {
+			return nil, err
+		}
+		return tok.std(tok, parse)
+	}
+	return parse.expression(0)
+}
+
+func (parse *Parser) advance(sym string) (*AST, error) {
+	token, err := parse.lexer.next()
+	if err != nil {
+		return nil, err
+	}
+	if token.Sym != sym {
+		return nil, parse.errorAt(token, "expected '%s', found %s", strings.ToLower(sym), describe(token))
+	}
+	return token, nil
+}
@@ -0,0 +1,135 @@
+package dicelang
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func benchmarkSimpleParse(cmd string, b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		var p *Parser
+		p = NewParser(cmd)
+		root, _ := p.Statements()
+		root.GetDiceSet()
+	}
+}
+func BenchmarkSimpleParse1(b *testing.B) {
+	benchmarkSimpleParse("roll 1d20", b)
+}
+func BenchmarkSimpleParse4d6Lx10(b *testing.B) {
+	benchmarkSimpleParse("roll 5d20", b)
+}
+func BenchmarkSimpleParse20d20x10(b *testing.B) {
+	benchmarkSimpleParse("roll 20d20 blue and twenty d10 red", b)
+}
+
+func TestParser_Diagnostics(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []Diagnostic
+		caret  string
+	}{
+		{
+			name:   "unexpected operator",
+			source: "1d20 + + 5",
+			want:   []Diagnostic{{SeverityError, "unexpected '+'", Position{1, 8}, Position{1, 9}}},
+			caret:  "1d20 + + 5\n       ^ unexpected '+'\n",
+		},
+		{
+			name:   "every statement is diagnosed",
+			source: "1d20 + + 5, 2d6 *\n1d4 $ 2",
+			want: []Diagnostic{
+				{SeverityError, "unexpected '+'", Position{1, 8}, Position{1, 9}},
+				{SeverityError, "unexpected end of line", Position{1, 18}, Position{1, 18}},
+				{SeverityError, "INVALID CHARACTER", Position{2, 5}, Position{2, 6}},
+			},
+		},
+		{
+			name:   "missing parenthesis",
+			source: "(1d4 + 2",
+			want:   []Diagnostic{{SeverityError, "unexpected end of input", Position{1, 9}, Position{1, 9}}},
+		},
+		{
+			name:   "arity",
+			source: "roll dc(1d20)",
+			want:   []Diagnostic{{SeverityError, "dc expects 2 arguments, found 1", Position{1, 6}, Position{1, 14}}},
+			caret:  "roll dc(1d20)\n     ^~~~~~~~ dc expects 2 arguments, found 1\n",
+		},
+		{
+			name:   "missing else",
+			source: "1d4 if 1d20 > 10",
+			want:   []Diagnostic{{SeverityError, "expected 'else', found end of input", Position{1, 17}, Position{1, 17}}},
+		},
+		{
+			name:   "stray block end",
+			source: "1d4 } 1d6",
+			want:   []Diagnostic{{SeverityError, "unexpected '}'", Position{1, 5}, Position{1, 6}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewParser(tt.source).Statements()
+			parseErr, ok := err.(*ParseError)
+			if !ok {
+				t.Fatalf("Statements() error = %v, want a *ParseError", err)
+			}
+			if len(parseErr.Diagnostics) != len(tt.want) {
+				t.Fatalf("Statements() diagnostics = %+v, want %+v", parseErr.Diagnostics, tt.want)
+			}
+			for i, d := range parseErr.Diagnostics {
+				if d != tt.want[i] {
+					t.Errorf("Statements() diagnostic %d = %+v, want %+v", i, d, tt.want[i])
+				}
+			}
+			if tt.caret != "" && parseErr.Caret() != tt.caret {
+				t.Errorf("ParseError.Caret() = %q, want %q", parseErr.Caret(), tt.caret)
+			}
+		})
+	}
+}
+
+//FuzzStatements checks that no source, however malformed, panics the parser or the interpreter
+func FuzzStatements(f *testing.F) {
+	for _, seed := range []string{
+		"roll 1d20",
+		"roll 1d20 + 5 fire, 2d6 cold",
+		"4d6-L1",
+		"(1d4 + 2) * 3",
+		"roll 1d4 if 1d20 > 10 else 2d4",
+		"roll 3d6 REP 6",
+		"roll dc(1d20 + 5, 15)",
+		"roll dpr(7, 15, 1d8 + 4)",
+		"{1d6 + 2d8 fire} * 2",
+		"50%",
+		"-(1d4)",
+		"1d20 + + 5",
+		"(1d4",
+		"1d2o",
+	} {
+		f.Add(seed)
+	}
+	limits := Limits{MaxDraws: 1000, MaxNodes: 1000, MaxDepth: 50, Timeout: time.Second}
+	f.Fuzz(func(t *testing.T, source string) {
+		tree, err := NewParser(source).Statements()
+		if err != nil {
+			if _, ok := err.(*ParseError); !ok {
+				t.Fatalf("Statements() error = %T, want a *ParseError", err)
+			}
+			return
+		}
+		tree.String()
+		ReStringAST(tree)
+		Format(tree)
+		for _, stmt := range tree.Children {
+			Simplify(stmt).GetDiceSet(WithBudget(NewBudget(context.Background(), limits)), WithSeed(1))
+			stmt.GetDiceSet(WithBudget(NewBudget(context.Background(), limits)), WithSeed(1))
+			AnalyzeWithBudget(stmt, NewBudget(context.Background(), limits))
+			stmt.DistributionWithBudget(NewBudget(context.Background(), limits))
+			if program, err := Compile(stmt); err == nil {
+				program.Run(WithBudget(NewBudget(context.Background(), limits)), WithSeed(1))
+			}
+		}
+	})
+}
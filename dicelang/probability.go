@@ -0,0 +1,204 @@
+package dicelang
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"math/big"
+)
+
+//DiceProbability returns a map of results to probabilities (in percent) for a given roll of dice
+//numberOfDice (int64): the number of dice in the throw.
+//sides (int64): the sides each die has
+//H: The number of high dice to drop (set to 0 to not drop any)
+//L: The number of low dice to drop (set to 0 to not drop any)
+//credit to https://stackoverflow.com/questions/50690348/calculate-probability-of-a-fair-dice-roll-in-non-exponential-time
+func DiceProbability(numberOfDice, sides, H, L int64) map[int64]float64 {
+	mw := newMemoWrap()
+	H, L = droppedDice(H, L)
+	d := mw.outcomes(numberOfDice, sides, H, L)
+	var sum, denominator float64
+	for _, v := range d {
+		sum += v
+	}
+	denominator = sum / 100
+	for k, v := range d {
+		d[k] = v / denominator
+	}
+	return d
+}
+
+//ExplodingDiceProbability returns a map of results to probabilities (in percent) for a throw of exploding dice.
+//Every die showing its highest face is rolled again and added, at most depth times per die.
+func ExplodingDiceProbability(numberOfDice, sides, depth int64) map[int64]float64 {
+	return poolProbability(numberOfDice, explodingDie(sides, depth))
+}
+
+//SuccessPoolProbability returns a map of net successes to probabilities (in percent) for a pool of dice.
+//Every die showing target or higher is a success, every die showing botch or lower subtracts a success (set botch to 0 for no botches).
+func SuccessPoolProbability(numberOfDice, sides, target, botch int64) map[int64]float64 {
+	return poolProbability(numberOfDice, successDie(explodingDie(sides, 0), target, botch))
+}
+
+//Probabilities returns a map of results to probabilities (in percent) for the dice,
+//accounting for drops, explosions and success counting.
+//Drops are ignored for exploding dice and success pools.
+func (d *Dice) Probabilities() map[int64]float64 {
+	if d.ExplodeDepth <= 0 && d.SuccessTarget <= 0 {
+		return DiceProbability(d.Count, d.Sides, d.DropHighest, d.DropLowest)
+	}
+	die := explodingDie(d.Sides, d.ExplodeDepth)
+	if d.SuccessTarget > 0 {
+		die = successDie(die, d.SuccessTarget, d.BotchTarget)
+	}
+	return poolProbability(d.Count, die)
+}
+
+//ProbabilitiesWithBudget is Probabilities, which fails with a Friendly error rather than work past the bounds of b
+func (d *Dice) ProbabilitiesWithBudget(b *Budget) (map[int64]float64, error) {
+	n, faces := float64(d.Count), float64(d.Sides)
+	//outcomes() is roughly cubic in the dice and quadratic in the sides, and a pool quadratic in both
+	cost := n * n * n * faces * faces
+	if d.ExplodeDepth > 0 || d.SuccessTarget > 0 {
+		faces *= float64(d.ExplodeDepth + 1)
+		cost = mapCost * n * n * faces * faces
+	}
+	if err := b.orUnlimited().spendWork(cost); err != nil {
+		return nil, err
+	}
+	return d.Probabilities(), nil
+}
+
+//explodingDie returns the probabilities (0-1) of every result of a single die which explodes at most depth times
+func explodingDie(sides, depth int64) map[int64]float64 {
+	d := make(map[int64]float64)
+	if sides < 1 {
+		return d
+	}
+	p := 1 / float64(sides)
+	chance := p
+	for explosions := int64(0); explosions <= depth; explosions++ {
+		// the last roll can't explode again, so it may show any face
+		lastFace := sides - 1
+		if explosions == depth || sides == 1 {
+			lastFace = sides
+		}
+		for face := int64(1); face <= lastFace; face++ {
+			d[explosions*sides+face] += chance
+		}
+		if sides == 1 {
+			break
+		}
+		chance *= p
+	}
+	return d
+}
+
+//successDie maps the results of a single die to 1 for a success, -1 for a botch and 0 otherwise
+func successDie(die map[int64]float64, target, botch int64) map[int64]float64 {
+	d := make(map[int64]float64)
+	for k, v := range die {
+		switch {
+		case k >= target:
+			d[1] += v
+		case k <= botch:
+			d[-1] += v
+		default:
+			d[0] += v
+		}
+	}
+	return d
+}
+
+//poolProbability sums numberOfDice independent copies of die and converts the result to percent
+func poolProbability(numberOfDice int64, die map[int64]float64) map[int64]float64 {
+	d := map[int64]float64{0: 1}
+	for i := int64(0); i < numberOfDice; i++ {
+		next := make(map[int64]float64)
+		for total, p := range d {
+			for face, q := range die {
+				next[total+face] += p * q
+			}
+		}
+		d = next
+	}
+	for k, v := range d {
+		d[k] = v * 100
+	}
+	return d
+}
+
+type memoWrap struct {
+	hasher hash.Hash
+	cache  map[string]map[int64]float64
+}
+
+func (mw *memoWrap) Save(args []int64, value map[int64]float64) {
+	b := make([]byte, 8)
+	for _, v := range args {
+		binary.LittleEndian.PutUint64(b, uint64(v))
+		mw.hasher.Write(b)
+	}
+	mw.cache[hex.EncodeToString(mw.hasher.Sum(nil))] = value
+	mw.hasher.Reset()
+}
+func (mw *memoWrap) Get(args []int64) map[int64]float64 {
+	b := make([]byte, 8)
+	for _, v := range args {
+		binary.LittleEndian.PutUint64(b, uint64(v))
+		mw.hasher.Write(b)
+	}
+	val := mw.cache[hex.EncodeToString(mw.hasher.Sum(nil))]
+	mw.hasher.Reset()
+	return val
+}
+
+func newMemoWrap() *memoWrap {
+	mw := new(memoWrap)
+	mw.hasher = md5.New()
+	mw.cache = make(map[string]map[int64]float64)
+	return mw
+}
+
+func (mw *memoWrap) outcomes(count, sides, dropHighest, dropLowest int64) map[int64]float64 {
+	args := []int64{count, sides, dropHighest, dropLowest}
+	if val := mw.Get(args); val != nil {
+		return val
+	}
+	d, d1 := make(map[int64]float64), make(map[int64]float64)
+	if count == 0 {
+		d[0] = 1
+	} else if sides != 0 {
+		for countShowingMax := int64(0); countShowingMax <= count; countShowingMax++ {
+			d1 = mw.outcomes(
+				count-countShowingMax,
+				sides-1,
+				max(dropHighest-countShowingMax, 0),
+				dropLowest)
+			countShowingMaxNotDropped := max(min(countShowingMax-dropHighest, count-dropHighest-dropLowest), 0)
+			sumShowingMax := countShowingMaxNotDropped * sides
+			multiplier := float64(new(big.Int).Binomial(count, countShowingMax).Int64())
+			for k, v := range d1 {
+				oldValue := d[sumShowingMax+k]
+				d[sumShowingMax+k] = oldValue + (multiplier * v)
+			}
+		}
+	}
+	mw.Save(args, d)
+	return d
+}
+
+func min(x, y int64) int64 {
+	if x < y {
+		return x
+	}
+	return y
+}
+
+func max(x, y int64) int64 {
+	if x > y {
+		return x
+	}
+	return y
+}
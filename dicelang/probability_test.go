@@ -100,3 +100,80 @@ func floatEquals(a, b float64) bool {
 	}
 	return false
 }
+
+func TestExplodingDiceProbability(t *testing.T) {
+	tests := []struct {
+		name         string
+		numberOfDice int64
+		sides        int64
+		depth        int64
+		want         map[int64]float64
+	}{
+		{name: "1d6 explodes once",
+			numberOfDice: 1, sides: 6, depth: 1,
+			want: map[int64]float64{
+				1: 100.0 / 6, 2: 100.0 / 6, 3: 100.0 / 6, 4: 100.0 / 6, 5: 100.0 / 6,
+				7: 100.0 / 36, 8: 100.0 / 36, 9: 100.0 / 36, 10: 100.0 / 36, 11: 100.0 / 36, 12: 100.0 / 36}},
+		{name: "1d2 explodes twice",
+			numberOfDice: 1, sides: 2, depth: 2,
+			want: map[int64]float64{1: 50, 3: 25, 5: 12.5, 6: 12.5}},
+		{name: "no depth is a normal roll",
+			numberOfDice: 2, sides: 4, depth: 0,
+			want: DiceProbability(2, 4, 0, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExplodingDiceProbability(tt.numberOfDice, tt.sides, tt.depth); !deepEqualFloatMap(got, tt.want) {
+				t.Errorf("ExplodingDiceProbability() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuccessPoolProbability(t *testing.T) {
+	tests := []struct {
+		name         string
+		numberOfDice int64
+		sides        int64
+		target       int64
+		botch        int64
+		want         map[int64]float64
+	}{
+		{name: "2d6 succeed on 5",
+			numberOfDice: 2, sides: 6, target: 5, botch: 0,
+			want: map[int64]float64{0: 400.0 / 9, 1: 400.0 / 9, 2: 100.0 / 9}},
+		{name: "2d10 succeed on 8, botch on 1",
+			numberOfDice: 2, sides: 10, target: 8, botch: 1,
+			want: map[int64]float64{
+				2:  9,
+				1:  36,
+				0:  42,
+				-1: 12,
+				-2: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SuccessPoolProbability(tt.numberOfDice, tt.sides, tt.target, tt.botch); !deepEqualFloatMap(got, tt.want) {
+				t.Errorf("SuccessPoolProbability() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDice_Probabilities(t *testing.T) {
+	dice := Dice{Count: 3, Sides: 6, ExplodeDepth: 2, SuccessTarget: 6}
+	probabilities := dice.Probabilities()
+	for i := 0; i < 100; i++ {
+		d := dice
+		total, err := d.Roll()
+		if err != nil {
+			t.Fatalf("Dice.Roll() error = %v", err)
+		}
+		if probabilities[total] == 0 {
+			t.Errorf("Dice.Roll() = %d, which Dice.Probabilities() says is impossible", total)
+		}
+		if total < d.Min || total > d.Max {
+			t.Errorf("Dice.Roll() = %d, outside of %d-%d", total, d.Min, d.Max)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package dicelang
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/aasmall/dicemagic/dicelang/errors"
+)
+
+//RandomSource provides the random numbers behind every die
+type RandomSource interface {
+	//Int63n returns a uniformly distributed number in [0, n)
+	Int63n(n int64) (int64, error)
+}
+
+//CryptoSource is a RandomSource backed by crypto/rand. It is the default and can't be reproduced.
+type CryptoSource struct{}
+
+//NewCryptoSource returns a RandomSource backed by crypto/rand
+func NewCryptoSource() *CryptoSource {
+	return &CryptoSource{}
+}
+
+//Int63n returns a uniformly distributed number in [0, n)
+func (s *CryptoSource) Int63n(n int64) (int64, error) {
+	if n <= 0 {
+		return 0, fmt.Errorf("Cannot make a random int of size zero")
+	}
+	nBig, err := rand.Int(rand.Reader, big.NewInt(n))
+	if err != nil {
+		return 0, fmt.Errorf("Couldn't make a random number. Out of entropy?")
+	}
+	return nBig.Int64(), nil
+}
+
+//PCGSource is a deterministic RandomSource. Two PCGSources with the same seed produce the same rolls.
+//It implements PCG-XSH-RR (http://www.pcg-random.org/) with 64 bits of state.
+type PCGSource struct {
+	state uint64
+	inc   uint64
+}
+
+const pcgMultiplier = 6364136223846793005
+
+//NewPCGSource returns a PCGSource seeded with seed
+func NewPCGSource(seed uint64) *PCGSource {
+	s := &PCGSource{inc: 1442695040888963407}
+	s.next()
+	s.state += seed
+	s.next()
+	return s
+}
+
+func (s *PCGSource) next() uint32 {
+	old := s.state
+	s.state = old*pcgMultiplier + s.inc
+	xorshifted := uint32(((old >> 18) ^ old) >> 27)
+	rot := uint32(old >> 59)
+	return (xorshifted >> rot) | (xorshifted << ((-rot) & 31))
+}
+
+//Int63n returns a uniformly distributed number in [0, n)
+func (s *PCGSource) Int63n(n int64) (int64, error) {
+	if n <= 0 {
+		return 0, fmt.Errorf("Cannot make a random int of size zero")
+	}
+	// reject the values that would bias the result towards low numbers
+	bound := uint64(n)
+	threshold := -bound % bound
+	for {
+		r := uint64(s.next())<<32 | uint64(s.next())
+		if r >= threshold {
+			return int64(r % bound), nil
+		}
+	}
+}
+
+//ScriptedSource is a RandomSource which returns a fixed sequence of numbers, for tests and replays.
+//A die with n sides shows the scripted number plus one.
+type ScriptedSource struct {
+	values []int64
+	index  int
+}
+
+//NewScriptedSource returns a RandomSource which returns each of values in order
+func NewScriptedSource(values ...int64) *ScriptedSource {
+	return &ScriptedSource{values: values}
+}
+
+//Int63n returns the next scripted number. It is an error to run out of numbers or for the next number to be outside of [0, n).
+func (s *ScriptedSource) Int63n(n int64) (int64, error) {
+	if s.index >= len(s.values) {
+		return 0, fmt.Errorf("scripted random source ran out of numbers after %d", len(s.values))
+	}
+	v := s.values[s.index]
+	if v < 0 || v >= n {
+		return 0, fmt.Errorf("scripted random number %d is outside of [0, %d)", v, n)
+	}
+	s.index++
+	return v, nil
+}
+
+//EvalOption configures how an AST is evaluated
+type EvalOption func(*DiceSet)
+
+//WithRandomSource rolls every die with src instead of crypto/rand
+func WithRandomSource(src RandomSource) EvalOption {
+	return func(ds *DiceSet) {
+		ds.random = src
+	}
+}
+
+//WithSeed rolls every die with a PCGSource seeded with seed
+func WithSeed(seed uint64) EvalOption {
+	return WithRandomSource(NewPCGSource(seed))
+}
+
+//randomSource returns the source for this DiceSet, defaulting to crypto/rand
+func (d *DiceSet) randomSource() RandomSource {
+	if d.random == nil {
+		return NewCryptoSource()
+	}
+	return d.random
+}
+
+//randomInt returns a number in [min, max] from src
+func randomInt(src RandomSource, min int64, max int64) (int64, error) {
+	if max <= 0 || min < 0 {
+		err := fmt.Errorf("Cannot make a random int of size zero")
+		return 0, err
+	}
+	size := max - min
+	if size == 0 {
+		return 1, nil
+	}
+	//Int63n does not return the max value, add 1
+	n, err := src.Int63n(size + 1)
+	if err != nil {
+		return 0, err
+	}
+	return n + min, nil
+}
+
+//Draw is a single number taken from a RandomSource
+type Draw struct {
+	//N is the exclusive upper bound of the draw
+	N int64
+	//Value is the number drawn, in [0, N)
+	Value int64
+}
+
+//RecordingSource is a RandomSource which records every draw made from another RandomSource
+type RecordingSource struct {
+	src        RandomSource
+	transcript []Draw
+}
+
+//NewRecordingSource returns a RandomSource which records every draw from src
+func NewRecordingSource(src RandomSource) *RecordingSource {
+	return &RecordingSource{src: src}
+}
+
+//Int63n returns a number from the underlying source and records it
+func (s *RecordingSource) Int63n(n int64) (int64, error) {
+	v, err := s.src.Int63n(n)
+	if err != nil {
+		return 0, err
+	}
+	s.transcript = append(s.transcript, Draw{N: n, Value: v})
+	return v, nil
+}
+
+//Transcript returns every draw made so far, in order
+func (s *RecordingSource) Transcript() []Draw {
+	return s.transcript
+}
+
+//ReplaySource is a RandomSource which replays a transcript recorded by a RecordingSource.
+//Replaying a transcript against the command that recorded it produces identical results.
+type ReplaySource struct {
+	transcript []Draw
+	index      int
+}
+
+//NewReplaySource returns a RandomSource which replays transcript
+func NewReplaySource(transcript []Draw) *ReplaySource {
+	return &ReplaySource{transcript: transcript}
+}
+
+//Int63n returns the next recorded draw. It is an error if the draw was recorded with a different bound.
+func (s *ReplaySource) Int63n(n int64) (int64, error) {
+	if s.index >= len(s.transcript) {
+		return 0, errors.NewDicelangError("That transcript ran out of rolls. Was it recorded for a different command?", errors.Friendly, nil)
+	}
+	d := s.transcript[s.index]
+	if d.N != n || d.Value < 0 || d.Value >= n {
+		return 0, errors.NewDicelangError(fmt.Sprintf("Roll %d of that transcript doesn't fit a d%d. Was it recorded for a different command?", s.index+1, n), errors.Friendly, nil)
+	}
+	s.index++
+	return d.Value, nil
+}
+
+//Done returns an error unless every draw in the transcript was replayed
+func (s *ReplaySource) Done() error {
+	if s.index != len(s.transcript) {
+		return errors.NewDicelangError(fmt.Sprintf("Only %d of %d rolls in that transcript were used. Was it recorded for a different command?", s.index, len(s.transcript)), errors.Friendly, nil)
+	}
+	return nil
+}
+
+//FormatTranscript formats a transcript as space separated "bound:value" pairs
+func FormatTranscript(transcript []Draw) string {
+	parts := make([]string, len(transcript))
+	for i, d := range transcript {
+		parts[i] = fmt.Sprintf("%d:%d", d.N, d.Value)
+	}
+	return strings.Join(parts, " ")
+}
+
+//ParseTranscript parses a transcript formatted by FormatTranscript
+func ParseTranscript(s string) ([]Draw, error) {
+	var transcript []Draw
+	for _, part := range strings.Fields(s) {
+		var d Draw
+		if _, err := fmt.Sscanf(part, "%d:%d", &d.N, &d.Value); err != nil || d.N <= 0 || d.Value < 0 || d.Value >= d.N {
+			return nil, errors.NewDicelangError(fmt.Sprintf("Could not read %q from that transcript", part), errors.Friendly, nil)
+		}
+		transcript = append(transcript, d)
+	}
+	return transcript, nil
+}
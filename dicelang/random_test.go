@@ -0,0 +1,176 @@
+package dicelang
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPCGSource_Deterministic(t *testing.T) {
+	root := NewParser("roll 10d20 fire + 4d6-L").testStatements()
+	_, first, err := root.GetDiceSet(WithSeed(42))
+	if err != nil {
+		t.Fatalf("AST.GetDiceSet() error = %v", err)
+	}
+	_, second, err := root.GetDiceSet(WithSeed(42))
+	if err != nil {
+		t.Fatalf("AST.GetDiceSet() error = %v", err)
+	}
+	if !reflect.DeepEqual(first.Dice, second.Dice) {
+		t.Errorf("same seed rolled %+v and %+v", first.Dice, second.Dice)
+	}
+	_, third, err := root.GetDiceSet(WithSeed(43))
+	if err != nil {
+		t.Fatalf("AST.GetDiceSet() error = %v", err)
+	}
+	if reflect.DeepEqual(first.Dice, third.Dice) {
+		t.Errorf("different seeds rolled the same dice: %+v", first.Dice)
+	}
+}
+
+func TestPCGSource_Int63n(t *testing.T) {
+	src := NewPCGSource(7)
+	m := make(map[int64]int)
+	for i := 0; i < 60000; i++ {
+		x, err := src.Int63n(6)
+		if err != nil {
+			t.Fatalf("PCGSource.Int63n() error = %v", err)
+		}
+		if x < 0 || x >= 6 {
+			t.Fatalf("PCGSource.Int63n() = %d, outside of [0, 6)", x)
+		}
+		m[x]++
+	}
+	for k, v := range m {
+		if v < 9000 || v > 11000 {
+			t.Errorf("PCGSource.Int63n() returned %d %d times out of 60000", k, v)
+		}
+	}
+}
+
+func TestScriptedSource(t *testing.T) {
+	tests := []struct {
+		name      string
+		cmd       string
+		values    []int64
+		wantTotal float64
+		wantFaces [][]int64
+		wantErr   bool
+	}{
+		{
+			name:      "exact faces",
+			cmd:       "roll 3d6 + 1d20",
+			values:    []int64{0, 5, 2, 19},
+			wantTotal: 30,
+			wantFaces: [][]int64{{1, 3, 6}, {20}}},
+		{
+			name:      "drop lowest",
+			cmd:       "roll 4d6-L",
+			values:    []int64{0, 1, 2, 3},
+			wantTotal: 9,
+			wantFaces: [][]int64{{1, 2, 3, 4}}},
+		{
+			name:    "out of numbers",
+			cmd:     "roll 3d6",
+			values:  []int64{0, 1},
+			wantErr: true},
+		{
+			name:    "number too large for the die",
+			cmd:     "roll 1d6",
+			values:  []int64{6},
+			wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			total, ds, err := NewParser(tt.cmd).testStatements().GetDiceSet(WithRandomSource(NewScriptedSource(tt.values...)))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AST.GetDiceSet() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if total != tt.wantTotal {
+				t.Errorf("AST.GetDiceSet() total = %v, want %v", total, tt.wantTotal)
+			}
+			var faces [][]int64
+			for _, d := range ds.Dice {
+				faces = append(faces, d.Faces)
+			}
+			if !reflect.DeepEqual(faces, tt.wantFaces) {
+				t.Errorf("AST.GetDiceSet() faces = %v, want %v", faces, tt.wantFaces)
+			}
+		})
+	}
+}
+
+func TestReplaySource(t *testing.T) {
+	cmds := []string{
+		"roll 3d6 fire and 1d20 ice",
+		"roll 4d6-L rep 6",
+		"1d4 + 2 if 1d20 > 10 else 1d8",
+		"{roll 2d8 + 3 fire, 1d6 cold}",
+	}
+	for _, cmd := range cmds {
+		t.Run(cmd, func(t *testing.T) {
+			root := NewParser(cmd).testStatements()
+			recorder := NewRecordingSource(NewCryptoSource())
+			total, ds, err := root.GetDiceSet(WithRandomSource(recorder))
+			if err != nil {
+				t.Fatalf("AST.GetDiceSet() error = %v", err)
+			}
+			transcript, err := ParseTranscript(FormatTranscript(recorder.Transcript()))
+			if err != nil {
+				t.Fatalf("ParseTranscript() error = %v", err)
+			}
+			replay := NewReplaySource(transcript)
+			replayTotal, replayDs, err := root.GetDiceSet(WithRandomSource(replay))
+			if err != nil {
+				t.Fatalf("AST.GetDiceSet() replay error = %v", err)
+			}
+			if err := replay.Done(); err != nil {
+				t.Errorf("ReplaySource.Done() error = %v", err)
+			}
+			if total != replayTotal || !reflect.DeepEqual(ds.Dice, replayDs.Dice) || !reflect.DeepEqual(ds.TotalsByColor, replayDs.TotalsByColor) {
+				t.Errorf("replay = %v %+v, want %v %+v", replayTotal, replayDs, total, ds)
+			}
+		})
+	}
+}
+
+func TestReplaySource_Mismatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		cmd        string
+		transcript []Draw
+	}{
+		{name: "different die", cmd: "roll 1d8", transcript: []Draw{{N: 6, Value: 1}}},
+		{name: "too short", cmd: "roll 2d6", transcript: []Draw{{N: 6, Value: 1}}},
+		{name: "too long", cmd: "roll 1d6", transcript: []Draw{{N: 6, Value: 1}, {N: 6, Value: 2}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replay := NewReplaySource(tt.transcript)
+			_, _, err := NewParser(tt.cmd).testStatements().GetDiceSet(WithRandomSource(replay))
+			if err == nil {
+				err = replay.Done()
+			}
+			if err == nil {
+				t.Errorf("replaying %v against %s should fail", tt.transcript, tt.cmd)
+			}
+		})
+	}
+}
+
+func TestParseTranscript(t *testing.T) {
+	got, err := ParseTranscript("6:0 20:19")
+	if err != nil {
+		t.Fatalf("ParseTranscript() error = %v", err)
+	}
+	if want := []Draw{{N: 6, Value: 0}, {N: 20, Value: 19}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseTranscript() = %v, want %v", got, want)
+	}
+	for _, bad := range []string{"6", "6:6", "x:1", "0:0"} {
+		if _, err := ParseTranscript(bad); err == nil {
+			t.Errorf("ParseTranscript(%q) expected an error", bad)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package dicelang
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/aasmall/dicemagic/dicelang/errors"
+)
+
+//registry holds every operator, built-in or registered, and is shared by every Lexer.
+//It is created in init, since the built-in evaluators refer back to it.
+var registry *tokenRegistry
+
+func init() {
+	registry = getTokenRegistry()
+}
+
+//NudFunc parses an operator at the start of an expression, like "-" in "-1d4"
+type NudFunc func(t *AST, p *Parser) (*AST, error)
+
+//LedFunc parses an operator following the expression left, like "+" in "1d4 + 2"
+type LedFunc func(t *AST, p *Parser, left *AST) (*AST, error)
+
+//EvalFunc evaluates a node, rolling any dice into ds, and returns its result
+type EvalFunc func(t *AST, ds *DiceSet) (float64, *DiceSet, error)
+
+//Operator is a symbol of the language, with how it parses and evaluates. Every built-in operator is an Operator too.
+type Operator struct {
+	//Symbol is how the operator is written. Words, like "DC", match regardless of case.
+	//Other symbols are one or two runes, like "!" or "<=".
+	Symbol string
+	//BindingPower is how tightly Led binds to the expression on its left, e.g. "+" is 50, "*" 60 and "d" 80
+	BindingPower int
+	//Nud parses the operator at the start of an expression
+	Nud NudFunc
+	//Led parses the operator following an expression
+	Led LedFunc
+	//Eval evaluates a node of the operator. Distribution and Analyze only know the built-in operators.
+	Eval EvalFunc
+}
+
+//Register adds op to the language, so house rules can ship as a package without forking dicelang.
+//A symbol may gain a Nud, Led or Eval it doesn't have yet, but not replace one.
+//Register is not safe to use while parsing or evaluating, so call it from an init function.
+func Register(op Operator) error {
+	sym := op.Symbol
+	if isWord(sym) {
+		sym = strings.ToUpper(sym)
+	} else if n := utf8.RuneCountInString(sym); n < 1 || n > 2 || strings.IndexFunc(sym, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsDigit(r) || isFirstIdentChar(r) || r == '#'
+	}) >= 0 {
+		return errors.Newf("cannot register %q: a symbol is a word, or one or two runes that are not letters, digits or spaces", op.Symbol)
+	}
+	if val, ok := registry.symTable[sym]; ok {
+		if (op.Nud != nil && val.nud != nil) || (op.Led != nil && val.led != nil) || (op.Eval != nil && val.evaluate != nil) {
+			return errors.Newf("cannot register %q: it is already defined", op.Symbol)
+		}
+	}
+	op.Symbol = sym
+	registry.define(op)
+	if !isWord(sym) {
+		for _, r := range sym {
+			if !registry.operatorChar(r) {
+				registry.operatorChars += string(r)
+			}
+		}
+	}
+	return nil
+}
+
+//Expression parses the expression that follows, binding operators tighter than rbp. It is for an Operator's Nud and Led.
+func (parse *Parser) Expression(rbp int) (*AST, error) {
+	return parse.expression(rbp)
+}
+
+//Advance consumes the next token, failing unless it is sym
+func (parse *Parser) Advance(sym string) (*AST, error) {
+	return parse.advance(sym)
+}
+
+//Peek returns the next token without consuming it
+func (parse *Parser) Peek() (*AST, error) {
+	return parse.lexer.peek()
+}
+
+//Errorf returns an error from t to the current token, for an Operator's Nud or Led to fail with
+func (parse *Parser) Errorf(t *AST, format string, a ...interface{}) error {
+	return parse.errorAt(t, format, a...)
+}
+
+//Eval evaluates t, counting it against the budget and trace of ds. It is for an Operator's Eval to evaluate its children.
+func (t *AST) Eval(ds *DiceSet) (float64, *DiceSet, error) {
+	return t.eval(ds)
+}
+
+//Infix is a left associative binary operator, like "+". Its node has the expressions on either side as children.
+func Infix(symbol string, bp int, eval EvalFunc) Operator {
+	return Operator{Symbol: symbol, BindingPower: bp, Eval: eval, Led: func(t *AST, p *Parser, left *AST) (*AST, error) {
+		t.Children = append(t.Children, left)
+		token, err := p.expression(t.BindingPower)
+		if err != nil {
+			return nil, err
+		}
+		t.Children = append(t.Children, token)
+		return t, nil
+	}}
+}
+
+//InfixRight is a right associative binary operator, so "a op b op c" is "a op (b op c)"
+func InfixRight(symbol string, bp int, eval EvalFunc) Operator {
+	return Operator{Symbol: symbol, BindingPower: bp, Eval: eval, Led: func(t *AST, p *Parser, left *AST) (*AST, error) {
+		t.Children = append(t.Children, left)
+		token, err := p.expression(t.BindingPower - 1)
+		if err != nil {
+			return nil, err
+		}
+		t.Children = append(t.Children, token)
+		return t, nil
+	}}
+}
+
+//Prefix is a unary operator, like "-", with the expression that follows as its child
+func Prefix(symbol string, eval EvalFunc) Operator {
+	return Operator{Symbol: symbol, Eval: eval, Nud: func(t *AST, p *Parser) (*AST, error) {
+		token, err := p.expression(200)
+		if err != nil {
+			return nil, err
+		}
+		t.Children = append(t.Children, token)
+		return t, nil
+	}}
+}
+
+//Postfix is a unary operator, like "%", with the expression before it as its child
+func Postfix(symbol string, bp int, eval EvalFunc) Operator {
+	return Operator{Symbol: symbol, BindingPower: bp, Eval: eval, Led: func(t *AST, p *Parser, left *AST) (*AST, error) {
+		t.Children = append(t.Children, left)
+		return t, nil
+	}}
+}
+
+//Function is followed by a parenthesized, comma separated list of between minArity and maxArity arguments, like "dc(1d20, 50%)".
+//Its node has the arguments as children.
+func Function(symbol string, minArity int, maxArity int, eval EvalFunc) Operator {
+	return Operator{Symbol: symbol, Eval: eval, Nud: func(t *AST, p *Parser) (*AST, error) {
+		if _, err := p.advance("("); err != nil {
+			return nil, err
+		}
+		for {
+			// bind tighter than "," so it separates arguments instead of merging them
+			arg, err := p.expression(25)
+			if err != nil {
+				return nil, err
+			}
+			t.Children = append(t.Children, arg)
+			next, err := p.lexer.peek()
+			if err != nil {
+				return nil, err
+			}
+			if next.Sym != "," {
+				break
+			}
+			if _, err := p.advance(","); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := p.advance(")"); err != nil {
+			return nil, err
+		}
+		if len(t.Children) < minArity || len(t.Children) > maxArity {
+			expected := fmt.Sprintf("%d", minArity)
+			if minArity != maxArity {
+				expected = fmt.Sprintf("between %d and %d", minArity, maxArity)
+			}
+			return nil, p.errorAt(t, "%s expects %s arguments, found %d", strings.ToLower(t.Value), expected, len(t.Children))
+		}
+		return t, nil
+	}}
+}
+
+//define adds op to the registry, filling in whatever the symbol doesn't have yet
+func (registry *tokenRegistry) define(op Operator) {
+	registry.register(op.Symbol, op.BindingPower, op.Nud, op.Led, nil)
+	if val := registry.symTable[op.Symbol]; val.evaluate == nil {
+		val.evaluate = op.Eval
+	}
+}
+
+//operatorChar reports whether r is part of an operator, rather than a word or number
+func (registry *tokenRegistry) operatorChar(r rune) bool {
+	return strings.ContainsRune(registry.operatorChars, r)
+}
+
+func isWord(s string) bool {
+	for i, r := range s {
+		if (i == 0 && !isFirstIdentChar(r)) || !isIdentChar(r) {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,109 @@
+package dicelang
+
+import (
+	"math"
+	"testing"
+)
+
+//evalBest is the largest of its arguments
+func evalBest(t *AST, ds *DiceSet) (float64, *DiceSet, error) {
+	best := math.Inf(-1)
+	for _, c := range t.Children {
+		x, ret, err := c.Eval(ds)
+		if err != nil {
+			return 0, ret, err
+		}
+		best = math.Max(best, x)
+	}
+	return best, ds, nil
+}
+
+//evalDouble is twice its child
+func evalDouble(t *AST, ds *DiceSet) (float64, *DiceSet, error) {
+	x, ds, err := t.Children[0].Eval(ds)
+	return 2 * x, ds, err
+}
+
+//withTestOperators gives a test a registry of its own, with "best" and "!" registered, so no other test sees them.
+//Defer the function it returns to restore the shared registry.
+func withTestOperators(t *testing.T) func() {
+	shared := registry
+	registry = getTokenRegistry()
+	restore := func() { registry = shared }
+	if err := Register(Function("best", 1, 10, evalBest)); err != nil {
+		restore()
+		t.Fatalf("Register(best) error = %v", err)
+	}
+	if err := Register(Postfix("!", 75, evalDouble)); err != nil {
+		restore()
+		t.Fatalf("Register(!) error = %v", err)
+	}
+	return restore
+}
+
+func TestRegister(t *testing.T) {
+	defer withTestOperators(t)()
+	tests := []struct {
+		source string
+		want   float64
+	}{
+		{"best(1, 3, 2)", 3},
+		{"BEST(2d1, 1d1)", 2},
+		{"3! + 1", 7},
+		{"best(1, 2)!", 4},
+		{"1 if 1 != 2 else 0", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			tree, err := NewParser(tt.source).Statements()
+			if err != nil {
+				t.Fatalf("Statements() error = %v", err)
+			}
+			got, _, err := tree.GetDiceSet()
+			if err != nil {
+				t.Fatalf("GetDiceSet() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GetDiceSet() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegister_Errors(t *testing.T) {
+	defer withTestOperators(t)()
+	tests := []struct {
+		name string
+		op   Operator
+	}{
+		{"built-in", Infix("+", 50, evalBest)},
+		{"registered", Function("BEST", 1, 1, evalBest)},
+		{"empty", Prefix("", evalBest)},
+		{"digits", Prefix("1x", evalBest)},
+		{"too long", Infix("<=>", 30, evalBest)},
+	}
+	for _, tt := range tests {
+		if err := Register(tt.op); err == nil {
+			t.Errorf("Register(%s) succeeded, want an error", tt.name)
+		}
+	}
+}
+
+func TestRegister_Diagnostics(t *testing.T) {
+	defer withTestOperators(t)()
+	_, err := NewParser("best(1d20, 1d20").Statements()
+	if _, ok := err.(*ParseError); !ok {
+		t.Fatalf("Statements() error = %v, want a *ParseError", err)
+	}
+}
+
+func TestRegister_Isolated(t *testing.T) {
+	restore := withTestOperators(t)
+	restore()
+	if _, ok := registry.symTable["BEST"]; ok {
+		t.Errorf("registry has best after the test restored it")
+	}
+	if _, err := NewParser("3!").Statements(); err == nil {
+		t.Errorf("Statements(3!) succeeded, want the shared registry without !")
+	}
+}
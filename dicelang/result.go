@@ -0,0 +1,217 @@
+package dicelang
+
+import (
+	"fmt"
+	"strings"
+)
+
+//ResultKind says how a ResultNode is rendered
+type ResultKind int
+
+const (
+	//TextResult is rendered as its Text
+	TextResult ResultKind = iota
+	//DiceResult is rendered as its Text followed by the faces of the dice it refers to
+	DiceResult
+	//OperatorResult is rendered as each of its children in order. Text holds the operator.
+	OperatorResult
+)
+
+//ResultNode is a structured rendering of a roll. Unlike AST.String, it never relies on format verbs,
+//and dice are referenced by index, so clients can render it safely in any order.
+type ResultNode struct {
+	Kind ResultKind
+	Text string
+	//Dice is the index in DiceSet.Dice of the dice a DiceResult refers to
+	Dice     int
+	Children []*ResultNode
+}
+
+//precedence is used to decide which operands need parentheses
+var precedence = map[string]int{
+	">": 1, "<": 1, ">=": 1, "<=": 1, "==": 1, "!=": 1,
+	"+": 2, "-": 2,
+	"*": 3, "/": 3,
+	"^": 4,
+}
+
+//ResultTree returns a structured rendering of the trace. Dice are indexed in the order they were rolled.
+func (tr *Trace) ResultTree() *ResultNode {
+	var rolled int
+	return tr.resultTree(&rolled)
+}
+
+//String renders the tree, calling faces for the faces of each DiceResult
+func (n *ResultNode) String(faces func(index int) string) string {
+	switch n.Kind {
+	case TextResult:
+		return n.Text
+	case DiceResult:
+		return fmt.Sprintf("%s(%s)", n.Text, faces(n.Dice))
+	}
+	var b strings.Builder
+	for _, c := range n.Children {
+		b.WriteString(c.String(faces))
+	}
+	return b.String()
+}
+
+func textResult(s string) *ResultNode {
+	return &ResultNode{Kind: TextResult, Text: s}
+}
+
+func operatorResult(op string, children ...*ResultNode) *ResultNode {
+	return &ResultNode{Kind: OperatorResult, Text: op, Children: children}
+}
+
+//resultTree walks the trace in evaluation order, so rolled counts the dice rolled before each node
+func (tr *Trace) resultTree(rolled *int) *ResultNode {
+	sym := strings.ToUpper(tr.Sym)
+	var operands []*ResultNode
+	var colors []string
+	for _, c := range tr.Children {
+		switch {
+		case c.Sym == "(IDENT)":
+			colors = append(colors, c.Value)
+		case c.Sym == "-H" || c.Sym == "-L":
+			//drops are rendered by the dice they apply to, but may carry the color
+			colors = append(colors, c.colors()...)
+		case sym == "D" && strings.ToUpper(c.Sym) == "(NUMBER)":
+			//the count and sides are rendered from the dice, but may carry the color
+			colors = append(colors, c.colors()...)
+		default:
+			operands = append(operands, c.resultTree(rolled))
+		}
+	}
+	var node *ResultNode
+	switch sym {
+	case "(NUMBER)":
+		node = textResult(tr.Value)
+	case "(IDENT)":
+		return textResult(tr.Value)
+	case "D":
+		node = &ResultNode{Kind: DiceResult, Dice: *rolled}
+		for _, d := range tr.Dice {
+			node.Text = fmt.Sprintf("%dd%d", d.Count, d.Sides)
+			if d.DropHighest > 0 {
+				node.Text += fmt.Sprintf("-H%d", d.DropHighest)
+			}
+			if d.DropLowest > 0 {
+				node.Text += fmt.Sprintf("-L%d", d.DropLowest)
+			}
+		}
+		*rolled += len(tr.Dice)
+	case "+", "*", "/", "^", ">", "<", ">=", "<=", "==", "!=":
+		node = binaryResult(tr, operands)
+	case "-":
+		if len(operands) == 1 {
+			node = operatorResult(tr.Sym, textResult("-"), tr.operandTrace(0).parenthesize(operands[0], precedence["^"]+1))
+		} else {
+			node = binaryResult(tr, operands)
+		}
+	case "%":
+		node = operatorResult(tr.Sym, tr.operandTrace(0).parenthesize(operandResult(operands, 0), precedence["^"]+1), textResult("%"))
+	case "IF":
+		node = operatorResult("if", operandResult(operands, 1), textResult(" if "), operandResult(operands, 0))
+	case "DC", "NEEDED", "DPR":
+		return textResult(tr.Value)
+	case "REP":
+		//the first operand is the number of repetitions
+		if len(operands) > 0 {
+			operands = operands[1:]
+		}
+		node = operatorResult("rep", joinResults(operands, ", ")...)
+	case "[":
+		node = listResult(operands)
+	case "(ARRAY)":
+		//the first operand is the number of values
+		if len(operands) > 0 {
+			operands = operands[1:]
+		}
+		node = listResult(operands)
+	case "(INDEX)":
+		node = operatorResult("[]", operandResult(operands, 0), textResult("["), operandResult(operands, 1), textResult("]"))
+	case "ROLL":
+		node = operatorResult("roll", append([]*ResultNode{textResult("Roll ")}, joinResults(operands, ", ")...)...)
+	case "{", "(ROOTNODE)":
+		if len(operands) == 1 && len(colors) == 0 {
+			return operands[0]
+		}
+		node = operatorResult(tr.Sym, joinResults(operands, ", ")...)
+	default:
+		args := joinResults(operands, ", ")
+		node = operatorResult(strings.ToLower(tr.Value), append(append([]*ResultNode{textResult(strings.ToLower(tr.Value) + "(")}, args...), textResult(")"))...)
+	}
+	if len(colors) > 0 {
+		node = operatorResult("", node, textResult(" "+strings.Join(colors, " ")))
+	}
+	return node
+}
+
+func listResult(values []*ResultNode) *ResultNode {
+	return operatorResult("[]", append(append([]*ResultNode{textResult("[")}, joinResults(values, ", ")...), textResult("]"))...)
+}
+
+func binaryResult(tr *Trace, operands []*ResultNode) *ResultNode {
+	p := precedence[tr.Sym]
+	node := operatorResult(tr.Sym)
+	for i, operand := range operands {
+		if i > 0 {
+			node.Children = append(node.Children, textResult(fmt.Sprintf(" %s ", tr.Sym)))
+			//operators are left associative, so an equal right hand side needs parentheses
+			operand = tr.operandTrace(i).parenthesize(operand, p+1)
+		} else {
+			operand = tr.operandTrace(i).parenthesize(operand, p)
+		}
+		node.Children = append(node.Children, operand)
+	}
+	return node
+}
+
+//operandTrace returns the ith child which is not a color
+func (tr *Trace) operandTrace(i int) *Trace {
+	if operands := tr.operands(); i < len(operands) {
+		return operands[i]
+	}
+	return &Trace{}
+}
+
+//parenthesize wraps node, the rendering of tr, in parentheses when tr binds looser than p
+func (tr *Trace) parenthesize(node *ResultNode, p int) *ResultNode {
+	if q, ok := precedence[tr.Sym]; ok && q < p && len(tr.operands()) > 1 {
+		return operatorResult("()", textResult("("), node, textResult(")"))
+	}
+	if strings.ToUpper(tr.Sym) == "IF" {
+		return operatorResult("()", textResult("("), node, textResult(")"))
+	}
+	return node
+}
+
+//operands returns the children of tr which are not colors
+func (tr *Trace) operands() []*Trace {
+	var operands []*Trace
+	for _, c := range tr.Children {
+		if c.Sym != "(IDENT)" {
+			operands = append(operands, c)
+		}
+	}
+	return operands
+}
+
+func operandResult(operands []*ResultNode, i int) *ResultNode {
+	if i < len(operands) {
+		return operands[i]
+	}
+	return textResult("")
+}
+
+func joinResults(nodes []*ResultNode, sep string) []*ResultNode {
+	var joined []*ResultNode
+	for i, n := range nodes {
+		if i > 0 {
+			joined = append(joined, textResult(sep))
+		}
+		joined = append(joined, n)
+	}
+	return joined
+}
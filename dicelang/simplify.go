@@ -0,0 +1,206 @@
+package dicelang
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+//WithSimplify evaluates Simplify(t) instead of t
+func WithSimplify() EvalOption {
+	return func(ds *DiceSet) {
+		ds.simplify = true
+	}
+}
+
+//Simplify returns a copy of t with constant sub-trees folded, like dice merged and no-op operations removed,
+//e.g. "1d6 + 3 + 1d6 + 0 * 2" becomes "2d6 + 3". Dice may be rolled in a different order, but every result is as likely as before.
+//Anything holding a color is left alone, since colors depend on where they appear.
+func Simplify(t *AST) *AST {
+	if t == nil {
+		return nil
+	}
+	s := &AST{Sym: t.Sym, Value: t.Value, line: t.line, col: t.col, BindingPower: t.BindingPower, nud: t.nud, led: t.led, std: t.std}
+	for _, c := range t.Children {
+		s.Children = append(s.Children, Simplify(c))
+	}
+	if hasColor(s) {
+		return s
+	}
+	//malformed trees, like a "+" without operands, are returned unchanged for eval to report
+	switch strings.ToUpper(s.Sym) {
+	case "+", "-":
+		if len(s.Children) == 2 {
+			return simplifySum(s)
+		}
+		if len(s.Children) != 1 {
+			return s
+		}
+		if x, ok := constant(s); ok {
+			return numberNode(x, s)
+		}
+		//--x is x
+		if inner := s.Children[0]; inner.Sym == "-" && len(inner.Children) == 1 && !hasColor(inner) {
+			return inner.Children[0]
+		}
+	case "*", "/", "^":
+		if len(s.Children) != 2 {
+			return s
+		}
+		left, leftConstant := constant(s.Children[0])
+		right, rightConstant := constant(s.Children[1])
+		switch {
+		case leftConstant && rightConstant:
+			if x := arithmetic(s.Sym, left, right); !math.IsInf(x, 0) && !math.IsNaN(x) {
+				return numberNode(x, s)
+			}
+		case rightConstant && right == 1:
+			return s.Children[0]
+		case leftConstant && left == 1 && s.Sym == "*":
+			return s.Children[1]
+		}
+	case "%":
+		if len(s.Children) != 1 {
+			return s
+		}
+		if x, ok := constant(s.Children[0]); ok {
+			return numberNode(x/100, s)
+		}
+	}
+	return s
+}
+
+//term is an operand of a chain of additions and subtractions
+type term struct {
+	negative bool
+	node     *AST
+}
+
+//simplifySum folds every constant in a chain of additions and subtractions, and merges like dice
+func simplifySum(t *AST) *AST {
+	var terms []term
+	collectTerms(t, false, &terms)
+	var sum float64
+	var out []term
+	for _, tm := range terms {
+		if x, ok := constant(tm.node); ok {
+			if tm.negative {
+				x = -x
+			}
+			sum += x
+			continue
+		}
+		if merged := mergeDice(out, tm); merged {
+			continue
+		}
+		out = append(out, tm)
+	}
+	if len(out) == 0 {
+		return numberNode(sum, t)
+	}
+	if sum != 0 {
+		out = append(out, term{negative: sum < 0, node: numberNode(math.Abs(sum), t)})
+	}
+	var s *AST
+	if out[0].negative {
+		s = registry.token("-", "-", t.line, t.col)
+		s.Children = []*AST{out[0].node}
+	} else {
+		s = out[0].node
+	}
+	for _, tm := range out[1:] {
+		op := "+"
+		if tm.negative {
+			op = "-"
+		}
+		left := s
+		s = registry.token(op, op, t.line, t.col)
+		s.Children = []*AST{left, tm.node}
+	}
+	return s
+}
+
+//collectTerms flattens a chain of uncolored additions and subtractions
+func collectTerms(t *AST, negative bool, terms *[]term) {
+	if (t.Sym == "+" || t.Sym == "-") && len(t.Children) == 2 && !hasColor(t) {
+		collectTerms(t.Children[0], negative, terms)
+		collectTerms(t.Children[1], negative != (t.Sym == "-"), terms)
+		return
+	}
+	*terms = append(*terms, term{negative: negative, node: t})
+}
+
+//mergeDice adds the dice of tm to a term in terms rolling the same dice, such as 1d6 and 2d6
+func mergeDice(terms []term, tm term) bool {
+	count, sides, ok := plainDice(tm.node)
+	if !ok {
+		return false
+	}
+	for i, other := range terms {
+		otherCount, otherSides, ok := plainDice(other.node)
+		if ok && other.negative == tm.negative && otherSides == sides {
+			merged := *other.node
+			merged.Children = []*AST{numberNode(count+otherCount, other.node.Children[0]), other.node.Children[1]}
+			terms[i].node = &merged
+			return true
+		}
+	}
+	return false
+}
+
+//plainDice returns the count and sides of dice with a whole number of dice, and nothing dropped
+func plainDice(t *AST) (float64, string, bool) {
+	if strings.ToUpper(t.Sym) != "D" || len(t.Children) != 2 {
+		return 0, "", false
+	}
+	count, ok := constant(t.Children[0])
+	if !ok || count < 0 || count != math.Trunc(count) || t.Children[1].Sym != "(NUMBER)" || len(t.Children[1].Children) > 0 {
+		return 0, "", false
+	}
+	return count, t.Children[1].Value, true
+}
+
+//constant returns the value of a number, or a negated number
+func constant(t *AST) (float64, bool) {
+	switch {
+	case t.Sym == "(NUMBER)" && len(t.Children) == 0:
+		x, err := strconv.ParseFloat(t.Value, 64)
+		return x, err == nil
+	case t.Sym == "-" && len(t.Children) == 1:
+		x, ok := constant(t.Children[0])
+		return -x, ok && t.Children[0].Sym == "(NUMBER)"
+	}
+	return 0, false
+}
+
+//numberNode returns a number for x, negated if x is negative, positioned like at
+func numberNode(x float64, at *AST) *AST {
+	n := registry.token("(NUMBER)", strconv.FormatFloat(math.Abs(x), 'f', -1, 64), at.line, at.col)
+	if x < 0 {
+		neg := registry.token("-", "-", at.line, at.col)
+		neg.Children = []*AST{n}
+		return neg
+	}
+	return n
+}
+
+func arithmetic(op string, x float64, y float64) float64 {
+	switch op {
+	case "*":
+		return x * y
+	case "/":
+		return x / y
+	case "^":
+		return math.Pow(x, y)
+	}
+	return math.NaN()
+}
+
+func hasColor(t *AST) bool {
+	for _, c := range t.Children {
+		if c.Sym == "(IDENT)" || hasColor(c) {
+			return true
+		}
+	}
+	return false
+}
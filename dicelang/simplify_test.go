@@ -0,0 +1,112 @@
+package dicelang
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSimplify(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"constants", "3+4*2", "11"},
+		{"negative constant", "2 - 5", "-3"},
+		{"like dice", "1d6+1d6", "2d6"},
+		{"zero chain", "1d20 + 5 + 0 + 0", "1d20 + 5"},
+		{"terms cancel", "1d6 + 3 + 1d6 - 3", "2d6"},
+		{"constants are moved last", "1d4 + 2 - 5", "1d4 - 3"},
+		{"multiply by one", "2*1d8*1", "2 * 1d8"},
+		{"divide by one", "(1d8+2)/1", "1d8 + 2"},
+		{"dice count", "(1+1)d6", "2d6"},
+		{"double negation", "--2 + 1d6", "1d6 + 2"},
+		{"percent", "50% * 1d100", "0.5 * 1d100"},
+		{"subtracted dice are kept", "1d4 - 1d4", "1d4 - 1d4"},
+		{"different dice are kept", "1d4 + 1d6 + 1d4", "2d4 + 1d6"},
+		{"dropped dice are kept", "4d6-L1 + 1d6", "4d6-L1 + 1d6"},
+		{"colors are kept", "1d6 fire + 0", "1d6 Fire + 0"},
+		{"division by zero is kept", "1/0", "1 / 0"},
+		{"statements", "roll 1d20+0, 2+2", "roll 1d20, 4"},
+		{"functions", "dc(1d20+1+1, 0.5)", "dc(1d20 + 2, 0.5)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := NewParser(tt.source).Statements()
+			if err != nil {
+				t.Fatalf("Statements() error = %v", err)
+			}
+			got, err := Format(Simplify(root))
+			if err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Simplify() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSimplify_DoesNotModify(t *testing.T) {
+	root, err := NewParser("1d6 + 1d6 + 0").Statements()
+	if err != nil {
+		t.Fatalf("Statements() error = %v", err)
+	}
+	before := astString(root)
+	Simplify(root)
+	if after := astString(root); after != before {
+		t.Errorf("Simplify() modified its input from %s to %s", before, after)
+	}
+}
+
+func TestSimplify_Malformed(t *testing.T) {
+	one := &AST{Sym: "(NUMBER)", Value: "1"}
+	for _, root := range []*AST{
+		{Sym: "+", Value: "+"},
+		{Sym: "-", Value: "-"},
+		{Sym: "-", Value: "-", Children: []*AST{one, one, one}},
+		{Sym: "%", Value: "%"},
+		{Sym: "%", Value: "%", Children: []*AST{one, one}},
+		{Sym: "*", Value: "*", Children: []*AST{one}},
+	} {
+		if got := Simplify(root); astString(got) != astString(root) {
+			t.Errorf("Simplify(%s) = %s, want it unchanged", astString(root), astString(got))
+		}
+	}
+}
+
+func TestSimplify_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		root := randomRoot(r)
+		simplified := Simplify(root)
+		if _, err := Format(simplified); err != nil {
+			t.Fatalf("Format() error = %v for simplified AST %s", err, astString(simplified))
+		}
+		//trees without dice always evaluate to the same total
+		if countDice(root) > 0 {
+			continue
+		}
+		want, _, wantErr := root.Children[0].GetDiceSet()
+		got, _, err := root.Children[0].GetDiceSet(WithSimplify())
+		if (err == nil) != (wantErr == nil) || (err == nil && !closeTo(got, want)) {
+			t.Fatalf("GetDiceSet(WithSimplify()) = %v (%v), want %v (%v) for AST %s", got, err, want, wantErr, astString(root.Children[0]))
+		}
+	}
+}
+
+func countDice(t *AST) int {
+	n := 0
+	if t.Sym == "D" {
+		n++
+	}
+	for _, c := range t.Children {
+		n += countDice(c)
+	}
+	return n
+}
+
+func closeTo(a float64, b float64) bool {
+	d := a - b
+	return d < 1e-9*(1+a*a) && -d < 1e-9*(1+a*a)
+}
@@ -0,0 +1,117 @@
+package dicelang
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+//ParserOption configures a Parser
+type ParserOption func(*Parser)
+
+//WithKnownWords adds words, like the names of saved commands, to the keywords suggested for typos
+func WithKnownWords(words ...string) ParserOption {
+	return func(parse *Parser) {
+		parse.words = append(parse.words, words...)
+	}
+}
+
+//keywords returns the words of the language, in lower case, like "roll" and "dc"
+func (registry *tokenRegistry) keywords() []string {
+	var words []string
+	for sym := range registry.symTable {
+		if len(sym) > 1 && strings.IndexFunc(sym, func(r rune) bool { return !unicode.IsLetter(r) }) < 0 {
+			words = append(words, strings.ToLower(sym))
+		}
+	}
+	sort.Strings(words)
+	return words
+}
+
+//Suggest returns the word in candidates closest to word, when it is close enough to be a typo. Case is ignored.
+func Suggest(word string, candidates []string) (string, bool) {
+	return closest(word, candidates, maxInt(1, len([]rune(word))/3))
+}
+
+func closest(word string, candidates []string, maxDistance int) (string, bool) {
+	best, bestDistance := "", maxDistance+1
+	for _, c := range candidates {
+		d := editDistance(strings.ToLower(word), strings.ToLower(c))
+		if d < bestDistance {
+			best, bestDistance = c, d
+		}
+	}
+	return best, bestDistance > 0 && bestDistance <= maxDistance
+}
+
+//suggest returns the keyword or known word closest to word
+func (parse *Parser) suggest(word string, maxDistance int) (string, bool) {
+	return closest(word, append(parse.lexer.tokReg.keywords(), parse.words...), maxDistance)
+}
+
+//known reports whether word is one of the parser's known words
+func (parse *Parser) known(word string) bool {
+	for _, w := range parse.words {
+		if strings.EqualFold(w, word) {
+			return true
+		}
+	}
+	return false
+}
+
+//editDistance counts the insertions, deletions, substitutions and swaps of adjacent runes that turn a into b
+func editDistance(a string, b string) int {
+	s, t := []rune(a), []rune(b)
+	d := make([][]int, len(s)+1)
+	for i := range d {
+		d[i] = make([]int, len(t)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			d[i][j] = minInt(minInt(d[i-1][j]+1, d[i][j-1]+1), d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(s)][len(t)]
+}
+
+//lookalikeDigits replaces letters that look like digits, so "2o" reads "20"
+var lookalikeDigits = strings.NewReplacer("o", "0", "O", "0", "l", "1", "I", "1")
+
+//colorWarning warns when a color is probably a typo, like "1d2o" or "1d20 adavntage"
+func (parse *Parser) colorWarning(t *AST, left *AST) {
+	if parse.Version() < 2 && keepsOrDrops(t.Value) {
+		parse.warn(t, "'%s' was read as a color, since keeping and dropping dice with kh, kl, dh and dl needs '%s' at the start", t.Value, Pragma(2))
+		return
+	}
+	if left.Sym == "(NUMBER)" && left.line == t.line && left.col+len([]rune(left.Value)) == t.col {
+		if digits := lookalikeDigits.Replace(t.Value); strings.IndexFunc(digits, func(r rune) bool { return !unicode.IsDigit(r) }) < 0 {
+			parse.warn(t, "'%s' was read as a color, did you mean '%s%s'?", t.Value, left.Value, digits)
+			return
+		}
+	}
+	if parse.known(t.Value) {
+		return
+	}
+	//colors are often short words, so only longer words are close enough to be typos
+	if s, ok := parse.suggest(t.Value, len([]rune(t.Value))/4); ok {
+		parse.warn(t, "'%s' was read as a color, did you mean '%s'?", t.Value, s)
+	}
+}
+
+func (parse *Parser) warn(t *AST, format string, a ...interface{}) {
+	d := parse.errorAt(t, format, a...)
+	d.Severity = SeverityWarning
+	d.End = Position{Line: t.line, Col: t.col + len([]rune(t.Value))}
+	parse.diagnostics = append(parse.diagnostics, *d)
+}
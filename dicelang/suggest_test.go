@@ -0,0 +1,107 @@
+package dicelang
+
+import (
+	"testing"
+)
+
+func Test_editDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"roll", "roll", 0},
+		{"rol", "roll", 1},
+		{"adavntage", "advantage", 1},
+		{"neded", "needed", 1},
+		{"fire", "dpr", 3},
+		{"", "rep", 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"advantage", "disadvantage", "fireball"}
+	tests := []struct {
+		word   string
+		want   string
+		wantOk bool
+	}{
+		{"adavntage", "advantage", true},
+		{"Fireblal", "fireball", true},
+		{"disadvantage", "", false},
+		{"sneak", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := Suggest(tt.word, candidates)
+		if got != tt.want && tt.wantOk || ok != tt.wantOk {
+			t.Errorf("Suggest(%q) = %q, %v, want %q, %v", tt.word, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
+
+func TestParser_Suggestions(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		words  []string
+		want   []Diagnostic
+	}{
+		{
+			name:   "misspelled keyword",
+			source: "rol 1d20",
+			want:   []Diagnostic{{SeverityError, "unexpected 'rol', did you mean 'roll'?", Position{1, 1}, Position{1, 4}}},
+		},
+		{
+			name:   "letter for a digit",
+			source: "roll 1d2o",
+			want:   []Diagnostic{{SeverityWarning, "'o' was read as a color, did you mean '20'?", Position{1, 9}, Position{1, 10}}},
+		},
+		{
+			name:   "saved command",
+			source: "1d20 adavntage",
+			words:  []string{"advantage"},
+			want:   []Diagnostic{{SeverityWarning, "'adavntage' was read as a color, did you mean 'advantage'?", Position{1, 6}, Position{1, 15}}},
+		},
+		{
+			name:   "keep without a pragma",
+			source: "[4d6kh3; 6]",
+			want:   []Diagnostic{{SeverityWarning, "'kh3' was read as a color, since keeping and dropping dice with kh, kl, dh and dl needs '#dicelang 2' at the start", Position{1, 5}, Position{1, 8}}},
+		},
+		{
+			name:   "drop without a pragma",
+			source: "4d6 DL",
+			want:   []Diagnostic{{SeverityWarning, "'DL' was read as a color, since keeping and dropping dice with kh, kl, dh and dl needs '#dicelang 2' at the start", Position{1, 5}, Position{1, 7}}},
+		},
+		{
+			name:   "keep with a pragma",
+			source: "#dicelang 2\n[4d6kh3; 6]",
+		},
+		{
+			name:   "short colors are not typos",
+			source: "roll 20d20 blue and twenty d10 red",
+		},
+		{
+			name:   "separated color",
+			source: "roll 1d20 o",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser(tt.source, WithKnownWords(tt.words...))
+			p.Statements()
+			got := p.Diagnostics()
+			if len(got) != len(tt.want) {
+				t.Fatalf("Diagnostics() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Diagnostics()[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package dicelang
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//Trace records the evaluation of an AST. It mirrors the AST, except REP nodes and lists like "[4d6dl1; 6]"
+//hold their number of repetitions followed by one child per repetition.
+type Trace struct {
+	Sym    string
+	Value  string
+	Result float64
+	//Values holds every value of a list, whose Result is their sum
+	Values   []float64
+	Dice     []Dice
+	Children []*Trace
+}
+
+type tracer struct {
+	root  *Trace
+	stack []*Trace
+}
+
+//WithTrace fills trace with every intermediate value while evaluating
+func WithTrace(trace *Trace) EvalOption {
+	return func(ds *DiceSet) {
+		ds.tracer = &tracer{root: trace}
+	}
+}
+
+//push starts tracing a node. The first node traced is the root.
+func (tr *tracer) push(t *AST) *Trace {
+	node := &Trace{Sym: t.Sym, Value: t.Value}
+	if len(tr.stack) == 0 {
+		*tr.root = *node
+		node = tr.root
+	} else {
+		parent := tr.stack[len(tr.stack)-1]
+		parent.Children = append(parent.Children, node)
+	}
+	tr.stack = append(tr.stack, node)
+	return node
+}
+
+func (tr *tracer) pop() {
+	tr.stack = tr.stack[:len(tr.stack)-1]
+}
+
+//traceEval evaluates t, recording the result and any dice it rolled
+func (t *AST) traceEval(ds *DiceSet) (float64, *DiceSet, error) {
+	node := ds.tracer.push(t)
+	defer ds.tracer.pop()
+	rolled := len(ds.Dice)
+	x, ret, err := t.evalNode(ds)
+	node.Result = x
+	if err == nil && t.IsList() {
+		node.Values = ds.list
+	}
+	switch strings.ToUpper(t.Sym) {
+	case "D":
+		if len(ds.Dice) > rolled {
+			node.Dice = append(node.Dice, ds.Dice[len(ds.Dice)-1])
+		}
+	case "DC", "NEEDED", "DPR":
+		//functions calculate from expressions they never roll, so explain them by their arguments
+		node.Children = nil
+		node.Value, _ = t.String()
+	}
+	return x, ret, err
+}
+
+//traceBoolean evaluates a comparison, recording 1 for true and 0 for false
+func (t *AST) traceBoolean(ds *DiceSet) (bool, *DiceSet, error) {
+	node := ds.tracer.push(t)
+	defer ds.tracer.pop()
+	res, ret, err := t.evalBoolean(ds)
+	if res {
+		node.Result = 1
+	}
+	return res, ret, err
+}
+
+//String explains how the trace arrived at its result, e.g. "(2d6=[3, 5]=8) + 3 = 11"
+func (tr *Trace) String() string {
+	return tr.explain(true)
+}
+
+func (tr *Trace) explain(withResult bool) string {
+	var operands []string
+	var colors []string
+	for _, c := range tr.Children {
+		if c.Sym == "(IDENT)" {
+			colors = append(colors, c.Value)
+			continue
+		}
+		if c.Sym == "-H" || c.Sym == "-L" {
+			//drops are explained by the dice they apply to, but may carry the color
+			colors = append(colors, c.colors()...)
+			continue
+		}
+		operands = append(operands, c.operand())
+	}
+	result := formatResult(tr.Result)
+	if tr.Values != nil {
+		result = formatValues(tr.Values)
+	}
+	var s string
+	switch strings.ToUpper(tr.Sym) {
+	case "(NUMBER)":
+		s = tr.Value
+		withResult = false
+	case "D":
+		//colors belong after the faces, not on the count or sides
+		operands = nil
+		for _, c := range tr.Children {
+			switch {
+			case c.Sym == "(IDENT)" || c.Sym == "-H" || c.Sym == "-L":
+			case strings.ToUpper(c.Sym) == "(NUMBER)":
+				operands = append(operands, c.Value)
+				colors = append(colors, c.colors()...)
+			default:
+				operands = append(operands, c.operand())
+			}
+		}
+		var faces []string
+		for _, d := range tr.Dice {
+			for _, f := range d.Faces {
+				faces = append(faces, strconv.FormatInt(f, 10))
+			}
+		}
+		dropped := ""
+		for _, d := range tr.Dice {
+			if d.DropHighest > 0 {
+				dropped += fmt.Sprintf("-H%d", d.DropHighest)
+			}
+			if d.DropLowest > 0 {
+				dropped += fmt.Sprintf("-L%d", d.DropLowest)
+			}
+		}
+		s = fmt.Sprintf("%sd%s%s=[%s]", operandAt(operands, 0), operandAt(operands, 1), dropped, strings.Join(faces, ", "))
+		if len(colors) > 0 {
+			s = fmt.Sprintf("%s %s", s, strings.Join(colors, " "))
+			colors = nil
+		}
+		s = fmt.Sprintf("%s=%s", s, result)
+		withResult = false
+	case "+", "*", "/", "^":
+		s = strings.Join(operands, fmt.Sprintf(" %s ", tr.Sym))
+	case "-":
+		if len(operands) == 1 {
+			s = "-" + operands[0]
+			withResult = strings.ToUpper(tr.Children[0].Sym) != "(NUMBER)"
+		} else {
+			s = strings.Join(operands, " - ")
+		}
+	case "%":
+		s = operandAt(operands, 0) + "%"
+	case ">", "<", ">=", "<=", "==", "!=":
+		s = strings.Join(operands, fmt.Sprintf(" %s ", tr.Sym))
+		result = strconv.FormatBool(tr.Result == 1)
+	case "IF":
+		if len(operands) > 1 {
+			s = fmt.Sprintf("%s if %s", operands[1], operands[0])
+		}
+	case "DC", "NEEDED", "DPR":
+		s = tr.Value
+		colors = nil
+	case "REP":
+		if len(operands) > 0 {
+			s = fmt.Sprintf("%s times: %s", operands[0], strings.Join(operands[1:], "; "))
+		}
+	case "[":
+		s = "[" + strings.Join(operands, ", ") + "]"
+	case "(ARRAY)":
+		if len(operands) > 0 {
+			s = "[" + strings.Join(operands[1:], ", ") + "]"
+		}
+	case "(INDEX)":
+		s = fmt.Sprintf("%s[%s]", operandAt(operands, 0), operandAt(operands, 1))
+	case "{", "ROLL", "(ROOTNODE)":
+		if len(operands) == 1 {
+			//a single statement needs no grouping
+			for _, c := range tr.Children {
+				if c.Sym != "(IDENT)" {
+					operands[0] = c.explain(withResult)
+				}
+			}
+		}
+		s = strings.Join(operands, ", ")
+		withResult = withResult && len(operands) > 1
+	case "(IDENT)":
+		return tr.Value
+	default:
+		s = fmt.Sprintf("%s(%s)", strings.ToLower(tr.Value), strings.Join(operands, ", "))
+	}
+	if len(colors) > 0 {
+		s = fmt.Sprintf("%s %s", s, strings.Join(colors, " "))
+	}
+	if withResult {
+		s = fmt.Sprintf("%s = %s", s, result)
+	}
+	return s
+}
+
+//colors returns every color beneath tr
+func (tr *Trace) colors() []string {
+	var colors []string
+	for _, c := range tr.Children {
+		if c.Sym == "(IDENT)" {
+			colors = append(colors, c.Value)
+		}
+		colors = append(colors, c.colors()...)
+	}
+	return colors
+}
+
+//operand explains a child, wrapping it in parentheses unless it is a single number
+func (tr *Trace) operand() string {
+	if strings.ToUpper(tr.Sym) == "(NUMBER)" {
+		return tr.explain(true)
+	}
+	return "(" + tr.explain(true) + ")"
+}
+
+func operandAt(operands []string, i int) string {
+	if i < len(operands) {
+		return operands[i]
+	}
+	return ""
+}
+
+func formatResult(x float64) string {
+	return strconv.FormatFloat(x, 'f', -1, 64)
+}
+
+//formatValues writes the values of a list, e.g. "15, 14, 13, 12, 10, 8"
+func formatValues(values []float64) string {
+	s := make([]string, len(values))
+	for i, x := range values {
+		s[i] = formatResult(x)
+	}
+	return strings.Join(s, ", ")
+}
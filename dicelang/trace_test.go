@@ -0,0 +1,76 @@
+package dicelang
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestTrace_String(t *testing.T) {
+	tests := []struct {
+		name   string
+		cmd    string
+		script []int64
+		want   string
+	}{
+		{"arithmetic", "(2d6+3)*2", []int64{2, 4}, "((2d6=[3, 5]=8) + 3 = 11) * 2 = 22"},
+		{"drop lowest with color", "roll 3d6-L1 fire", []int64{0, 3, 5}, "3d6-L1=[1, 4, 6] Fire=10"},
+		{"colors", "roll 1d4 fire and 1d8+1 ice", []int64{3, 0}, "(1d4=[4] Fire=4), ((1d8=[1]=1) + 1 Ice = 2) = 6"},
+		{"repeat", "roll 1d20 rep 3", []int64{0, 9, 19}, "3 times: (1d20=[1]=1); (1d20=[10]=10); (1d20=[20]=20) = 31"},
+		{"subtraction", "roll 1d4 - 2", []int64{1}, "(1d4=[2]=2) - 2 = 0"},
+		{"percent", "roll 2d10 * 50%", []int64{4, 5}, "(2d10=[5, 6]=11) * (50% = 0.5) = 5.5"},
+		{"if", "roll 1d4 + 2 if 1d20 > 10 else 1d8", []int64{14, 2}, "((1d4=[3]=3) + 2 = 5) if ((1d20=[15]=15) > 10 = true) = 5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := NewParser(tt.cmd).testStatements()
+			var tr Trace
+			total, _, err := root.GetDiceSet(WithRandomSource(NewScriptedSource(tt.script...)), WithTrace(&tr))
+			if err != nil {
+				t.Fatalf("AST.GetDiceSet() error = %v", err)
+			}
+			if got := tr.String(); got != tt.want {
+				t.Errorf("Trace.String() = %q, want %q (total %v)", got, tt.want, total)
+			}
+		})
+	}
+}
+
+func TestTrace_ResultTree(t *testing.T) {
+	tests := []struct {
+		name   string
+		cmd    string
+		script []int64
+		want   string
+	}{
+		{"arithmetic", "(2d6+3)*2", []int64{2, 4}, "(2d6(3, 5) + 3) * 2"},
+		{"drop lowest with color", "roll 3d6-L1 fire", []int64{0, 3, 5}, "Roll 3d6-L1(1, 4, 6) Fire"},
+		{"colors", "roll 1d4 fire and 1d8+1 ice", []int64{3, 0}, "Roll 1d4(4) Fire, 1d8(1) + 1 Ice"},
+		{"repeat", "roll 1d20 rep 3", []int64{0, 9, 19}, "Roll 1d20(1), 1d20(10), 1d20(20)"},
+		{"associativity", "roll 1d4 - 2 - (3 - 1)", []int64{1}, "Roll 1d4(2) - 2 - (3 - 1)"},
+		{"percent", "roll 2d10 * 50%", []int64{4, 5}, "Roll 2d10(5, 6) * 50%"},
+		{"negative", "roll -(1d4+1)", []int64{1}, "Roll -(1d4(2) + 1)"},
+		{"rolled count", "roll (1d4)d6", []int64{1, 0, 5}, "Roll 2d6(1, 6)"},
+		{"if", "roll 1d4 + 2 if 1d20 > 10 else 1d8", []int64{14, 2}, "Roll 1d4(3) + 2 if 1d20(15) > 10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := NewParser(tt.cmd).testStatements()
+			var tr Trace
+			_, ds, err := root.GetDiceSet(WithRandomSource(NewScriptedSource(tt.script...)), WithTrace(&tr))
+			if err != nil {
+				t.Fatalf("AST.GetDiceSet() error = %v", err)
+			}
+			faces := func(i int) string {
+				var s []string
+				for _, f := range ds.Dice[i].Faces {
+					s = append(s, strconv.FormatInt(f, 10))
+				}
+				return strings.Join(s, ", ")
+			}
+			if got := tr.ResultTree().String(faces); got != tt.want {
+				t.Errorf("ResultNode.String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
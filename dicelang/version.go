@@ -0,0 +1,95 @@
+package dicelang
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+//LatestVersion is the newest version of the language.
+//Source is read as version 1 unless it starts with a pragma like "#dicelang 2", so saved commands keep their meaning.
+//
+//Version 2 drops dice with "dl" and "dh", as in "4d6dl1", so "-" always subtracts. Version 1 drops with "-L" and "-H".
+const LatestVersion = 2
+
+//Pragma returns the comment that marks source as a version of the language
+func Pragma(version int) string {
+	return fmt.Sprintf("#dicelang %d", version)
+}
+
+//WithVersion reads source that has no pragma as version, instead of version 1
+func WithVersion(version int) ParserOption {
+	return func(parse *Parser) {
+		if !parse.pragma {
+			parse.setVersion(version, Position{Line: 1, Col: 1})
+		}
+	}
+}
+
+//Version returns the version of the language the parser reads
+func (parse *Parser) Version() int {
+	return parse.lexer.version
+}
+
+func (parse *Parser) setVersion(version int, pos Position) {
+	if version < 1 || version > LatestVersion {
+		parse.diagnostics = append(parse.diagnostics, Diagnostic{
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("unknown dicelang version %d, the latest is %d", version, LatestVersion),
+			Start:    pos,
+			End:      Position{Line: pos.Line, Col: pos.Col + len(Pragma(version))},
+		})
+		return
+	}
+	parse.lexer.version = version
+}
+
+//findPragma looks for "#dicelang N" in the blank and comment lines before the first statement of source
+func findPragma(source string) (version int, pos Position, found bool) {
+	for i, line := range strings.Split(source, "\n") {
+		trimmed := strings.TrimLeft(line, " \t\r")
+		if trimmed == "" {
+			continue
+		}
+		if trimmed[0] != '#' {
+			break
+		}
+		fields := strings.Fields(trimmed[1:])
+		if len(fields) == 0 || fields[0] != "dicelang" {
+			continue
+		}
+		pos = Position{Line: i + 1, Col: len(line) - len(trimmed) + 1}
+		if len(fields) < 2 {
+			return 0, pos, true
+		}
+		version, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return 0, pos, true
+		}
+		return version, pos, true
+	}
+	return 0, Position{}, false
+}
+
+//keepsOrDrops reports whether word is spelled like a keep or drop of version 2, like "kh", "KL3" or "dl1"
+func keepsOrDrops(word string) bool {
+	if len(word) < 2 || !strings.ContainsRune("kKdD", rune(word[0])) || !strings.ContainsRune("hHlL", rune(word[1])) {
+		return false
+	}
+	return strings.IndexFunc(word[2:], func(r rune) bool { return !unicode.IsDigit(r) }) < 0
+}
+
+//Migrate rewrites source in the latest version of the language, with the canonical formatter.
+//Migrating source that is already the latest version returns it formatted.
+func Migrate(source string) (string, error) {
+	root, err := NewParser(source).Statements()
+	if err != nil {
+		return "", err
+	}
+	s, err := FormatVersion(root, LatestVersion)
+	if err != nil {
+		return "", err
+	}
+	return Pragma(LatestVersion) + "\n" + s, nil
+}
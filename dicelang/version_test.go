@@ -0,0 +1,182 @@
+package dicelang
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/aasmall/dicemagic/dicelang/errors"
+)
+
+func TestParser_Version(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		opts   []ParserOption
+		want   int
+	}{
+		{"no pragma", "4d6-L", nil, 1},
+		{"pragma", "#dicelang 2\n4d6dl", nil, 2},
+		{"pragma after comments", "\n# strength\n  #dicelang 1\n4d6-L", nil, 1},
+		{"pragma after a statement is a comment", "1d20\n#dicelang 2", nil, 1},
+		{"default version", "4d6dl", []ParserOption{WithVersion(2)}, 2},
+		{"pragma beats the default", "#dicelang 1\n4d6-L", []ParserOption{WithVersion(2)}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser(tt.source, tt.opts...)
+			if _, err := p.Statements(); err != nil {
+				t.Fatalf("Statements() error = %v", err)
+			}
+			if got := p.Version(); got != tt.want {
+				t.Errorf("Version() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParser_VersionErrors(t *testing.T) {
+	for _, source := range []string{"#dicelang 2\n4d6-L", "#dicelang 3\n1d20", "#dicelang two\n1d20", "#dicelang\n1d20"} {
+		_, err := NewParser(source).Statements()
+		if _, ok := err.(*ParseError); !ok {
+			t.Errorf("Statements(%q) error = %v, want a *ParseError", source, err)
+		}
+	}
+}
+
+func TestLexer_Drops(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"v1 drop", "4d6-L1", "4d6-L1"},
+		{"v2 drop lowest", "#dicelang 2\n4d6dl1", "#dicelang 2\n4d6dl1"},
+		{"v2 drop highest", "#dicelang 2\n2d20DH", "#dicelang 2\n2d20dh1"},
+		{"v2 minus is subtraction", "#dicelang 2\n4d6-1", "#dicelang 2\n4d6 - 1"},
+		{"v2 colored drop", "#dicelang 2\n(4d6 fire) dl 1", "#dicelang 2\n4d6 Fire dl1"},
+		{"v2 words starting with dl", "#dicelang 2\n1d4 dlight", "#dicelang 2\n1d4 Dlight"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FormatSource(tt.source)
+			if err != nil {
+				t.Fatalf("FormatSource() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("FormatSource() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"roll 4d6-L", "#dicelang 2\nroll 4d6dl1"},
+		{"(3d6-H2) fire + 1", "#dicelang 2\n(3d6dh2) Fire + 1"},
+		{"1d20+5 fire, 2d6 cold", "#dicelang 2\n1d20 + 5 Fire, 2d6 Cold"},
+		{"#dicelang 2\n4d6dl", "#dicelang 2\n4d6dl1"},
+	}
+	for _, tt := range tests {
+		got, err := Migrate(tt.source)
+		if err != nil {
+			t.Fatalf("Migrate(%q) error = %v", tt.source, err)
+		}
+		if got != tt.want {
+			t.Errorf("Migrate(%q) = %q, want %q", tt.source, got, tt.want)
+		}
+		again, err := Migrate(got)
+		if err != nil || again != got {
+			t.Errorf("Migrate(%q) = %q, %v, want it unchanged", got, again, err)
+		}
+	}
+}
+
+func TestMigrate_KeepColors(t *testing.T) {
+	for _, source := range []string{"4d6 kh", "2d6 dl1 + 3", "[4d6kh3; 6]", "4d6 dh2", "1d8 KL"} {
+		got, err := Migrate(source)
+		if err == nil {
+			t.Errorf("Migrate(%q) = %q, want an error rather than a color read as a keep or drop", source, got)
+			continue
+		}
+		if e, ok := err.(*errors.DicelangError); !ok || e.Code != errors.Friendly {
+			t.Errorf("Migrate(%q) error = %v, want a Friendly error", source, err)
+		}
+	}
+}
+
+func TestFormatVersion_RoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		root := randomRoot(r)
+		source, err := FormatVersion(root, LatestVersion)
+		if hasKeepColor(root) {
+			if err == nil {
+				t.Fatalf("FormatVersion() = %q, want an error for a color read as a keep or drop\nAST %s", source, astString(root))
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("FormatVersion() error = %v for AST %s", err, astString(root))
+		}
+		parsed, err := NewParser(source, WithVersion(LatestVersion)).Statements()
+		if err != nil {
+			t.Fatalf("FormatVersion() = %q, which doesn't parse: %v\nAST %s", source, err, astString(root))
+		}
+		if !equalAST(root, parsed) {
+			t.Fatalf("FormatVersion() = %q, which parses to\n%s\nwant\n%s", source, astString(parsed), astString(root))
+		}
+	}
+}
+
+func hasKeepColor(t *AST) bool {
+	if t.Sym == "(IDENT)" && keepsOrDrops(t.Value) {
+		return true
+	}
+	for _, c := range t.Children {
+		if hasKeepColor(c) {
+			return true
+		}
+	}
+	return false
+}
+
+//TestDrops_Version pins the drops of version 1, which commands saved before versions existed rely on
+func TestDrops_Version(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want float64
+		kept float64
+	}{
+		{"4d6-H1", 6, 3},
+		{"4d6-L1", 9, 3},
+		{"4d6-H1-L1", 6, 3},
+		{"4d6-L1-H1", 6, 3},
+		{"4d6-H2-L2", 3, 2},
+		{"4d6-L3", 4, 1},
+		{"#dicelang 2\n4d6dh1dl1", 6, 3},
+		{"#dicelang 2\n4d6kh3kl3", 6, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cmd, func(t *testing.T) {
+			root := NewParser(tt.cmd).testStatements()
+			//faces 1, 2, 3 and 4
+			got, _, err := root.GetDiceSet(WithRandomSource(NewScriptedSource(0, 1, 2, 3)))
+			if err != nil {
+				t.Fatalf("AST.GetDiceSet() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("AST.GetDiceSet() = %v, want %v", got, tt.want)
+			}
+			d, err := root.Distribution()
+			if err != nil {
+				t.Fatalf("AST.Distribution() error = %v", err)
+			}
+			if max := d.Outcomes()[len(d)-1]; max != tt.kept*6 {
+				t.Errorf("AST.Distribution() is at most %v, want %v", max, tt.kept*6)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package dicelang
+
+import (
+	"fmt"
+)
+
+//Run runs the Program, like AST.GetDiceSet. Tracing and simplifying evaluate the AST the Program was compiled from.
+func (p *Program) Run(opts ...EvalOption) (float64, DiceSet, error) {
+	ds := &DiceSet{}
+	for _, o := range opts {
+		o(ds)
+	}
+	if ds.tracer != nil || ds.simplify {
+		return p.root.GetDiceSet(opts...)
+	}
+	x, err := p.run(ds)
+	if err != nil {
+		return 0, *ds, err
+	}
+	return x, *ds, nil
+}
+
+func (p *Program) run(ds *DiceSet) (float64, error) {
+	b := ds.budget
+	//the tree leaves every node it entered when it stops, and every arithmetic expression, and so does the Program
+	depth, colorDepth := ds.depth, ds.colorDepth
+	defer func() { ds.depth, ds.colorDepth = depth, colorDepth }()
+	stack := make([]float64, 0, 16)
+	//marks holds the dice rolled before each arithmetic expression and the repetitions left of each REP
+	var marks []int
+	for pc := 0; pc < len(p.code); pc++ {
+		in := &p.code[pc]
+		switch in.op {
+		case opEnter:
+			if b != nil {
+				ds.depth++
+				if err := b.enter(ds.depth); err != nil {
+					return 0, err
+				}
+			}
+		case opLeave:
+			if b != nil {
+				ds.depth--
+			}
+		case opPush:
+			stack = append(stack, in.x)
+		case opColor:
+			ds.PushColor(p.nodes[in.arg].Value)
+		case opEval:
+			x, _, err := p.nodes[in.arg].eval(ds)
+			if err != nil {
+				return 0, err
+			}
+			stack = append(stack, x)
+		case opColorEval:
+			if _, _, err := p.nodes[in.arg].eval(ds); err != nil {
+				return 0, err
+			}
+		case opSum:
+			var x float64
+			for _, y := range stack[len(stack)-in.arg:] {
+				x += y
+			}
+			stack = append(stack[:len(stack)-in.arg], x)
+		case opDrop:
+			n := int64(stack[len(stack)-1])
+			stack = stack[:len(stack)-1]
+			switch in.sym {
+			case "-H":
+				ds.dropHighest = n
+			case "-L":
+				ds.dropLowest = n
+			}
+		case opDice:
+			args := stack[len(stack)-in.arg:]
+			res, err := ds.PushAndRoll(Dice{Count: int64(args[0]), Sides: int64(args[1])})
+			if err != nil {
+				return 0, err
+			}
+			stack = append(stack[:len(stack)-in.arg], float64(res))
+		case opBeginArithmetic:
+			marks = append(marks, len(ds.Dice))
+			ds.colorDepth++
+		case opArithmetic:
+			ds.colorDepth--
+			x, err := foldArithmetic(in.sym, stack[len(stack)-in.arg:])
+			if err != nil {
+				return 0, err
+			}
+			diceCount := marks[len(marks)-1]
+			marks = marks[:len(marks)-1]
+			if err := ds.colorArithmetic(len(ds.Dice)-diceCount, x); err != nil {
+				return 0, err
+			}
+			stack = append(stack[:len(stack)-in.arg], x)
+		case opPercent:
+			stack[len(stack)-1] /= 100
+		case opRepeat:
+			marks = append(marks, int(stack[len(stack)-1]))
+			stack[len(stack)-1] = 0
+		case opNext:
+			if marks[len(marks)-1] <= 0 {
+				marks = marks[:len(marks)-1]
+				pc = in.arg - 1
+				continue
+			}
+			marks[len(marks)-1]--
+		case opAdd:
+			stack[len(stack)-2] += stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+		case opJump:
+			pc = in.arg - 1
+		case opCompare:
+			left, right := stack[len(stack)-2], stack[len(stack)-1]
+			stack = stack[:len(stack)-2]
+			var holds bool
+			switch in.sym {
+			case ">":
+				holds = left > right
+			case "<":
+				holds = left < right
+			case "<=":
+				holds = left <= right
+			case ">=":
+				holds = left >= right
+			case "==":
+				holds = left == right
+			case "!=":
+				holds = left != right
+			default:
+				return 0, fmt.Errorf("Bad bool")
+			}
+			stack = append(stack, 0)
+			if holds {
+				stack[len(stack)-1] = 1
+			}
+		case opJumpFalse:
+			x := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if x == 0 {
+				pc = in.arg - 1
+			}
+		case opFail:
+			return 0, p.fails[in.arg]()
+		}
+	}
+	if len(stack) != 1 {
+		return 0, fmt.Errorf("Program left %d values", len(stack))
+	}
+	return stack[0], nil
+}
@@ -1,3 +0,0 @@
-module github.com/aasmall/dicemagic/internal/dicelang/errors
-
-go 1.12
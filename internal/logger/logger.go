@@ -113,6 +113,15 @@ func New(projectID string, options ...LoggerOption) *Logger {
 
 var std = newLocal()
 
+// NewLocal returns a Logger which writes to stdout, without a Stackdriver client, as tests need
+func NewLocal(options ...LoggerOption) *Logger {
+	opts := LoggerOptions{}
+	for _, o := range options {
+		o(&opts)
+	}
+	return &Logger{mu: new(sync.Mutex), prefix: opts.Prefix, debug: opts.Debug, local: true}
+}
+
 func newLocal() *Logger {
 	return &Logger{mu: new(sync.Mutex), prefix: "", local: true}
 }
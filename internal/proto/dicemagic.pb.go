@@ -22,12 +22,85 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
+type ResultNode_Kind int32
+
+const (
+	// Rendered as Text
+	ResultNode_TEXT ResultNode_Kind = 0
+	// Rendered as Text followed by the faces of DiceSet.Dice[DiceIndex]
+	ResultNode_DICE ResultNode_Kind = 1
+	// Rendered as each of Children in order. Text holds the operator.
+	ResultNode_OPERATOR ResultNode_Kind = 2
+)
+
+var ResultNode_Kind_name = map[int32]string{
+	0: "TEXT",
+	1: "DICE",
+	2: "OPERATOR",
+}
+
+var ResultNode_Kind_value = map[string]int32{
+	"TEXT":     0,
+	"DICE":     1,
+	"OPERATOR": 2,
+}
+
+func (x ResultNode_Kind) String() string {
+	return proto.EnumName(ResultNode_Kind_name, int32(x))
+}
+
+func (ResultNode_Kind) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_63ba8fa741f5f7d8, []int{11, 0}
+}
+
+type Diagnostic_Severity int32
+
+const (
+	Diagnostic_ERROR   Diagnostic_Severity = 0
+	Diagnostic_WARNING Diagnostic_Severity = 1
+)
+
+var Diagnostic_Severity_name = map[int32]string{
+	0: "ERROR",
+	1: "WARNING",
+}
+
+var Diagnostic_Severity_value = map[string]int32{
+	"ERROR":   0,
+	"WARNING": 1,
+}
+
+func (x Diagnostic_Severity) String() string {
+	return proto.EnumName(Diagnostic_Severity_name, int32(x))
+}
+
+func (Diagnostic_Severity) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_63ba8fa741f5f7d8, []int{22, 0}
+}
+
 // The request message containing the command. Input validation preformed on the server side.
 type RollRequest struct {
-	Cmd                  string   `protobuf:"bytes,1,opt,name=cmd,proto3" json:"cmd,omitempty"`
-	Probabilities        bool     `protobuf:"varint,2,opt,name=probabilities,proto3" json:"probabilities,omitempty"`
-	Chart                bool     `protobuf:"varint,3,opt,name=chart,proto3" json:"chart,omitempty"`
-	RootOnly             bool     `protobuf:"varint,4,opt,name=rootOnly,proto3" json:"rootOnly,omitempty"`
+	Cmd           string `protobuf:"bytes,1,opt,name=cmd,proto3" json:"cmd,omitempty"`
+	Probabilities bool   `protobuf:"varint,2,opt,name=probabilities,proto3" json:"probabilities,omitempty"`
+	Chart         bool   `protobuf:"varint,3,opt,name=chart,proto3" json:"chart,omitempty"`
+	RootOnly      bool   `protobuf:"varint,4,opt,name=rootOnly,proto3" json:"rootOnly,omitempty"`
+	Statistics    bool   `protobuf:"varint,5,opt,name=statistics,proto3" json:"statistics,omitempty"`
+	// Rolls are reproducible when seeded. 0 rolls with crypto/rand.
+	Seed uint64 `protobuf:"varint,6,opt,name=seed,proto3" json:"seed,omitempty"`
+	// Replays a transcript from a previous RollResponse instead of rolling. Takes precedence over seed.
+	Replay []*Draw `protobuf:"bytes,7,rep,name=replay,proto3" json:"replay,omitempty"`
+	// Derives every roll from the seeds of a provably fair session. Takes precedence over seed.
+	Fair *FairSeed `protobuf:"bytes,8,opt,name=fair,proto3" json:"fair,omitempty"`
+	// Lowers the server's evaluation limits for this request. Zero values use the server's limits.
+	Limits *EvaluationLimits `protobuf:"bytes,9,opt,name=limits,proto3" json:"limits,omitempty"`
+	// Populates the Explanation of every DiceSet
+	Explain bool `protobuf:"varint,10,opt,name=explain,proto3" json:"explain,omitempty"`
+	// Folds constants and merges like dice before rolling, so ReString shows "1d20 + 5" for "1d20 + 5 + 0 + 0"
+	Simplify bool `protobuf:"varint,11,opt,name=simplify,proto3" json:"simplify,omitempty"`
+	// Populates the Bounds and BoundsByColor of every DiceSet
+	Bounds bool `protobuf:"varint,12,opt,name=bounds,proto3" json:"bounds,omitempty"`
+	// Words to suggest when the command has a typo, like the names of the user's saved commands
+	KnownWords           []string `protobuf:"bytes,13,rep,name=knownWords,proto3" json:"knownWords,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -86,23 +159,211 @@ func (m *RollRequest) GetRootOnly() bool {
 	return false
 }
 
+func (m *RollRequest) GetStatistics() bool {
+	if m != nil {
+		return m.Statistics
+	}
+	return false
+}
+
+func (m *RollRequest) GetSeed() uint64 {
+	if m != nil {
+		return m.Seed
+	}
+	return 0
+}
+
+func (m *RollRequest) GetReplay() []*Draw {
+	if m != nil {
+		return m.Replay
+	}
+	return nil
+}
+
+func (m *RollRequest) GetFair() *FairSeed {
+	if m != nil {
+		return m.Fair
+	}
+	return nil
+}
+
+func (m *RollRequest) GetLimits() *EvaluationLimits {
+	if m != nil {
+		return m.Limits
+	}
+	return nil
+}
+
+func (m *RollRequest) GetExplain() bool {
+	if m != nil {
+		return m.Explain
+	}
+	return false
+}
+
+func (m *RollRequest) GetSimplify() bool {
+	if m != nil {
+		return m.Simplify
+	}
+	return false
+}
+
+func (m *RollRequest) GetBounds() bool {
+	if m != nil {
+		return m.Bounds
+	}
+	return false
+}
+
+func (m *RollRequest) GetKnownWords() []string {
+	if m != nil {
+		return m.KnownWords
+	}
+	return nil
+}
+
+type EvaluationLimits struct {
+	MaxDraws             int64    `protobuf:"varint,1,opt,name=maxDraws,proto3" json:"maxDraws,omitempty"`
+	MaxNodes             int64    `protobuf:"varint,2,opt,name=maxNodes,proto3" json:"maxNodes,omitempty"`
+	MaxDepth             int32    `protobuf:"varint,3,opt,name=maxDepth,proto3" json:"maxDepth,omitempty"`
+	TimeoutMs            int64    `protobuf:"varint,4,opt,name=timeoutMs,proto3" json:"timeoutMs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EvaluationLimits) Reset()         { *m = EvaluationLimits{} }
+func (m *EvaluationLimits) String() string { return proto.CompactTextString(m) }
+func (*EvaluationLimits) ProtoMessage()    {}
+func (*EvaluationLimits) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63ba8fa741f5f7d8, []int{1}
+}
+
+func (m *EvaluationLimits) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EvaluationLimits.Unmarshal(m, b)
+}
+func (m *EvaluationLimits) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EvaluationLimits.Marshal(b, m, deterministic)
+}
+func (m *EvaluationLimits) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EvaluationLimits.Merge(m, src)
+}
+func (m *EvaluationLimits) XXX_Size() int {
+	return xxx_messageInfo_EvaluationLimits.Size(m)
+}
+func (m *EvaluationLimits) XXX_DiscardUnknown() {
+	xxx_messageInfo_EvaluationLimits.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EvaluationLimits proto.InternalMessageInfo
+
+func (m *EvaluationLimits) GetMaxDraws() int64 {
+	if m != nil {
+		return m.MaxDraws
+	}
+	return 0
+}
+
+func (m *EvaluationLimits) GetMaxNodes() int64 {
+	if m != nil {
+		return m.MaxNodes
+	}
+	return 0
+}
+
+func (m *EvaluationLimits) GetMaxDepth() int32 {
+	if m != nil {
+		return m.MaxDepth
+	}
+	return 0
+}
+
+func (m *EvaluationLimits) GetTimeoutMs() int64 {
+	if m != nil {
+		return m.TimeoutMs
+	}
+	return 0
+}
+
+// The seeds of a single roll in a provably fair session
+type FairSeed struct {
+	ServerSeed           string   `protobuf:"bytes,1,opt,name=serverSeed,proto3" json:"serverSeed,omitempty"`
+	ClientSeed           string   `protobuf:"bytes,2,opt,name=clientSeed,proto3" json:"clientSeed,omitempty"`
+	Nonce                int64    `protobuf:"varint,3,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FairSeed) Reset()         { *m = FairSeed{} }
+func (m *FairSeed) String() string { return proto.CompactTextString(m) }
+func (*FairSeed) ProtoMessage()    {}
+func (*FairSeed) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63ba8fa741f5f7d8, []int{2}
+}
+
+func (m *FairSeed) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FairSeed.Unmarshal(m, b)
+}
+func (m *FairSeed) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FairSeed.Marshal(b, m, deterministic)
+}
+func (m *FairSeed) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FairSeed.Merge(m, src)
+}
+func (m *FairSeed) XXX_Size() int {
+	return xxx_messageInfo_FairSeed.Size(m)
+}
+func (m *FairSeed) XXX_DiscardUnknown() {
+	xxx_messageInfo_FairSeed.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FairSeed proto.InternalMessageInfo
+
+func (m *FairSeed) GetServerSeed() string {
+	if m != nil {
+		return m.ServerSeed
+	}
+	return ""
+}
+
+func (m *FairSeed) GetClientSeed() string {
+	if m != nil {
+		return m.ClientSeed
+	}
+	return ""
+}
+
+func (m *FairSeed) GetNonce() int64 {
+	if m != nil {
+		return m.Nonce
+	}
+	return 0
+}
+
 // The response message containing one DiceSet. If the command warrents multiple dice-sets, they will be merged
 type RollResponse struct {
-	Cmd                  string     `protobuf:"bytes,1,opt,name=Cmd,proto3" json:"Cmd,omitempty"`
-	DiceSet              *DiceSet   `protobuf:"bytes,2,opt,name=DiceSet,proto3" json:"DiceSet,omitempty"`
-	DiceSets             []*DiceSet `protobuf:"bytes,3,rep,name=DiceSets,proto3" json:"DiceSets,omitempty"`
-	Ok                   bool       `protobuf:"varint,4,opt,name=Ok,proto3" json:"Ok,omitempty"`
-	Error                *RollError `protobuf:"bytes,5,opt,name=Error,proto3" json:"Error,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
-	XXX_unrecognized     []byte     `json:"-"`
-	XXX_sizecache        int32      `json:"-"`
+	Cmd      string     `protobuf:"bytes,1,opt,name=Cmd,proto3" json:"Cmd,omitempty"`
+	DiceSet  *DiceSet   `protobuf:"bytes,2,opt,name=DiceSet,proto3" json:"DiceSet,omitempty"`
+	DiceSets []*DiceSet `protobuf:"bytes,3,rep,name=DiceSets,proto3" json:"DiceSets,omitempty"`
+	Ok       bool       `protobuf:"varint,4,opt,name=Ok,proto3" json:"Ok,omitempty"`
+	Error    *RollError `protobuf:"bytes,5,opt,name=Error,proto3" json:"Error,omitempty"`
+	// Every random number drawn to produce this response, in order
+	Transcript []*Draw `protobuf:"bytes,6,rep,name=Transcript,proto3" json:"Transcript,omitempty"`
+	// Proves this response came from dice-server, when the server is configured to sign rolls
+	Receipt *Receipt `protobuf:"bytes,7,opt,name=Receipt,proto3" json:"Receipt,omitempty"`
+	// Problems with a command that still rolled, like a typo read as a color
+	Warnings             []*Diagnostic `protobuf:"bytes,8,rep,name=Warnings,proto3" json:"Warnings,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
 }
 
 func (m *RollResponse) Reset()         { *m = RollResponse{} }
 func (m *RollResponse) String() string { return proto.CompactTextString(m) }
 func (*RollResponse) ProtoMessage()    {}
 func (*RollResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_63ba8fa741f5f7d8, []int{1}
+	return fileDescriptor_63ba8fa741f5f7d8, []int{3}
 }
 
 func (m *RollResponse) XXX_Unmarshal(b []byte) error {
@@ -158,6 +419,219 @@ func (m *RollResponse) GetError() *RollError {
 	return nil
 }
 
+func (m *RollResponse) GetTranscript() []*Draw {
+	if m != nil {
+		return m.Transcript
+	}
+	return nil
+}
+
+func (m *RollResponse) GetReceipt() *Receipt {
+	if m != nil {
+		return m.Receipt
+	}
+	return nil
+}
+
+func (m *RollResponse) GetWarnings() []*Diagnostic {
+	if m != nil {
+		return m.Warnings
+	}
+	return nil
+}
+
+// A signature over everything a RollResponse shows, its transcript, and the time it was rolled
+type Receipt struct {
+	// Unix time in nanoseconds
+	Timestamp int64 `protobuf:"varint,1,opt,name=Timestamp,proto3" json:"Timestamp,omitempty"`
+	// HMAC-SHA256
+	Signature            []byte   `protobuf:"bytes,2,opt,name=Signature,proto3" json:"Signature,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Receipt) Reset()         { *m = Receipt{} }
+func (m *Receipt) String() string { return proto.CompactTextString(m) }
+func (*Receipt) ProtoMessage()    {}
+func (*Receipt) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63ba8fa741f5f7d8, []int{4}
+}
+
+func (m *Receipt) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Receipt.Unmarshal(m, b)
+}
+func (m *Receipt) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Receipt.Marshal(b, m, deterministic)
+}
+func (m *Receipt) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Receipt.Merge(m, src)
+}
+func (m *Receipt) XXX_Size() int {
+	return xxx_messageInfo_Receipt.Size(m)
+}
+func (m *Receipt) XXX_DiscardUnknown() {
+	xxx_messageInfo_Receipt.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Receipt proto.InternalMessageInfo
+
+func (m *Receipt) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *Receipt) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+type VerifyRequest struct {
+	Roll                 *RollResponse `protobuf:"bytes,1,opt,name=Roll,proto3" json:"Roll,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *VerifyRequest) Reset()         { *m = VerifyRequest{} }
+func (m *VerifyRequest) String() string { return proto.CompactTextString(m) }
+func (*VerifyRequest) ProtoMessage()    {}
+func (*VerifyRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63ba8fa741f5f7d8, []int{5}
+}
+
+func (m *VerifyRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VerifyRequest.Unmarshal(m, b)
+}
+func (m *VerifyRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VerifyRequest.Marshal(b, m, deterministic)
+}
+func (m *VerifyRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VerifyRequest.Merge(m, src)
+}
+func (m *VerifyRequest) XXX_Size() int {
+	return xxx_messageInfo_VerifyRequest.Size(m)
+}
+func (m *VerifyRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_VerifyRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VerifyRequest proto.InternalMessageInfo
+
+func (m *VerifyRequest) GetRoll() *RollResponse {
+	if m != nil {
+		return m.Roll
+	}
+	return nil
+}
+
+type VerifyResponse struct {
+	Valid                bool       `protobuf:"varint,1,opt,name=Valid,proto3" json:"Valid,omitempty"`
+	Ok                   bool       `protobuf:"varint,2,opt,name=Ok,proto3" json:"Ok,omitempty"`
+	Error                *RollError `protobuf:"bytes,3,opt,name=Error,proto3" json:"Error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *VerifyResponse) Reset()         { *m = VerifyResponse{} }
+func (m *VerifyResponse) String() string { return proto.CompactTextString(m) }
+func (*VerifyResponse) ProtoMessage()    {}
+func (*VerifyResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63ba8fa741f5f7d8, []int{6}
+}
+
+func (m *VerifyResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VerifyResponse.Unmarshal(m, b)
+}
+func (m *VerifyResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VerifyResponse.Marshal(b, m, deterministic)
+}
+func (m *VerifyResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VerifyResponse.Merge(m, src)
+}
+func (m *VerifyResponse) XXX_Size() int {
+	return xxx_messageInfo_VerifyResponse.Size(m)
+}
+func (m *VerifyResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_VerifyResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VerifyResponse proto.InternalMessageInfo
+
+func (m *VerifyResponse) GetValid() bool {
+	if m != nil {
+		return m.Valid
+	}
+	return false
+}
+
+func (m *VerifyResponse) GetOk() bool {
+	if m != nil {
+		return m.Ok
+	}
+	return false
+}
+
+func (m *VerifyResponse) GetError() *RollError {
+	if m != nil {
+		return m.Error
+	}
+	return nil
+}
+
+// A single random number in [0, N)
+type Draw struct {
+	N                    int64    `protobuf:"varint,1,opt,name=N,proto3" json:"N,omitempty"`
+	Value                int64    `protobuf:"varint,2,opt,name=Value,proto3" json:"Value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Draw) Reset()         { *m = Draw{} }
+func (m *Draw) String() string { return proto.CompactTextString(m) }
+func (*Draw) ProtoMessage()    {}
+func (*Draw) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63ba8fa741f5f7d8, []int{7}
+}
+
+func (m *Draw) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Draw.Unmarshal(m, b)
+}
+func (m *Draw) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Draw.Marshal(b, m, deterministic)
+}
+func (m *Draw) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Draw.Merge(m, src)
+}
+func (m *Draw) XXX_Size() int {
+	return xxx_messageInfo_Draw.Size(m)
+}
+func (m *Draw) XXX_DiscardUnknown() {
+	xxx_messageInfo_Draw.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Draw proto.InternalMessageInfo
+
+func (m *Draw) GetN() int64 {
+	if m != nil {
+		return m.N
+	}
+	return 0
+}
+
+func (m *Draw) GetValue() int64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
 type Dice struct {
 	Count                int64             `protobuf:"varint,1,opt,name=Count,proto3" json:"Count,omitempty"`
 	Sides                int64             `protobuf:"varint,2,opt,name=Sides,proto3" json:"Sides,omitempty"`
@@ -170,6 +644,9 @@ type Dice struct {
 	DropLowest           int64             `protobuf:"varint,9,opt,name=DropLowest,proto3" json:"DropLowest,omitempty"`
 	Chart                []byte            `protobuf:"bytes,10,opt,name=Chart,proto3" json:"Chart,omitempty"`
 	Probabilities        map[int64]float64 `protobuf:"bytes,11,rep,name=Probabilities,proto3" json:"Probabilities,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"fixed64,2,opt,name=value,proto3"`
+	ExplodeDepth         int64             `protobuf:"varint,12,opt,name=ExplodeDepth,proto3" json:"ExplodeDepth,omitempty"`
+	SuccessTarget        int64             `protobuf:"varint,13,opt,name=SuccessTarget,proto3" json:"SuccessTarget,omitempty"`
+	BotchTarget          int64             `protobuf:"varint,14,opt,name=BotchTarget,proto3" json:"BotchTarget,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
 	XXX_unrecognized     []byte            `json:"-"`
 	XXX_sizecache        int32             `json:"-"`
@@ -179,7 +656,7 @@ func (m *Dice) Reset()         { *m = Dice{} }
 func (m *Dice) String() string { return proto.CompactTextString(m) }
 func (*Dice) ProtoMessage()    {}
 func (*Dice) Descriptor() ([]byte, []int) {
-	return fileDescriptor_63ba8fa741f5f7d8, []int{2}
+	return fileDescriptor_63ba8fa741f5f7d8, []int{8}
 }
 
 func (m *Dice) XXX_Unmarshal(b []byte) error {
@@ -277,111 +754,935 @@ func (m *Dice) GetProbabilities() map[int64]float64 {
 	return nil
 }
 
-type DiceSet struct {
-	Dice                 []*Dice            `protobuf:"bytes,1,rep,name=Dice,proto3" json:"Dice,omitempty"`
-	TotalsByColor        map[string]float64 `protobuf:"bytes,2,rep,name=TotalsByColor,proto3" json:"TotalsByColor,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"fixed64,2,opt,name=value,proto3"`
-	Total                int64              `protobuf:"varint,3,opt,name=Total,proto3" json:"Total,omitempty"`
-	ReString             string             `protobuf:"bytes,4,opt,name=ReString,proto3" json:"ReString,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
-	XXX_unrecognized     []byte             `json:"-"`
-	XXX_sizecache        int32              `json:"-"`
+func (m *Dice) GetExplodeDepth() int64 {
+	if m != nil {
+		return m.ExplodeDepth
+	}
+	return 0
 }
 
-func (m *DiceSet) Reset()         { *m = DiceSet{} }
-func (m *DiceSet) String() string { return proto.CompactTextString(m) }
+func (m *Dice) GetSuccessTarget() int64 {
+	if m != nil {
+		return m.SuccessTarget
+	}
+	return 0
+}
+
+func (m *Dice) GetBotchTarget() int64 {
+	if m != nil {
+		return m.BotchTarget
+	}
+	return 0
+}
+
+type DiceSet struct {
+	Dice          []*Dice            `protobuf:"bytes,1,rep,name=Dice,proto3" json:"Dice,omitempty"`
+	TotalsByColor map[string]float64 `protobuf:"bytes,2,rep,name=TotalsByColor,proto3" json:"TotalsByColor,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"fixed64,2,opt,name=value,proto3"`
+	Total         int64              `protobuf:"varint,3,opt,name=Total,proto3" json:"Total,omitempty"`
+	// Deprecated: format verbs in ReString are filled with the faces of Dice, in order. Render Result instead.
+	ReString string `protobuf:"bytes,4,opt,name=ReString,proto3" json:"ReString,omitempty"`
+	// Populated when statistics are requested and HasStatistics is set
+	Mean         float64 `protobuf:"fixed64,5,opt,name=Mean,proto3" json:"Mean,omitempty"`
+	StdDev       float64 `protobuf:"fixed64,6,opt,name=StdDev,proto3" json:"StdDev,omitempty"`
+	Percentile5  float64 `protobuf:"fixed64,7,opt,name=Percentile5,proto3" json:"Percentile5,omitempty"`
+	Percentile50 float64 `protobuf:"fixed64,8,opt,name=Percentile50,proto3" json:"Percentile50,omitempty"`
+	Percentile95 float64 `protobuf:"fixed64,9,opt,name=Percentile95,proto3" json:"Percentile95,omitempty"`
+	// Percent of all possible results that are greater than or equal to Total
+	TopPercent float64 `protobuf:"fixed64,10,opt,name=TopPercent,proto3" json:"TopPercent,omitempty"`
+	// Set when Mean, StdDev, the percentiles and TopPercent are populated. They are zero when statistics weren't
+	// requested or are too complex to calculate.
+	HasStatistics bool `protobuf:"varint,11,opt,name=HasStatistics,proto3" json:"HasStatistics,omitempty"`
+	// Populated when an explanation is requested
+	Explanation *TraceNode `protobuf:"bytes,12,opt,name=Explanation,proto3" json:"Explanation,omitempty"`
+	// Structured rendering of the command, e.g. "Roll 2d6(3, 5) + 3"
+	Result *ResultNode `protobuf:"bytes,13,opt,name=Result,proto3" json:"Result,omitempty"`
+	// Smallest, largest and mean Total of the command, found without rolling. Populated when bounds are requested,
+	// unless too complex to calculate.
+	Bounds *Bounds `protobuf:"bytes,14,opt,name=Bounds,proto3" json:"Bounds,omitempty"`
+	// Bounds of each total in TotalsByColor
+	BoundsByColor map[string]*Bounds `protobuf:"bytes,15,rep,name=BoundsByColor,proto3" json:"BoundsByColor,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// Every value of a command that is a list, like "[4d6dl1; 6]", in order. Total is their sum.
+	Values               []float64 `protobuf:"fixed64,16,rep,packed,name=Values,proto3" json:"Values,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *DiceSet) Reset()         { *m = DiceSet{} }
+func (m *DiceSet) String() string { return proto.CompactTextString(m) }
 func (*DiceSet) ProtoMessage()    {}
 func (*DiceSet) Descriptor() ([]byte, []int) {
-	return fileDescriptor_63ba8fa741f5f7d8, []int{3}
+	return fileDescriptor_63ba8fa741f5f7d8, []int{9}
+}
+
+func (m *DiceSet) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DiceSet.Unmarshal(m, b)
+}
+func (m *DiceSet) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DiceSet.Marshal(b, m, deterministic)
+}
+func (m *DiceSet) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DiceSet.Merge(m, src)
+}
+func (m *DiceSet) XXX_Size() int {
+	return xxx_messageInfo_DiceSet.Size(m)
+}
+func (m *DiceSet) XXX_DiscardUnknown() {
+	xxx_messageInfo_DiceSet.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DiceSet proto.InternalMessageInfo
+
+func (m *DiceSet) GetDice() []*Dice {
+	if m != nil {
+		return m.Dice
+	}
+	return nil
+}
+
+func (m *DiceSet) GetTotalsByColor() map[string]float64 {
+	if m != nil {
+		return m.TotalsByColor
+	}
+	return nil
+}
+
+func (m *DiceSet) GetTotal() int64 {
+	if m != nil {
+		return m.Total
+	}
+	return 0
+}
+
+func (m *DiceSet) GetReString() string {
+	if m != nil {
+		return m.ReString
+	}
+	return ""
+}
+
+func (m *DiceSet) GetMean() float64 {
+	if m != nil {
+		return m.Mean
+	}
+	return 0
+}
+
+func (m *DiceSet) GetStdDev() float64 {
+	if m != nil {
+		return m.StdDev
+	}
+	return 0
+}
+
+func (m *DiceSet) GetPercentile5() float64 {
+	if m != nil {
+		return m.Percentile5
+	}
+	return 0
+}
+
+func (m *DiceSet) GetPercentile50() float64 {
+	if m != nil {
+		return m.Percentile50
+	}
+	return 0
+}
+
+func (m *DiceSet) GetPercentile95() float64 {
+	if m != nil {
+		return m.Percentile95
+	}
+	return 0
+}
+
+func (m *DiceSet) GetTopPercent() float64 {
+	if m != nil {
+		return m.TopPercent
+	}
+	return 0
+}
+
+func (m *DiceSet) GetHasStatistics() bool {
+	if m != nil {
+		return m.HasStatistics
+	}
+	return false
+}
+
+func (m *DiceSet) GetExplanation() *TraceNode {
+	if m != nil {
+		return m.Explanation
+	}
+	return nil
+}
+
+func (m *DiceSet) GetResult() *ResultNode {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *DiceSet) GetBounds() *Bounds {
+	if m != nil {
+		return m.Bounds
+	}
+	return nil
+}
+
+func (m *DiceSet) GetBoundsByColor() map[string]*Bounds {
+	if m != nil {
+		return m.BoundsByColor
+	}
+	return nil
+}
+
+func (m *DiceSet) GetValues() []float64 {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+type Bounds struct {
+	Min                  float64  `protobuf:"fixed64,1,opt,name=Min,proto3" json:"Min,omitempty"`
+	Max                  float64  `protobuf:"fixed64,2,opt,name=Max,proto3" json:"Max,omitempty"`
+	Mean                 float64  `protobuf:"fixed64,3,opt,name=Mean,proto3" json:"Mean,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Bounds) Reset()         { *m = Bounds{} }
+func (m *Bounds) String() string { return proto.CompactTextString(m) }
+func (*Bounds) ProtoMessage()    {}
+func (*Bounds) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63ba8fa741f5f7d8, []int{10}
+}
+
+func (m *Bounds) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Bounds.Unmarshal(m, b)
+}
+func (m *Bounds) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Bounds.Marshal(b, m, deterministic)
+}
+func (m *Bounds) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Bounds.Merge(m, src)
+}
+func (m *Bounds) XXX_Size() int {
+	return xxx_messageInfo_Bounds.Size(m)
+}
+func (m *Bounds) XXX_DiscardUnknown() {
+	xxx_messageInfo_Bounds.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Bounds proto.InternalMessageInfo
+
+func (m *Bounds) GetMin() float64 {
+	if m != nil {
+		return m.Min
+	}
+	return 0
+}
+
+func (m *Bounds) GetMax() float64 {
+	if m != nil {
+		return m.Max
+	}
+	return 0
+}
+
+func (m *Bounds) GetMean() float64 {
+	if m != nil {
+		return m.Mean
+	}
+	return 0
+}
+
+// ResultNode is a structured rendering of a roll that clients can render without format strings.
+type ResultNode struct {
+	Kind                 ResultNode_Kind `protobuf:"varint,1,opt,name=kind,proto3,enum=proto.ResultNode_Kind" json:"kind,omitempty"`
+	Text                 string          `protobuf:"bytes,2,opt,name=Text,proto3" json:"Text,omitempty"`
+	DiceIndex            int32           `protobuf:"varint,3,opt,name=DiceIndex,proto3" json:"DiceIndex,omitempty"`
+	Children             []*ResultNode   `protobuf:"bytes,4,rep,name=Children,proto3" json:"Children,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *ResultNode) Reset()         { *m = ResultNode{} }
+func (m *ResultNode) String() string { return proto.CompactTextString(m) }
+func (*ResultNode) ProtoMessage()    {}
+func (*ResultNode) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63ba8fa741f5f7d8, []int{11}
+}
+
+func (m *ResultNode) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ResultNode.Unmarshal(m, b)
+}
+func (m *ResultNode) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ResultNode.Marshal(b, m, deterministic)
+}
+func (m *ResultNode) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResultNode.Merge(m, src)
+}
+func (m *ResultNode) XXX_Size() int {
+	return xxx_messageInfo_ResultNode.Size(m)
+}
+func (m *ResultNode) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResultNode.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResultNode proto.InternalMessageInfo
+
+func (m *ResultNode) GetKind() ResultNode_Kind {
+	if m != nil {
+		return m.Kind
+	}
+	return ResultNode_TEXT
+}
+
+func (m *ResultNode) GetText() string {
+	if m != nil {
+		return m.Text
+	}
+	return ""
+}
+
+func (m *ResultNode) GetDiceIndex() int32 {
+	if m != nil {
+		return m.DiceIndex
+	}
+	return 0
+}
+
+func (m *ResultNode) GetChildren() []*ResultNode {
+	if m != nil {
+		return m.Children
+	}
+	return nil
+}
+
+// Mirrors a node of the AST, with the value it evaluated to and the dice it rolled
+type TraceNode struct {
+	Sym      string       `protobuf:"bytes,1,opt,name=Sym,proto3" json:"Sym,omitempty"`
+	Value    string       `protobuf:"bytes,2,opt,name=Value,proto3" json:"Value,omitempty"`
+	Result   float64      `protobuf:"fixed64,3,opt,name=Result,proto3" json:"Result,omitempty"`
+	Dice     []*Dice      `protobuf:"bytes,4,rep,name=Dice,proto3" json:"Dice,omitempty"`
+	Children []*TraceNode `protobuf:"bytes,5,rep,name=Children,proto3" json:"Children,omitempty"`
+	// Human readable explanation of this node and its children, e.g. "(2d6=[3, 5]=8) + 3 = 11"
+	Text                 string   `protobuf:"bytes,6,opt,name=Text,proto3" json:"Text,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TraceNode) Reset()         { *m = TraceNode{} }
+func (m *TraceNode) String() string { return proto.CompactTextString(m) }
+func (*TraceNode) ProtoMessage()    {}
+func (*TraceNode) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63ba8fa741f5f7d8, []int{12}
+}
+
+func (m *TraceNode) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TraceNode.Unmarshal(m, b)
+}
+func (m *TraceNode) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TraceNode.Marshal(b, m, deterministic)
+}
+func (m *TraceNode) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TraceNode.Merge(m, src)
+}
+func (m *TraceNode) XXX_Size() int {
+	return xxx_messageInfo_TraceNode.Size(m)
+}
+func (m *TraceNode) XXX_DiscardUnknown() {
+	xxx_messageInfo_TraceNode.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TraceNode proto.InternalMessageInfo
+
+func (m *TraceNode) GetSym() string {
+	if m != nil {
+		return m.Sym
+	}
+	return ""
+}
+
+func (m *TraceNode) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+func (m *TraceNode) GetResult() float64 {
+	if m != nil {
+		return m.Result
+	}
+	return 0
+}
+
+func (m *TraceNode) GetDice() []*Dice {
+	if m != nil {
+		return m.Dice
+	}
+	return nil
+}
+
+func (m *TraceNode) GetChildren() []*TraceNode {
+	if m != nil {
+		return m.Children
+	}
+	return nil
+}
+
+func (m *TraceNode) GetText() string {
+	if m != nil {
+		return m.Text
+	}
+	return ""
+}
+
+type DiceSets struct {
+	DiceSet              []*DiceSet `protobuf:"bytes,1,rep,name=DiceSet,proto3" json:"DiceSet,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *DiceSets) Reset()         { *m = DiceSets{} }
+func (m *DiceSets) String() string { return proto.CompactTextString(m) }
+func (*DiceSets) ProtoMessage()    {}
+func (*DiceSets) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63ba8fa741f5f7d8, []int{13}
+}
+
+func (m *DiceSets) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DiceSets.Unmarshal(m, b)
+}
+func (m *DiceSets) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DiceSets.Marshal(b, m, deterministic)
+}
+func (m *DiceSets) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DiceSets.Merge(m, src)
+}
+func (m *DiceSets) XXX_Size() int {
+	return xxx_messageInfo_DiceSets.Size(m)
+}
+func (m *DiceSets) XXX_DiscardUnknown() {
+	xxx_messageInfo_DiceSets.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DiceSets proto.InternalMessageInfo
+
+func (m *DiceSets) GetDiceSet() []*DiceSet {
+	if m != nil {
+		return m.DiceSet
+	}
+	return nil
+}
+
+// The request message containing a command like "compare 2d6 vs 1d12". The leading "compare" is optional.
+type CompareRequest struct {
+	Cmd                  string   `protobuf:"bytes,1,opt,name=cmd,proto3" json:"cmd,omitempty"`
+	Chart                bool     `protobuf:"varint,2,opt,name=chart,proto3" json:"chart,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CompareRequest) Reset()         { *m = CompareRequest{} }
+func (m *CompareRequest) String() string { return proto.CompactTextString(m) }
+func (*CompareRequest) ProtoMessage()    {}
+func (*CompareRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63ba8fa741f5f7d8, []int{14}
+}
+
+func (m *CompareRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CompareRequest.Unmarshal(m, b)
+}
+func (m *CompareRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CompareRequest.Marshal(b, m, deterministic)
+}
+func (m *CompareRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CompareRequest.Merge(m, src)
+}
+func (m *CompareRequest) XXX_Size() int {
+	return xxx_messageInfo_CompareRequest.Size(m)
+}
+func (m *CompareRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CompareRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CompareRequest proto.InternalMessageInfo
+
+func (m *CompareRequest) GetCmd() string {
+	if m != nil {
+		return m.Cmd
+	}
+	return ""
+}
+
+func (m *CompareRequest) GetChart() bool {
+	if m != nil {
+		return m.Chart
+	}
+	return false
+}
+
+type CompareResponse struct {
+	Cmd string      `protobuf:"bytes,1,opt,name=Cmd,proto3" json:"Cmd,omitempty"`
+	A   *Expression `protobuf:"bytes,2,opt,name=A,proto3" json:"A,omitempty"`
+	B   *Expression `protobuf:"bytes,3,opt,name=B,proto3" json:"B,omitempty"`
+	// Chance, in percent, that a roll of A is greater than, equal to, or less than a roll of B
+	Greater float64 `protobuf:"fixed64,4,opt,name=Greater,proto3" json:"Greater,omitempty"`
+	Equal   float64 `protobuf:"fixed64,5,opt,name=Equal,proto3" json:"Equal,omitempty"`
+	Less    float64 `protobuf:"fixed64,6,opt,name=Less,proto3" json:"Less,omitempty"`
+	// One row per possible result of either expression, in ascending order
+	Table []*CompareRow `protobuf:"bytes,7,rep,name=Table,proto3" json:"Table,omitempty"`
+	// PNG line chart of both distributions, populated when requested
+	Chart                []byte     `protobuf:"bytes,8,opt,name=Chart,proto3" json:"Chart,omitempty"`
+	Ok                   bool       `protobuf:"varint,9,opt,name=Ok,proto3" json:"Ok,omitempty"`
+	Error                *RollError `protobuf:"bytes,10,opt,name=Error,proto3" json:"Error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *CompareResponse) Reset()         { *m = CompareResponse{} }
+func (m *CompareResponse) String() string { return proto.CompactTextString(m) }
+func (*CompareResponse) ProtoMessage()    {}
+func (*CompareResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63ba8fa741f5f7d8, []int{15}
+}
+
+func (m *CompareResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CompareResponse.Unmarshal(m, b)
+}
+func (m *CompareResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CompareResponse.Marshal(b, m, deterministic)
+}
+func (m *CompareResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CompareResponse.Merge(m, src)
+}
+func (m *CompareResponse) XXX_Size() int {
+	return xxx_messageInfo_CompareResponse.Size(m)
+}
+func (m *CompareResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CompareResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CompareResponse proto.InternalMessageInfo
+
+func (m *CompareResponse) GetCmd() string {
+	if m != nil {
+		return m.Cmd
+	}
+	return ""
+}
+
+func (m *CompareResponse) GetA() *Expression {
+	if m != nil {
+		return m.A
+	}
+	return nil
+}
+
+func (m *CompareResponse) GetB() *Expression {
+	if m != nil {
+		return m.B
+	}
+	return nil
+}
+
+func (m *CompareResponse) GetGreater() float64 {
+	if m != nil {
+		return m.Greater
+	}
+	return 0
+}
+
+func (m *CompareResponse) GetEqual() float64 {
+	if m != nil {
+		return m.Equal
+	}
+	return 0
+}
+
+func (m *CompareResponse) GetLess() float64 {
+	if m != nil {
+		return m.Less
+	}
+	return 0
+}
+
+func (m *CompareResponse) GetTable() []*CompareRow {
+	if m != nil {
+		return m.Table
+	}
+	return nil
+}
+
+func (m *CompareResponse) GetChart() []byte {
+	if m != nil {
+		return m.Chart
+	}
+	return nil
+}
+
+func (m *CompareResponse) GetOk() bool {
+	if m != nil {
+		return m.Ok
+	}
+	return false
+}
+
+func (m *CompareResponse) GetError() *RollError {
+	if m != nil {
+		return m.Error
+	}
+	return nil
+}
+
+type Expression struct {
+	Cmd                  string   `protobuf:"bytes,1,opt,name=Cmd,proto3" json:"Cmd,omitempty"`
+	Mean                 float64  `protobuf:"fixed64,2,opt,name=Mean,proto3" json:"Mean,omitempty"`
+	StdDev               float64  `protobuf:"fixed64,3,opt,name=StdDev,proto3" json:"StdDev,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Expression) Reset()         { *m = Expression{} }
+func (m *Expression) String() string { return proto.CompactTextString(m) }
+func (*Expression) ProtoMessage()    {}
+func (*Expression) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63ba8fa741f5f7d8, []int{16}
+}
+
+func (m *Expression) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Expression.Unmarshal(m, b)
+}
+func (m *Expression) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Expression.Marshal(b, m, deterministic)
+}
+func (m *Expression) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Expression.Merge(m, src)
+}
+func (m *Expression) XXX_Size() int {
+	return xxx_messageInfo_Expression.Size(m)
+}
+func (m *Expression) XXX_DiscardUnknown() {
+	xxx_messageInfo_Expression.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Expression proto.InternalMessageInfo
+
+func (m *Expression) GetCmd() string {
+	if m != nil {
+		return m.Cmd
+	}
+	return ""
+}
+
+func (m *Expression) GetMean() float64 {
+	if m != nil {
+		return m.Mean
+	}
+	return 0
+}
+
+func (m *Expression) GetStdDev() float64 {
+	if m != nil {
+		return m.StdDev
+	}
+	return 0
+}
+
+type CompareRow struct {
+	Result float64 `protobuf:"fixed64,1,opt,name=Result,proto3" json:"Result,omitempty"`
+	// Chance, in percent, of rolling Result
+	A                    float64  `protobuf:"fixed64,2,opt,name=A,proto3" json:"A,omitempty"`
+	B                    float64  `protobuf:"fixed64,3,opt,name=B,proto3" json:"B,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CompareRow) Reset()         { *m = CompareRow{} }
+func (m *CompareRow) String() string { return proto.CompactTextString(m) }
+func (*CompareRow) ProtoMessage()    {}
+func (*CompareRow) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63ba8fa741f5f7d8, []int{17}
+}
+
+func (m *CompareRow) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CompareRow.Unmarshal(m, b)
+}
+func (m *CompareRow) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CompareRow.Marshal(b, m, deterministic)
+}
+func (m *CompareRow) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CompareRow.Merge(m, src)
+}
+func (m *CompareRow) XXX_Size() int {
+	return xxx_messageInfo_CompareRow.Size(m)
+}
+func (m *CompareRow) XXX_DiscardUnknown() {
+	xxx_messageInfo_CompareRow.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CompareRow proto.InternalMessageInfo
+
+func (m *CompareRow) GetResult() float64 {
+	if m != nil {
+		return m.Result
+	}
+	return 0
 }
 
-func (m *DiceSet) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_DiceSet.Unmarshal(m, b)
+func (m *CompareRow) GetA() float64 {
+	if m != nil {
+		return m.A
+	}
+	return 0
 }
-func (m *DiceSet) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_DiceSet.Marshal(b, m, deterministic)
+
+func (m *CompareRow) GetB() float64 {
+	if m != nil {
+		return m.B
+	}
+	return 0
 }
-func (m *DiceSet) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_DiceSet.Merge(m, src)
+
+// Every attack rolls 1d20 + attackBonus against AC. Rolls of critRange or higher crit, doubling the dice of damage.
+type DamageRequest struct {
+	AttackBonus int64 `protobuf:"varint,1,opt,name=attackBonus,proto3" json:"attackBonus,omitempty"`
+	AC          int64 `protobuf:"varint,2,opt,name=AC,proto3" json:"AC,omitempty"`
+	// Defaults to 20
+	CritRange int64  `protobuf:"varint,3,opt,name=critRange,proto3" json:"critRange,omitempty"`
+	Damage    string `protobuf:"bytes,4,opt,name=damage,proto3" json:"damage,omitempty"`
+	// Defaults to 1
+	Attacks              int64    `protobuf:"varint,5,opt,name=attacks,proto3" json:"attacks,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
-func (m *DiceSet) XXX_Size() int {
-	return xxx_messageInfo_DiceSet.Size(m)
+
+func (m *DamageRequest) Reset()         { *m = DamageRequest{} }
+func (m *DamageRequest) String() string { return proto.CompactTextString(m) }
+func (*DamageRequest) ProtoMessage()    {}
+func (*DamageRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63ba8fa741f5f7d8, []int{18}
 }
-func (m *DiceSet) XXX_DiscardUnknown() {
-	xxx_messageInfo_DiceSet.DiscardUnknown(m)
+
+func (m *DamageRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DamageRequest.Unmarshal(m, b)
+}
+func (m *DamageRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DamageRequest.Marshal(b, m, deterministic)
+}
+func (m *DamageRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DamageRequest.Merge(m, src)
+}
+func (m *DamageRequest) XXX_Size() int {
+	return xxx_messageInfo_DamageRequest.Size(m)
+}
+func (m *DamageRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DamageRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_DiceSet proto.InternalMessageInfo
+var xxx_messageInfo_DamageRequest proto.InternalMessageInfo
 
-func (m *DiceSet) GetDice() []*Dice {
+func (m *DamageRequest) GetAttackBonus() int64 {
 	if m != nil {
-		return m.Dice
+		return m.AttackBonus
 	}
-	return nil
+	return 0
 }
 
-func (m *DiceSet) GetTotalsByColor() map[string]float64 {
+func (m *DamageRequest) GetAC() int64 {
 	if m != nil {
-		return m.TotalsByColor
+		return m.AC
 	}
-	return nil
+	return 0
 }
 
-func (m *DiceSet) GetTotal() int64 {
+func (m *DamageRequest) GetCritRange() int64 {
 	if m != nil {
-		return m.Total
+		return m.CritRange
 	}
 	return 0
 }
 
-func (m *DiceSet) GetReString() string {
+func (m *DamageRequest) GetDamage() string {
 	if m != nil {
-		return m.ReString
+		return m.Damage
 	}
 	return ""
 }
 
-type DiceSets struct {
-	DiceSet              []*DiceSet `protobuf:"bytes,1,rep,name=DiceSet,proto3" json:"DiceSet,omitempty"`
+func (m *DamageRequest) GetAttacks() int64 {
+	if m != nil {
+		return m.Attacks
+	}
+	return 0
+}
+
+type DamageResponse struct {
+	// Expected damage per round
+	Mean   float64 `protobuf:"fixed64,1,opt,name=Mean,proto3" json:"Mean,omitempty"`
+	StdDev float64 `protobuf:"fixed64,2,opt,name=StdDev,proto3" json:"StdDev,omitempty"`
+	// Chance, in percent, that a single attack hits without a crit, crits, or misses
+	HitChance  float64 `protobuf:"fixed64,3,opt,name=HitChance,proto3" json:"HitChance,omitempty"`
+	CritChance float64 `protobuf:"fixed64,4,opt,name=CritChance,proto3" json:"CritChance,omitempty"`
+	MissChance float64 `protobuf:"fixed64,5,opt,name=MissChance,proto3" json:"MissChance,omitempty"`
+	// Chance, in percent, of every possible total damage per round, in ascending order
+	Distribution         []*Outcome `protobuf:"bytes,6,rep,name=Distribution,proto3" json:"Distribution,omitempty"`
+	Ok                   bool       `protobuf:"varint,7,opt,name=Ok,proto3" json:"Ok,omitempty"`
+	Error                *RollError `protobuf:"bytes,8,opt,name=Error,proto3" json:"Error,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
 	XXX_unrecognized     []byte     `json:"-"`
 	XXX_sizecache        int32      `json:"-"`
 }
 
-func (m *DiceSets) Reset()         { *m = DiceSets{} }
-func (m *DiceSets) String() string { return proto.CompactTextString(m) }
-func (*DiceSets) ProtoMessage()    {}
-func (*DiceSets) Descriptor() ([]byte, []int) {
-	return fileDescriptor_63ba8fa741f5f7d8, []int{4}
+func (m *DamageResponse) Reset()         { *m = DamageResponse{} }
+func (m *DamageResponse) String() string { return proto.CompactTextString(m) }
+func (*DamageResponse) ProtoMessage()    {}
+func (*DamageResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63ba8fa741f5f7d8, []int{19}
 }
 
-func (m *DiceSets) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_DiceSets.Unmarshal(m, b)
+func (m *DamageResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DamageResponse.Unmarshal(m, b)
 }
-func (m *DiceSets) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_DiceSets.Marshal(b, m, deterministic)
+func (m *DamageResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DamageResponse.Marshal(b, m, deterministic)
 }
-func (m *DiceSets) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_DiceSets.Merge(m, src)
+func (m *DamageResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DamageResponse.Merge(m, src)
 }
-func (m *DiceSets) XXX_Size() int {
-	return xxx_messageInfo_DiceSets.Size(m)
+func (m *DamageResponse) XXX_Size() int {
+	return xxx_messageInfo_DamageResponse.Size(m)
 }
-func (m *DiceSets) XXX_DiscardUnknown() {
-	xxx_messageInfo_DiceSets.DiscardUnknown(m)
+func (m *DamageResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DamageResponse.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_DiceSets proto.InternalMessageInfo
+var xxx_messageInfo_DamageResponse proto.InternalMessageInfo
 
-func (m *DiceSets) GetDiceSet() []*DiceSet {
+func (m *DamageResponse) GetMean() float64 {
 	if m != nil {
-		return m.DiceSet
+		return m.Mean
+	}
+	return 0
+}
+
+func (m *DamageResponse) GetStdDev() float64 {
+	if m != nil {
+		return m.StdDev
+	}
+	return 0
+}
+
+func (m *DamageResponse) GetHitChance() float64 {
+	if m != nil {
+		return m.HitChance
+	}
+	return 0
+}
+
+func (m *DamageResponse) GetCritChance() float64 {
+	if m != nil {
+		return m.CritChance
+	}
+	return 0
+}
+
+func (m *DamageResponse) GetMissChance() float64 {
+	if m != nil {
+		return m.MissChance
+	}
+	return 0
+}
+
+func (m *DamageResponse) GetDistribution() []*Outcome {
+	if m != nil {
+		return m.Distribution
+	}
+	return nil
+}
+
+func (m *DamageResponse) GetOk() bool {
+	if m != nil {
+		return m.Ok
+	}
+	return false
+}
+
+func (m *DamageResponse) GetError() *RollError {
+	if m != nil {
+		return m.Error
 	}
 	return nil
 }
 
+type Outcome struct {
+	Result               float64  `protobuf:"fixed64,1,opt,name=Result,proto3" json:"Result,omitempty"`
+	Probability          float64  `protobuf:"fixed64,2,opt,name=Probability,proto3" json:"Probability,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Outcome) Reset()         { *m = Outcome{} }
+func (m *Outcome) String() string { return proto.CompactTextString(m) }
+func (*Outcome) ProtoMessage()    {}
+func (*Outcome) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63ba8fa741f5f7d8, []int{20}
+}
+
+func (m *Outcome) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Outcome.Unmarshal(m, b)
+}
+func (m *Outcome) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Outcome.Marshal(b, m, deterministic)
+}
+func (m *Outcome) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Outcome.Merge(m, src)
+}
+func (m *Outcome) XXX_Size() int {
+	return xxx_messageInfo_Outcome.Size(m)
+}
+func (m *Outcome) XXX_DiscardUnknown() {
+	xxx_messageInfo_Outcome.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Outcome proto.InternalMessageInfo
+
+func (m *Outcome) GetResult() float64 {
+	if m != nil {
+		return m.Result
+	}
+	return 0
+}
+
+func (m *Outcome) GetProbability() float64 {
+	if m != nil {
+		return m.Probability
+	}
+	return 0
+}
+
 type RollError struct {
-	Msg                  string   `protobuf:"bytes,1,opt,name=msg,proto3" json:"msg,omitempty"`
-	Code                 int32    `protobuf:"varint,2,opt,name=code,proto3" json:"code,omitempty"`
+	Msg  string `protobuf:"bytes,1,opt,name=msg,proto3" json:"msg,omitempty"`
+	Code int32  `protobuf:"varint,2,opt,name=code,proto3" json:"code,omitempty"`
+	// Every problem found parsing the command, when it did not parse
+	Diagnostics []*Diagnostic `protobuf:"bytes,3,rep,name=diagnostics,proto3" json:"diagnostics,omitempty"`
+	// The command with each diagnostic marked under it, for display in a fixed width font
+	Caret                string   `protobuf:"bytes,4,opt,name=caret,proto3" json:"caret,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -391,7 +1692,7 @@ func (m *RollError) Reset()         { *m = RollError{} }
 func (m *RollError) String() string { return proto.CompactTextString(m) }
 func (*RollError) ProtoMessage()    {}
 func (*RollError) Descriptor() ([]byte, []int) {
-	return fileDescriptor_63ba8fa741f5f7d8, []int{5}
+	return fileDescriptor_63ba8fa741f5f7d8, []int{21}
 }
 
 func (m *RollError) XXX_Unmarshal(b []byte) error {
@@ -426,54 +1727,283 @@ func (m *RollError) GetCode() int32 {
 	return 0
 }
 
+func (m *RollError) GetDiagnostics() []*Diagnostic {
+	if m != nil {
+		return m.Diagnostics
+	}
+	return nil
+}
+
+func (m *RollError) GetCaret() string {
+	if m != nil {
+		return m.Caret
+	}
+	return ""
+}
+
+// A problem found in a command, spanning start up to, but not including, end
+type Diagnostic struct {
+	Severity             Diagnostic_Severity `protobuf:"varint,1,opt,name=severity,proto3,enum=proto.Diagnostic_Severity" json:"severity,omitempty"`
+	Message              string              `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Start                *Position           `protobuf:"bytes,3,opt,name=start,proto3" json:"start,omitempty"`
+	End                  *Position           `protobuf:"bytes,4,opt,name=end,proto3" json:"end,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
+	XXX_unrecognized     []byte              `json:"-"`
+	XXX_sizecache        int32               `json:"-"`
+}
+
+func (m *Diagnostic) Reset()         { *m = Diagnostic{} }
+func (m *Diagnostic) String() string { return proto.CompactTextString(m) }
+func (*Diagnostic) ProtoMessage()    {}
+func (*Diagnostic) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63ba8fa741f5f7d8, []int{22}
+}
+
+func (m *Diagnostic) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Diagnostic.Unmarshal(m, b)
+}
+func (m *Diagnostic) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Diagnostic.Marshal(b, m, deterministic)
+}
+func (m *Diagnostic) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Diagnostic.Merge(m, src)
+}
+func (m *Diagnostic) XXX_Size() int {
+	return xxx_messageInfo_Diagnostic.Size(m)
+}
+func (m *Diagnostic) XXX_DiscardUnknown() {
+	xxx_messageInfo_Diagnostic.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Diagnostic proto.InternalMessageInfo
+
+func (m *Diagnostic) GetSeverity() Diagnostic_Severity {
+	if m != nil {
+		return m.Severity
+	}
+	return Diagnostic_ERROR
+}
+
+func (m *Diagnostic) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *Diagnostic) GetStart() *Position {
+	if m != nil {
+		return m.Start
+	}
+	return nil
+}
+
+func (m *Diagnostic) GetEnd() *Position {
+	if m != nil {
+		return m.End
+	}
+	return nil
+}
+
+// A line and column in a command, both counting from 1
+type Position struct {
+	Line                 int32    `protobuf:"varint,1,opt,name=line,proto3" json:"line,omitempty"`
+	Col                  int32    `protobuf:"varint,2,opt,name=col,proto3" json:"col,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Position) Reset()         { *m = Position{} }
+func (m *Position) String() string { return proto.CompactTextString(m) }
+func (*Position) ProtoMessage()    {}
+func (*Position) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63ba8fa741f5f7d8, []int{23}
+}
+
+func (m *Position) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Position.Unmarshal(m, b)
+}
+func (m *Position) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Position.Marshal(b, m, deterministic)
+}
+func (m *Position) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Position.Merge(m, src)
+}
+func (m *Position) XXX_Size() int {
+	return xxx_messageInfo_Position.Size(m)
+}
+func (m *Position) XXX_DiscardUnknown() {
+	xxx_messageInfo_Position.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Position proto.InternalMessageInfo
+
+func (m *Position) GetLine() int32 {
+	if m != nil {
+		return m.Line
+	}
+	return 0
+}
+
+func (m *Position) GetCol() int32 {
+	if m != nil {
+		return m.Col
+	}
+	return 0
+}
+
 func init() {
+	proto.RegisterEnum("proto.ResultNode_Kind", ResultNode_Kind_name, ResultNode_Kind_value)
+	proto.RegisterEnum("proto.Diagnostic_Severity", Diagnostic_Severity_name, Diagnostic_Severity_value)
 	proto.RegisterType((*RollRequest)(nil), "proto.RollRequest")
+	proto.RegisterType((*EvaluationLimits)(nil), "proto.EvaluationLimits")
+	proto.RegisterType((*FairSeed)(nil), "proto.FairSeed")
 	proto.RegisterType((*RollResponse)(nil), "proto.RollResponse")
+	proto.RegisterType((*Receipt)(nil), "proto.Receipt")
+	proto.RegisterType((*VerifyRequest)(nil), "proto.VerifyRequest")
+	proto.RegisterType((*VerifyResponse)(nil), "proto.VerifyResponse")
+	proto.RegisterType((*Draw)(nil), "proto.Draw")
 	proto.RegisterType((*Dice)(nil), "proto.Dice")
 	proto.RegisterMapType((map[int64]float64)(nil), "proto.Dice.ProbabilitiesEntry")
 	proto.RegisterType((*DiceSet)(nil), "proto.DiceSet")
+	proto.RegisterMapType((map[string]*Bounds)(nil), "proto.DiceSet.BoundsByColorEntry")
 	proto.RegisterMapType((map[string]float64)(nil), "proto.DiceSet.TotalsByColorEntry")
+	proto.RegisterType((*Bounds)(nil), "proto.Bounds")
+	proto.RegisterType((*ResultNode)(nil), "proto.ResultNode")
+	proto.RegisterType((*TraceNode)(nil), "proto.TraceNode")
 	proto.RegisterType((*DiceSets)(nil), "proto.DiceSets")
+	proto.RegisterType((*CompareRequest)(nil), "proto.CompareRequest")
+	proto.RegisterType((*CompareResponse)(nil), "proto.CompareResponse")
+	proto.RegisterType((*Expression)(nil), "proto.Expression")
+	proto.RegisterType((*CompareRow)(nil), "proto.CompareRow")
+	proto.RegisterType((*DamageRequest)(nil), "proto.DamageRequest")
+	proto.RegisterType((*DamageResponse)(nil), "proto.DamageResponse")
+	proto.RegisterType((*Outcome)(nil), "proto.Outcome")
 	proto.RegisterType((*RollError)(nil), "proto.RollError")
+	proto.RegisterType((*Diagnostic)(nil), "proto.Diagnostic")
+	proto.RegisterType((*Position)(nil), "proto.Position")
 }
 
 func init() { proto.RegisterFile("dicemagic.proto", fileDescriptor_63ba8fa741f5f7d8) }
 
 var fileDescriptor_63ba8fa741f5f7d8 = []byte{
-	// 527 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x54, 0xc1, 0x6e, 0xd3, 0x40,
-	0x10, 0xc5, 0x76, 0xd2, 0xc6, 0x93, 0xa6, 0x54, 0x0b, 0x87, 0x55, 0x0e, 0xc5, 0x58, 0x08, 0x45,
-	0x1c, 0x22, 0xb5, 0x70, 0x40, 0x70, 0x41, 0x24, 0x05, 0x0e, 0xa0, 0xa0, 0x0d, 0x3f, 0xe0, 0x38,
-	0xab, 0x74, 0x15, 0xc7, 0x1b, 0xd6, 0x1b, 0x4a, 0x7e, 0x8a, 0xdf, 0xe2, 0x17, 0x38, 0xa2, 0x99,
-	0xdd, 0xb8, 0xb6, 0x5a, 0xc4, 0x29, 0xf3, 0xde, 0xcc, 0xee, 0xbc, 0x7d, 0x33, 0x31, 0x3c, 0x5c,
-	0xaa, 0x5c, 0x6e, 0xb2, 0x95, 0xca, 0xc7, 0x5b, 0xa3, 0xad, 0x66, 0x5d, 0xfa, 0x49, 0x6f, 0xa0,
-	0x2f, 0x74, 0x51, 0x08, 0xf9, 0x7d, 0x27, 0x2b, 0xcb, 0xce, 0x20, 0xca, 0x37, 0x4b, 0x1e, 0x24,
-	0xc1, 0x28, 0x16, 0x18, 0xb2, 0x67, 0x30, 0xd8, 0x1a, 0xbd, 0xc8, 0x16, 0xaa, 0x50, 0x56, 0xc9,
-	0x8a, 0x87, 0x49, 0x30, 0xea, 0x89, 0x36, 0xc9, 0x1e, 0x43, 0x37, 0xbf, 0xce, 0x8c, 0xe5, 0x11,
-	0x65, 0x1d, 0x60, 0x43, 0xe8, 0x19, 0xad, 0xed, 0xac, 0x2c, 0xf6, 0xbc, 0x43, 0x89, 0x1a, 0xa7,
-	0xbf, 0x02, 0x38, 0x71, 0x9d, 0xab, 0xad, 0x2e, 0x2b, 0x89, 0xad, 0x27, 0xb7, 0xad, 0x27, 0x9b,
-	0x25, 0x1b, 0xc1, 0xf1, 0x54, 0xe5, 0x72, 0x2e, 0x2d, 0x35, 0xed, 0x5f, 0x9e, 0x3a, 0xed, 0x63,
-	0xcf, 0x8a, 0x43, 0x9a, 0xbd, 0x80, 0x9e, 0x0f, 0x2b, 0x1e, 0x25, 0xd1, 0x3d, 0xa5, 0x75, 0x9e,
-	0x9d, 0x42, 0x38, 0x5b, 0x7b, 0x39, 0xe1, 0x6c, 0xcd, 0x9e, 0x43, 0xf7, 0xca, 0x18, 0x6d, 0x78,
-	0x97, 0x7a, 0x9c, 0xf9, 0x83, 0xa8, 0x8d, 0x78, 0xe1, 0xd2, 0xe9, 0x9f, 0x10, 0x3a, 0x78, 0x09,
-	0xbe, 0x75, 0xa2, 0x77, 0xa5, 0x25, 0xa9, 0x91, 0x70, 0x00, 0xd9, 0xb9, 0x5a, 0x7a, 0x7f, 0x22,
-	0xe1, 0x00, 0xb2, 0xdf, 0xb4, 0xcd, 0x0a, 0xf2, 0x25, 0x12, 0x0e, 0x20, 0xfb, 0x21, 0xcb, 0x65,
-	0xc5, 0x3b, 0x49, 0x84, 0x2c, 0x01, 0x77, 0x6f, 0xe1, 0x85, 0xc4, 0xc2, 0x01, 0xb4, 0xe5, 0x4b,
-	0xf6, 0x93, 0x1f, 0xd1, 0x79, 0x0c, 0x89, 0x51, 0x25, 0x3f, 0xf6, 0x8c, 0x2a, 0x59, 0x02, 0xfd,
-	0xa9, 0xd1, 0xdb, 0x4f, 0x6a, 0x75, 0x2d, 0x2b, 0xcb, 0x7b, 0x94, 0x69, 0x52, 0xec, 0x1c, 0x00,
-	0xe1, 0x67, 0x7d, 0x83, 0x05, 0x31, 0x15, 0x34, 0x18, 0xea, 0x4d, 0xf3, 0x83, 0x24, 0x18, 0x9d,
-	0x08, 0x07, 0xd8, 0x14, 0x06, 0x5f, 0x5b, 0xb3, 0xef, 0x93, 0xb7, 0xe7, 0x0d, 0x6f, 0xc7, 0xad,
-	0x82, 0xab, 0xd2, 0x9a, 0xbd, 0x68, 0x1f, 0x1a, 0xbe, 0x03, 0x76, 0xb7, 0x08, 0x5f, 0xb1, 0x96,
-	0x7b, 0xef, 0x21, 0x86, 0xa8, 0xe1, 0x47, 0x56, 0xec, 0x24, 0x39, 0x18, 0x08, 0x07, 0xde, 0x84,
-	0xaf, 0x83, 0xf4, 0x77, 0x50, 0x6f, 0x02, 0x7b, 0xe2, 0xa6, 0xc0, 0x03, 0x92, 0xd2, 0x6f, 0x48,
-	0x11, 0x6e, 0x3c, 0x1f, 0x61, 0x40, 0x2e, 0x57, 0xef, 0xf7, 0xce, 0xce, 0x90, 0x2a, 0x9f, 0xb6,
-	0x17, 0x62, 0xdc, 0xaa, 0xf1, 0xba, 0x5b, 0xdc, 0x3f, 0x66, 0x37, 0x84, 0x9e, 0x90, 0x73, 0x6b,
-	0x54, 0xb9, 0xa2, 0x25, 0x8a, 0x45, 0x8d, 0xf1, 0xa5, 0x77, 0xaf, 0x6d, 0xbe, 0x34, 0xfe, 0xdf,
-	0x4b, 0x5f, 0xdd, 0x2e, 0x72, 0x73, 0xfd, 0x83, 0x7b, 0x77, 0xfa, 0x90, 0x4e, 0x2f, 0x20, 0xae,
-	0xd7, 0x15, 0xdb, 0x6d, 0xaa, 0xd5, 0xa1, 0xdd, 0xa6, 0x5a, 0x31, 0x06, 0x9d, 0x5c, 0x2f, 0x5d,
-	0xb7, 0xae, 0xa0, 0xf8, 0xf2, 0x2d, 0x1c, 0xe1, 0x11, 0x69, 0xd8, 0x05, 0x74, 0x30, 0x62, 0xac,
-	0xb1, 0xf8, 0xfe, 0x73, 0x30, 0x7c, 0xd4, 0xe2, 0xdc, 0x1f, 0x35, 0x7d, 0xb0, 0x38, 0x22, 0xf6,
-	0xe5, 0xdf, 0x00, 0x00, 0x00, 0xff, 0xff, 0xf9, 0x18, 0x57, 0xc3, 0x55, 0x04, 0x00, 0x00,
+	// 1834 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x57, 0xcd, 0x72, 0x1b, 0xb9,
+	0xf1, 0xf7, 0x70, 0x48, 0x8a, 0x6c, 0x52, 0xb2, 0xfe, 0xf8, 0xdb, 0xce, 0x94, 0x6a, 0x6b, 0x97,
+	0x3b, 0xce, 0x66, 0x15, 0x27, 0x51, 0x36, 0xda, 0x38, 0xf1, 0xee, 0x25, 0x96, 0x28, 0xae, 0xed,
+	0xc4, 0x96, 0x54, 0x20, 0x6b, 0x9d, 0x53, 0x2a, 0xd0, 0x10, 0xa6, 0x50, 0x1a, 0xce, 0x70, 0x01,
+	0x50, 0x16, 0xab, 0x72, 0x4c, 0xe5, 0x9e, 0xaa, 0x3c, 0x40, 0x9e, 0x20, 0xa7, 0xbc, 0x41, 0xae,
+	0x79, 0x8c, 0xbc, 0x40, 0xce, 0xb9, 0xa4, 0x1a, 0x1f, 0xf3, 0x21, 0x52, 0xce, 0x69, 0xd0, 0xbf,
+	0x6e, 0x60, 0x80, 0xee, 0x1f, 0xba, 0x1b, 0x70, 0x7f, 0x2a, 0x12, 0x3e, 0x67, 0x33, 0x91, 0x1c,
+	0x2c, 0x64, 0xae, 0x73, 0xd2, 0x32, 0x9f, 0xf8, 0x2f, 0x21, 0xf4, 0x68, 0x9e, 0xa6, 0x94, 0x7f,
+	0xb7, 0xe4, 0x4a, 0x93, 0x5d, 0x08, 0x93, 0xf9, 0x34, 0x0a, 0x06, 0xc1, 0x7e, 0x97, 0xe2, 0x90,
+	0x7c, 0x1f, 0xb6, 0x17, 0x32, 0xbf, 0x60, 0x17, 0x22, 0x15, 0x5a, 0x70, 0x15, 0x35, 0x06, 0xc1,
+	0x7e, 0x87, 0xd6, 0x41, 0xf2, 0x00, 0x5a, 0xc9, 0x25, 0x93, 0x3a, 0x0a, 0x8d, 0xd6, 0x0a, 0x64,
+	0x0f, 0x3a, 0x32, 0xcf, 0xf5, 0x59, 0x96, 0xae, 0xa2, 0xa6, 0x51, 0x14, 0x32, 0xf9, 0x18, 0x40,
+	0x69, 0xa6, 0x85, 0xd2, 0x22, 0x51, 0x51, 0xcb, 0x68, 0x2b, 0x08, 0x21, 0xd0, 0x54, 0x9c, 0x4f,
+	0xa3, 0xf6, 0x20, 0xd8, 0x6f, 0x52, 0x33, 0x26, 0x8f, 0xa1, 0x2d, 0xf9, 0x22, 0x65, 0xab, 0x68,
+	0x6b, 0x10, 0xee, 0xf7, 0x0e, 0x7b, 0xf6, 0x30, 0x07, 0x27, 0x92, 0xbd, 0xa7, 0x4e, 0x45, 0x1e,
+	0x43, 0xf3, 0x1d, 0x13, 0x32, 0xea, 0x0c, 0x82, 0xfd, 0xde, 0xe1, 0x7d, 0x67, 0xf2, 0x0d, 0x13,
+	0x72, 0xcc, 0xf9, 0x94, 0x1a, 0x25, 0xf9, 0x29, 0xb4, 0x53, 0x31, 0x17, 0x5a, 0x45, 0x5d, 0x63,
+	0xf6, 0x3d, 0x67, 0x36, 0xba, 0x66, 0xe9, 0x92, 0x69, 0x91, 0x67, 0xaf, 0x8d, 0x9a, 0x3a, 0x33,
+	0x12, 0xc1, 0x16, 0xbf, 0x59, 0xa4, 0x4c, 0x64, 0x11, 0x98, 0xbd, 0x7a, 0x11, 0x0f, 0xa9, 0xc4,
+	0x7c, 0x91, 0x8a, 0x77, 0xab, 0xa8, 0x67, 0x0f, 0xe9, 0x65, 0xf2, 0x08, 0xda, 0x17, 0xf9, 0x32,
+	0x9b, 0xaa, 0xa8, 0x6f, 0x34, 0x4e, 0xc2, 0xc3, 0x5f, 0x65, 0xf9, 0xfb, 0xec, 0x6d, 0x2e, 0xa7,
+	0x2a, 0xda, 0x1e, 0x84, 0xfb, 0x5d, 0x5a, 0x41, 0xe2, 0x3f, 0x06, 0xb0, 0x7b, 0x7b, 0x2b, 0xf8,
+	0xa3, 0x39, 0xbb, 0xc1, 0xb3, 0x2a, 0x13, 0xa0, 0x90, 0x16, 0xb2, 0xd3, 0x9d, 0xe6, 0x53, 0x17,
+	0xa0, 0x90, 0x16, 0xb2, 0x9f, 0xc7, 0x17, 0xfa, 0xd2, 0x84, 0xa7, 0x45, 0x0b, 0x99, 0x7c, 0x04,
+	0x5d, 0x2d, 0xe6, 0x3c, 0x5f, 0xea, 0x37, 0xca, 0x84, 0x28, 0xa4, 0x25, 0x10, 0xff, 0x1e, 0x3a,
+	0xde, 0x6f, 0x26, 0x5e, 0x5c, 0x5e, 0x73, 0x23, 0x39, 0x82, 0x54, 0x10, 0xd4, 0x27, 0xa9, 0xe0,
+	0x99, 0x36, 0xfa, 0x86, 0xd5, 0x97, 0x08, 0x32, 0x24, 0xcb, 0xb3, 0x84, 0x9b, 0x2d, 0x84, 0xd4,
+	0x0a, 0xf1, 0xdf, 0x1b, 0xd0, 0xb7, 0xfc, 0x53, 0x8b, 0x3c, 0x53, 0x1c, 0x09, 0x38, 0x2c, 0x09,
+	0x38, 0x9c, 0x4f, 0xc9, 0x3e, 0x6c, 0x9d, 0x88, 0x84, 0x8f, 0xb9, 0x36, 0xab, 0xf6, 0x0e, 0x77,
+	0x7c, 0xd4, 0x2d, 0x4a, 0xbd, 0x9a, 0x3c, 0x81, 0x8e, 0x1b, 0xaa, 0x28, 0x1c, 0x84, 0x1b, 0x4c,
+	0x0b, 0x3d, 0xd9, 0x81, 0xc6, 0xd9, 0x95, 0x23, 0x65, 0xe3, 0xec, 0x8a, 0xfc, 0x00, 0x5a, 0x23,
+	0x29, 0x73, 0x69, 0x98, 0xd8, 0x3b, 0xdc, 0x75, 0x13, 0x71, 0x6f, 0x06, 0xa7, 0x56, 0x4d, 0x7e,
+	0x04, 0x30, 0x91, 0x2c, 0x53, 0x89, 0x14, 0x0b, 0x1d, 0xb5, 0xd7, 0x69, 0x58, 0x51, 0xe3, 0xd6,
+	0x29, 0x4f, 0x38, 0x5a, 0x6e, 0xd5, 0xb6, 0xee, 0x50, 0xea, 0xd5, 0xe4, 0x27, 0xd0, 0x79, 0xcb,
+	0x64, 0x26, 0xb2, 0x99, 0x8a, 0x3a, 0x66, 0xd1, 0xff, 0x2b, 0xb6, 0xce, 0x66, 0x59, 0x8e, 0x77,
+	0x82, 0x16, 0x26, 0xf1, 0xa8, 0x58, 0x18, 0x23, 0x38, 0x11, 0x73, 0xae, 0x34, 0x9b, 0x2f, 0x1c,
+	0x2d, 0x4a, 0x00, 0xb5, 0x63, 0x31, 0xcb, 0x98, 0x5e, 0x4a, 0x6e, 0xdc, 0xd7, 0xa7, 0x25, 0x10,
+	0x3f, 0x83, 0xed, 0x6f, 0xb9, 0x14, 0xef, 0x56, 0xfe, 0xfa, 0x7f, 0x0e, 0x4d, 0x3c, 0xb1, 0x59,
+	0xa7, 0x77, 0xf8, 0xff, 0x15, 0x27, 0xf8, 0x00, 0x51, 0x63, 0x10, 0xff, 0x0e, 0x76, 0xfc, 0x4c,
+	0x17, 0xb8, 0x07, 0xd0, 0xfa, 0x96, 0xa5, 0xc2, 0x86, 0xae, 0x43, 0xad, 0xe0, 0xdc, 0xdc, 0x58,
+	0x77, 0x73, 0xf8, 0x41, 0x37, 0xc7, 0x4f, 0xa0, 0x89, 0xde, 0x24, 0x7d, 0x08, 0x4e, 0xdd, 0xa9,
+	0x82, 0x53, 0xf7, 0x8f, 0x25, 0x77, 0x14, 0xb7, 0x42, 0xfc, 0xaf, 0x10, 0x9a, 0x18, 0x57, 0x54,
+	0x0f, 0xf3, 0x65, 0xa6, 0xdd, 0x04, 0x2b, 0x20, 0x3a, 0x16, 0xe5, 0xbd, 0xb0, 0x02, 0xa2, 0x93,
+	0x5c, 0xb3, 0xd4, 0xd3, 0xd1, 0x08, 0x88, 0x7e, 0xc3, 0x12, 0x8e, 0x57, 0x21, 0x44, 0xd4, 0x08,
+	0x76, 0xdd, 0xd4, 0x71, 0xa3, 0x4b, 0xad, 0x80, 0x4c, 0x7d, 0xc3, 0x6e, 0x4c, 0x7e, 0x0a, 0x29,
+	0x0e, 0x0d, 0x22, 0xb2, 0x68, 0xcb, 0x21, 0x22, 0x23, 0x03, 0xe8, 0x9d, 0xc8, 0x7c, 0xf1, 0x52,
+	0xcc, 0x2e, 0xb9, 0xd2, 0x26, 0x25, 0x85, 0xb4, 0x0a, 0xe1, 0xb5, 0x41, 0xf1, 0x75, 0xfe, 0x1e,
+	0x0d, 0xba, 0xc6, 0xa0, 0x82, 0x98, 0x7f, 0x9b, 0xc4, 0x0a, 0x26, 0x78, 0x56, 0x20, 0x27, 0xb0,
+	0x7d, 0x5e, 0x4b, 0xca, 0x3d, 0xc3, 0x99, 0x8f, 0x2b, 0x74, 0x3f, 0xa8, 0x19, 0x8c, 0x32, 0x2d,
+	0x57, 0xb4, 0x3e, 0x89, 0xc4, 0xd0, 0x1f, 0xdd, 0x2c, 0xd2, 0x7c, 0xca, 0x6d, 0x72, 0xe8, 0x9b,
+	0xbf, 0xd7, 0x30, 0x4c, 0xff, 0xe3, 0x65, 0x92, 0x70, 0xa5, 0x26, 0x4c, 0xce, 0xb8, 0x8e, 0xb6,
+	0x8d, 0x51, 0x1d, 0xc4, 0x73, 0x1e, 0xe7, 0x3a, 0xb9, 0x74, 0x36, 0x3b, 0xf6, 0x9c, 0x15, 0x68,
+	0xef, 0x39, 0x90, 0xf5, 0x0d, 0xa1, 0xc7, 0xae, 0xf8, 0xca, 0xc5, 0x0b, 0x87, 0x78, 0xde, 0xeb,
+	0x22, 0xc4, 0x01, 0xb5, 0xc2, 0xd7, 0x8d, 0x67, 0x41, 0xfc, 0x9f, 0x56, 0x91, 0x08, 0xc8, 0x27,
+	0x36, 0xe2, 0x51, 0x50, 0xbf, 0x7f, 0x22, 0xe1, 0xd4, 0x28, 0xc8, 0x0b, 0xd8, 0x36, 0x11, 0x55,
+	0xc7, 0x2b, 0x1b, 0xba, 0x86, 0xb1, 0xfc, 0xb4, 0x9e, 0x0f, 0x0e, 0x6a, 0x36, 0xce, 0x47, 0x35,
+	0xec, 0x0e, 0x9e, 0xec, 0x41, 0x87, 0xf2, 0xb1, 0x96, 0x22, 0x9b, 0x99, 0x1c, 0xd2, 0xa5, 0x85,
+	0x8c, 0x85, 0xeb, 0x0d, 0x67, 0x99, 0x21, 0x4b, 0x40, 0xcd, 0x18, 0xeb, 0xc0, 0x58, 0x4f, 0x4f,
+	0xf8, 0xb5, 0xa1, 0x4b, 0x40, 0x9d, 0x84, 0x7e, 0x3b, 0xe7, 0x32, 0xe1, 0x99, 0x16, 0x29, 0x7f,
+	0x6a, 0x98, 0x13, 0xd0, 0x2a, 0x84, 0x31, 0xaa, 0x88, 0x5f, 0x18, 0x0a, 0x05, 0xb4, 0x86, 0xd5,
+	0x6d, 0xbe, 0x7a, 0x1a, 0x75, 0x6f, 0xdb, 0x7c, 0xf5, 0x14, 0x79, 0x36, 0xc9, 0x17, 0x0e, 0x32,
+	0x64, 0x0a, 0x68, 0x05, 0xc1, 0x38, 0xbf, 0x64, 0x6a, 0x5c, 0x56, 0x64, 0x5b, 0xca, 0xea, 0x20,
+	0x39, 0x84, 0x1e, 0xb2, 0x83, 0x65, 0xa6, 0x2e, 0x45, 0xfd, 0xda, 0x25, 0x9e, 0x48, 0x96, 0x70,
+	0x2c, 0x39, 0xb4, 0x6a, 0x44, 0x7e, 0x08, 0x6d, 0xca, 0xd5, 0x32, 0xb5, 0xd4, 0x29, 0x13, 0x9b,
+	0x05, 0x8d, 0xbd, 0x33, 0x20, 0x9f, 0x41, 0xfb, 0xd8, 0x96, 0xcb, 0x1d, 0x63, 0xba, 0xed, 0x4c,
+	0x2d, 0x48, 0x9d, 0x12, 0x83, 0x6b, 0x47, 0x3e, 0xb8, 0xf7, 0x37, 0x06, 0xb7, 0x66, 0xe3, 0x82,
+	0x5b, 0xc3, 0x30, 0x2c, 0x26, 0x85, 0xa8, 0x68, 0x77, 0x10, 0x62, 0x58, 0xac, 0x84, 0x64, 0x5d,
+	0x67, 0x46, 0x95, 0xac, 0xdd, 0xff, 0x41, 0xd6, 0xbd, 0x33, 0x20, 0xeb, 0xbf, 0xdf, 0xb0, 0xc2,
+	0xe3, 0xea, 0x0a, 0x6b, 0x07, 0xae, 0xb0, 0xff, 0xb9, 0x77, 0x8d, 0xcf, 0x32, 0x81, 0xf9, 0x25,
+	0x0e, 0x7d, 0x26, 0x6a, 0x38, 0x84, 0xdd, 0x14, 0x1c, 0x0c, 0x4b, 0x0e, 0xc6, 0xff, 0x08, 0x00,
+	0x4a, 0x9f, 0x93, 0x27, 0xd0, 0xbc, 0x12, 0x99, 0x4d, 0xd7, 0x3b, 0x87, 0x8f, 0xd6, 0x82, 0x72,
+	0xf0, 0x1b, 0x91, 0x4d, 0xa9, 0xb1, 0xc1, 0xe5, 0x26, 0xfc, 0x46, 0xbb, 0xaa, 0x6e, 0xc6, 0x58,
+	0x59, 0xd0, 0xd1, 0xaf, 0xb2, 0x29, 0xbf, 0x71, 0x6d, 0x45, 0x09, 0x60, 0x3d, 0x1b, 0x5e, 0x8a,
+	0x74, 0x2a, 0x79, 0x66, 0x72, 0xe9, 0xc6, 0xb0, 0x17, 0x26, 0xf1, 0x3e, 0x34, 0xf1, 0x77, 0xa4,
+	0x03, 0xcd, 0xc9, 0xe8, 0xb7, 0x93, 0xdd, 0x7b, 0x38, 0x3a, 0x79, 0x35, 0x1c, 0xed, 0x06, 0xa4,
+	0x0f, 0x9d, 0xb3, 0xf3, 0x11, 0x3d, 0x9a, 0x9c, 0xd1, 0xdd, 0x46, 0xfc, 0xb7, 0x00, 0xba, 0x05,
+	0xd1, 0xf0, 0xe4, 0xe3, 0xd5, 0xdc, 0x3b, 0x74, 0xbc, 0x9a, 0xd7, 0x4b, 0x44, 0xd7, 0x95, 0x08,
+	0x0c, 0xb4, 0xe3, 0xa0, 0xf5, 0x88, 0x93, 0x8a, 0x3c, 0xd2, 0xbc, 0x2b, 0x8f, 0xfc, 0xb8, 0x72,
+	0x8e, 0xd6, 0x20, 0xdc, 0xc8, 0xf6, 0xc2, 0xa2, 0xf0, 0x53, 0xbb, 0xf4, 0x53, 0xfc, 0xf3, 0xb2,
+	0x29, 0xa9, 0xb6, 0x32, 0xc1, 0xc6, 0xfe, 0xc4, 0xab, 0xe3, 0x67, 0xb0, 0x33, 0xcc, 0xe7, 0x0b,
+	0x26, 0xf9, 0xdd, 0x9d, 0x79, 0xd1, 0x73, 0x37, 0x2a, 0x3d, 0x77, 0xfc, 0xd7, 0x06, 0xdc, 0x2f,
+	0xa6, 0xde, 0xd9, 0x54, 0x7d, 0x02, 0xc1, 0x91, 0xe3, 0x9c, 0x0f, 0xcc, 0xe8, 0x66, 0x21, 0xb9,
+	0x52, 0x22, 0xcf, 0x68, 0x70, 0x84, 0x06, 0xc7, 0x51, 0x78, 0xa7, 0xc1, 0x31, 0x36, 0xc4, 0x2f,
+	0x24, 0x67, 0x9a, 0x4b, 0x93, 0x01, 0x03, 0xea, 0x45, 0xdc, 0xd7, 0xe8, 0xbb, 0x25, 0x4b, 0x5d,
+	0x06, 0xb4, 0x02, 0xfa, 0xe6, 0x35, 0x57, 0xca, 0x25, 0x40, 0x33, 0x26, 0x9f, 0x43, 0x6b, 0xc2,
+	0x2e, 0x52, 0xee, 0xda, 0x79, 0xff, 0x23, 0xbf, 0xfd, 0xfc, 0x3d, 0xb5, 0xfa, 0xb2, 0x0a, 0x76,
+	0xaa, 0x55, 0xd0, 0x36, 0x17, 0xdd, 0xf5, 0xe6, 0x02, 0x3e, 0xdc, 0x5c, 0xfc, 0x1a, 0xa0, 0x3c,
+	0xcb, 0x06, 0xe7, 0xf8, 0xdb, 0xd3, 0xd8, 0x98, 0xc1, 0xc3, 0x6a, 0x06, 0x8f, 0x9f, 0x03, 0x94,
+	0xdb, 0xad, 0xf0, 0x2c, 0xa8, 0xf1, 0xac, 0xef, 0xdd, 0x1d, 0xa0, 0x6f, 0xfb, 0xde, 0xb7, 0x01,
+	0x0d, 0x8e, 0xe3, 0x3f, 0x07, 0xb0, 0x7d, 0xc2, 0xe6, 0x6c, 0x56, 0x84, 0x7a, 0x00, 0x3d, 0xa6,
+	0x35, 0x4b, 0xae, 0x8e, 0xf3, 0x6c, 0xe9, 0x7b, 0xfd, 0x2a, 0x84, 0x27, 0x3f, 0x1a, 0xba, 0x86,
+	0xa6, 0x71, 0x34, 0xc4, 0xcb, 0x98, 0x48, 0xa1, 0x29, 0xcb, 0x66, 0xbe, 0xc1, 0x2e, 0x01, 0xdc,
+	0xd5, 0xd4, 0xfc, 0xc0, 0xd5, 0x2a, 0x27, 0x61, 0x08, 0xed, 0xa2, 0xf6, 0xfd, 0x15, 0x52, 0x2f,
+	0xc6, 0x7f, 0x6a, 0xc0, 0x8e, 0xdf, 0x93, 0xe3, 0x90, 0x77, 0x4a, 0xb0, 0xd1, 0x29, 0x8d, 0x5a,
+	0x59, 0xfb, 0x08, 0xba, 0x2f, 0x85, 0x1e, 0x5e, 0x32, 0xdf, 0xef, 0x07, 0xb4, 0x04, 0xb0, 0x14,
+	0x0d, 0x65, 0xa1, 0xb6, 0xe4, 0xa9, 0x20, 0xa8, 0x7f, 0x23, 0x94, 0x72, 0x7a, 0x4b, 0xa2, 0x0a,
+	0x42, 0x0e, 0xa1, 0x7f, 0x22, 0x94, 0x96, 0xe2, 0x62, 0x69, 0xaa, 0x50, 0xbb, 0x76, 0x95, 0xce,
+	0x96, 0x3a, 0xc9, 0xe7, 0x9c, 0xd6, 0x6c, 0x1c, 0x55, 0xb6, 0xd6, 0xa9, 0xd2, 0xf9, 0x30, 0x55,
+	0x86, 0xb0, 0xe5, 0x16, 0xbc, 0x33, 0xb6, 0x58, 0xc3, 0x8b, 0xce, 0x66, 0xe5, 0x3c, 0x51, 0x85,
+	0xe2, 0x3f, 0x40, 0xb7, 0x58, 0x18, 0xe9, 0x36, 0x57, 0x33, 0x4f, 0xb7, 0xb9, 0x32, 0x0d, 0x43,
+	0x92, 0x4f, 0x6d, 0xc6, 0x6a, 0x51, 0x33, 0x26, 0x5f, 0x42, 0x6f, 0x5a, 0x34, 0xfe, 0xfe, 0x35,
+	0xb3, 0xe1, 0x49, 0x50, 0xb5, 0x32, 0x09, 0x81, 0x49, 0xae, 0x5d, 0x98, 0xad, 0x10, 0xff, 0x33,
+	0x00, 0x28, 0x67, 0x90, 0x5f, 0x40, 0x47, 0xf1, 0x6b, 0x2e, 0x71, 0xaf, 0x36, 0xf7, 0xef, 0xad,
+	0x2d, 0x7b, 0x30, 0x76, 0x16, 0xb4, 0xb0, 0x45, 0xb2, 0xcc, 0xb9, 0x52, 0x6c, 0x66, 0x37, 0xda,
+	0xa5, 0x5e, 0x24, 0x9f, 0x41, 0x4b, 0x69, 0xff, 0xf6, 0x2f, 0x5f, 0xdc, 0xe7, 0xb9, 0x12, 0xe8,
+	0x7b, 0x6a, 0xb5, 0xe4, 0x53, 0x08, 0x79, 0x36, 0x8d, 0x9a, 0x9b, 0x8d, 0x50, 0x17, 0xc7, 0xd0,
+	0xf1, 0x7f, 0x26, 0x5d, 0x68, 0x8d, 0x28, 0x3d, 0xa3, 0xbb, 0xf7, 0x48, 0x0f, 0xb6, 0xde, 0x1e,
+	0xd1, 0xd3, 0x57, 0xa7, 0x2f, 0x76, 0x83, 0xf8, 0x0b, 0xe8, 0xf8, 0x49, 0xe8, 0xb9, 0x54, 0x64,
+	0xdc, 0x9c, 0xa3, 0x45, 0xcd, 0xd8, 0xe4, 0xc9, 0x3c, 0x75, 0xce, 0xc4, 0xe1, 0xe1, 0xbf, 0x03,
+	0x68, 0xa3, 0xff, 0xb9, 0x24, 0x3f, 0xb3, 0xef, 0x1b, 0x42, 0x6a, 0x2f, 0x1b, 0x73, 0xeb, 0xf6,
+	0x36, 0xbd, 0x76, 0xe2, 0x7b, 0xe4, 0x6b, 0xd8, 0x72, 0x17, 0x9c, 0x3c, 0xbc, 0x95, 0x9f, 0xdc,
+	0xc4, 0x47, 0xb7, 0xe1, 0x62, 0xee, 0xaf, 0xfc, 0x2d, 0x3a, 0xe7, 0x92, 0x62, 0xf5, 0x26, 0x0f,
+	0xbc, 0xaf, 0xab, 0x17, 0x7e, 0xef, 0xe1, 0x2d, 0xb4, 0x58, 0xe0, 0x97, 0xd0, 0xb6, 0xcf, 0xac,
+	0x62, 0x62, 0xed, 0xbd, 0xb6, 0xf7, 0xf0, 0x16, 0xea, 0x27, 0x5e, 0xb4, 0x0d, 0xfe, 0xe5, 0x7f,
+	0x07, 0x00, 0xfb, 0xa2, 0xfc, 0x60, 0xf8, 0x11, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -490,6 +2020,12 @@ const _ = grpc.SupportPackageIsVersion4
 type RollerClient interface {
 	// Rolls dice
 	Roll(ctx context.Context, in *RollRequest, opts ...grpc.CallOption) (*RollResponse, error)
+	// Compares the distributions of two expressions
+	Compare(ctx context.Context, in *CompareRequest, opts ...grpc.CallOption) (*CompareResponse, error)
+	// Calculates the damage dealt by a round of attacks
+	DamagePerRound(ctx context.Context, in *DamageRequest, opts ...grpc.CallOption) (*DamageResponse, error)
+	// Checks the receipt of a RollResponse
+	Verify(ctx context.Context, in *VerifyRequest, opts ...grpc.CallOption) (*VerifyResponse, error)
 }
 
 type rollerClient struct {
@@ -509,10 +2045,43 @@ func (c *rollerClient) Roll(ctx context.Context, in *RollRequest, opts ...grpc.C
 	return out, nil
 }
 
+func (c *rollerClient) Compare(ctx context.Context, in *CompareRequest, opts ...grpc.CallOption) (*CompareResponse, error) {
+	out := new(CompareResponse)
+	err := c.cc.Invoke(ctx, "/proto.Roller/Compare", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rollerClient) DamagePerRound(ctx context.Context, in *DamageRequest, opts ...grpc.CallOption) (*DamageResponse, error) {
+	out := new(DamageResponse)
+	err := c.cc.Invoke(ctx, "/proto.Roller/DamagePerRound", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rollerClient) Verify(ctx context.Context, in *VerifyRequest, opts ...grpc.CallOption) (*VerifyResponse, error) {
+	out := new(VerifyResponse)
+	err := c.cc.Invoke(ctx, "/proto.Roller/Verify", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // RollerServer is the server API for Roller service.
 type RollerServer interface {
 	// Rolls dice
 	Roll(context.Context, *RollRequest) (*RollResponse, error)
+	// Compares the distributions of two expressions
+	Compare(context.Context, *CompareRequest) (*CompareResponse, error)
+	// Calculates the damage dealt by a round of attacks
+	DamagePerRound(context.Context, *DamageRequest) (*DamageResponse, error)
+	// Checks the receipt of a RollResponse
+	Verify(context.Context, *VerifyRequest) (*VerifyResponse, error)
 }
 
 func RegisterRollerServer(s *grpc.Server, srv RollerServer) {
@@ -537,6 +2106,60 @@ func _Roller_Roll_Handler(srv interface{}, ctx context.Context, dec func(interfa
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Roller_Compare_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompareRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RollerServer).Compare(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Roller/Compare",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RollerServer).Compare(ctx, req.(*CompareRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Roller_DamagePerRound_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DamageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RollerServer).DamagePerRound(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Roller/DamagePerRound",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RollerServer).DamagePerRound(ctx, req.(*DamageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Roller_Verify_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RollerServer).Verify(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Roller/Verify",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RollerServer).Verify(ctx, req.(*VerifyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Roller_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "proto.Roller",
 	HandlerType: (*RollerServer)(nil),
@@ -545,6 +2168,18 @@ var _Roller_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Roll",
 			Handler:    _Roller_Roll_Handler,
 		},
+		{
+			MethodName: "Compare",
+			Handler:    _Roller_Compare_Handler,
+		},
+		{
+			MethodName: "DamagePerRound",
+			Handler:    _Roller_DamagePerRound_Handler,
+		},
+		{
+			MethodName: "Verify",
+			Handler:    _Roller_Verify_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "dicemagic.proto",